@@ -0,0 +1,419 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"crypto/sha1"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AtomNS is the XML namespace for Atom 1.0 (RFC 4287)
+const AtomNS = "http://www.w3.org/2005/Atom"
+
+// Atom is an Atom 1.0 feed document
+type Atom struct {
+	XMLName   xml.Name       `xml:"http://www.w3.org/2005/Atom feed" json:"-"`
+	ID        string         `xml:"id" json:"id"`
+	Title     *AtomText      `xml:"title" json:"title"`
+	Subtitle  *AtomText      `xml:"subtitle,omitempty" json:"subtitle,omitempty"`
+	Updated   string         `xml:"updated" json:"updated"`
+	Links     []AtomLink     `xml:"link,omitempty" json:"link,omitempty"`
+	Authors   []AtomPerson   `xml:"author,omitempty" json:"author,omitempty"`
+	Category  []AtomCategory `xml:"category,omitempty" json:"category,omitempty"`
+	Generator string         `xml:"generator,omitempty" json:"generator,omitempty"`
+	Rights    string         `xml:"rights,omitempty" json:"rights,omitempty"`
+	Logo      string         `xml:"logo,omitempty" json:"logo,omitempty"`
+	Entries   []AtomEntry    `xml:"entry,omitempty" json:"entry,omitempty"`
+}
+
+// AtomEntry is a single entry in an Atom feed
+type AtomEntry struct {
+	ID        string         `xml:"id" json:"id"`
+	Title     *AtomText      `xml:"title" json:"title"`
+	Updated   string         `xml:"updated" json:"updated"`
+	Published string         `xml:"published,omitempty" json:"published,omitempty"`
+	Links     []AtomLink     `xml:"link,omitempty" json:"link,omitempty"`
+	Authors   []AtomPerson   `xml:"author,omitempty" json:"author,omitempty"`
+	Category  []AtomCategory `xml:"category,omitempty" json:"category,omitempty"`
+	Summary   *AtomText      `xml:"summary,omitempty" json:"summary,omitempty"`
+	Content   *AtomText      `xml:"content,omitempty" json:"content,omitempty"`
+	Source    *AtomSource    `xml:"source,omitempty" json:"source,omitempty"`
+}
+
+// AtomText holds an Atom text construct, Type is "text" or "html"
+type AtomText struct {
+	Type  string `xml:"type,attr,omitempty" json:"type,omitempty"`
+	Value string `xml:",chardata" json:"value"`
+}
+
+// AtomLink is an Atom link element
+type AtomLink struct {
+	Href   string `xml:"href,attr" json:"href"`
+	Rel    string `xml:"rel,attr,omitempty" json:"rel,omitempty"`
+	Type   string `xml:"type,attr,omitempty" json:"type,omitempty"`
+	Length string `xml:"length,attr,omitempty" json:"length,omitempty"`
+}
+
+// AtomPerson is an Atom author or contributor
+type AtomPerson struct {
+	Name  string `xml:"name" json:"name"`
+	Email string `xml:"email,omitempty" json:"email,omitempty"`
+	URI   string `xml:"uri,omitempty" json:"uri,omitempty"`
+}
+
+// AtomCategory is an Atom category element
+type AtomCategory struct {
+	Term string `xml:"term,attr" json:"term"`
+}
+
+// AtomSource identifies the feed an entry was copied from
+type AtomSource struct {
+	Title *AtomText `xml:"title,omitempty" json:"title,omitempty"`
+}
+
+// atomEpoch is used for updated when nothing in the RSS feed
+// carries a usable date so output is stable between runs.
+var atomEpoch = time.Unix(0, 0).UTC()
+
+// uuidURN returns a name based (SHA-1, version 5 style) UUID URN for
+// the strings given. The same input always yields the same id.
+func uuidURN(parts ...string) string {
+	h := sha1.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	b := h.Sum(nil)[:16]
+	b[6] = (b[6] & 0x0f) | 0x50
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// atomDate converts an RSS date to RFC 3339, ok is false if the date
+// is missing or can't be parsed.
+func atomDate(s string) (string, bool) {
+	t, err := parseDate(s)
+	if err != nil {
+		return "", false
+	}
+	return t.Format(time.RFC3339), true
+}
+
+// atomPerson converts an RSS author value, usually of the form
+// "jane@example.edu (Jane Doe)", into an AtomPerson.
+func atomPerson(s string) AtomPerson {
+	s = strings.TrimSpace(s)
+	p := AtomPerson{Name: s}
+	if i := strings.Index(s, "("); i > 0 && strings.HasSuffix(s, ")") {
+		email := strings.TrimSpace(s[0:i])
+		if strings.Contains(email, "@") {
+			p.Email = email
+			p.Name = strings.TrimSpace(s[i+1 : len(s)-1])
+		}
+	} else if strings.Contains(s, "@") && !strings.Contains(s, " ") {
+		p.Email = s
+	}
+	return p
+}
+
+// itemID picks a stable id for an item. The GUID is preferred, then the
+// link, otherwise one is synthesized from the item's content.
+func (r *RSS2) itemID(item Item) string {
+	switch {
+	case strings.TrimSpace(item.GUID) != "":
+		return strings.TrimSpace(item.GUID)
+	case strings.TrimSpace(item.Link) != "":
+		return strings.TrimSpace(item.Link)
+	}
+	return uuidURN(r.Link, item.Title, item.Description, item.PubDate)
+}
+
+// NewAtom renders an RSS2 value as an Atom 1.0 feed. Atom requires
+// id and updated elements which RSS doesn't, when they are missing
+// they are derived from the RSS content so the same RSS input always
+// produces the same Atom output.
+func NewAtom(r *RSS2) *Atom {
+	feed := new(Atom)
+	feed.Title = &AtomText{Type: "text", Value: strings.TrimSpace(r.Title)}
+	if strings.TrimSpace(r.Description) != "" {
+		feed.Subtitle = &AtomText{Type: "text", Value: strings.TrimSpace(r.Description)}
+	}
+	if link := strings.TrimSpace(r.Link); link != "" {
+		feed.ID = link
+		feed.Links = append(feed.Links, AtomLink{Href: link, Rel: "alternate"})
+	} else {
+		feed.ID = uuidURN(r.Title, r.Description)
+	}
+	// paging links, see Paginate, the RSS self link doesn't name
+	// the Atom document
+	for _, link := range r.AtomLinks {
+		if link.Rel != "" && link.Rel != "alternate" && link.Rel != "self" {
+			feed.Links = append(feed.Links, link)
+		}
+	}
+	if r.ManagingEditor != "" {
+		feed.Authors = append(feed.Authors, atomPerson(r.ManagingEditor))
+	}
+	if r.Category != "" {
+		feed.Category = append(feed.Category, AtomCategory{Term: strings.TrimSpace(r.Category)})
+	}
+	feed.Generator = r.Generator
+	feed.Rights = r.Copyright
+
+	// Fallback for entries without a date of their own
+	fallback, ok := atomDate(r.LastBuildDate)
+	if !ok {
+		fallback, ok = atomDate(r.PubDate)
+	}
+	if !ok {
+		fallback = ""
+	}
+
+	// the newest entry is found by time, the offsets of the dates
+	// may differ
+	newest, newestTime := "", time.Time{}
+	needAuthor := false
+	for _, item := range r.ItemList {
+		entry := AtomEntry{}
+		entry.ID = r.itemID(item)
+		entry.Title = &AtomText{Type: "text", Value: strings.TrimSpace(item.Title)}
+		if published, ok := atomDate(item.PubDate); ok {
+			entry.Published = published
+			entry.Updated = published
+		} else if fallback != "" {
+			entry.Updated = fallback
+		} else {
+			entry.Updated = atomEpoch.Format(time.RFC3339)
+		}
+		if link := strings.TrimSpace(item.Link); link != "" {
+			entry.Links = append(entry.Links, AtomLink{Href: link, Rel: "alternate"})
+		}
+		if item.Comments != "" {
+			entry.Links = append(entry.Links, AtomLink{Href: strings.TrimSpace(item.Comments), Rel: "replies", Type: "text/html"})
+		}
+		if item.Author != "" {
+			entry.Authors = append(entry.Authors, atomPerson(item.Author))
+		} else {
+			needAuthor = true
+		}
+		if item.Enclosure != nil && item.Enclosure.URL != "" {
+			entry.Links = append(entry.Links, AtomLink{Href: item.Enclosure.URL, Rel: "enclosure", Type: item.Enclosure.Type, Length: item.Enclosure.Length})
+		}
+		for _, category := range item.Category {
+			if strings.TrimSpace(category) != "" {
+				entry.Category = append(entry.Category, AtomCategory{Term: strings.TrimSpace(category)})
+			}
+		}
+		if item.Description != "" {
+			entry.Summary = &AtomText{Type: "html", Value: item.Description}
+		}
+		if item.Content != "" {
+			entry.Content = &AtomText{Type: "html", Value: item.Content}
+		}
+		if item.Source != "" {
+			entry.Source = &AtomSource{Title: &AtomText{Type: "text", Value: strings.TrimSpace(item.Source)}}
+		}
+		if t, err := time.Parse(time.RFC3339, entry.Updated); err == nil && (newest == "" || t.After(newestTime)) {
+			newest, newestTime = entry.Updated, t
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	// Atom requires an author for every entry, either directly or
+	// inherited from the feed.
+	if needAuthor && len(feed.Authors) == 0 {
+		name := strings.TrimSpace(r.Title)
+		if name == "" {
+			name = feed.ID
+		}
+		feed.Authors = append(feed.Authors, AtomPerson{Name: name})
+	}
+
+	switch {
+	case fallback != "":
+		feed.Updated = fallback
+	case newest != "":
+		feed.Updated = newest
+	default:
+		feed.Updated = atomEpoch.Format(time.RFC3339)
+	}
+	return feed
+}
+
+// ToAtom returns the RSS2 document rendered as Atom 1.0 XML
+func (r *RSS2) ToAtom() ([]byte, error) {
+	src, err := xml.MarshalIndent(NewAtom(r), "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), src...), nil
+}
+
+// UnmarshalXML reads an Atom text construct. XHTML content is kept as
+// serialized markup (without the wrapping div) and its type becomes
+// "html" so it can be carried in RSS description or content elements.
+func (text *AtomText) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "type" {
+			text.Type = attr.Value
+		}
+	}
+	if text.Type != "xhtml" {
+		s := struct {
+			Value string `xml:",chardata"`
+		}{}
+		if err := d.DecodeElement(&s, &start); err != nil {
+			return err
+		}
+		text.Value = s.Value
+		return nil
+	}
+	s := struct {
+		Inner string `xml:",innerxml"`
+	}{}
+	if err := d.DecodeElement(&s, &start); err != nil {
+		return err
+	}
+	inner := strings.TrimSpace(s.Inner)
+	// Drop the required xhtml div wrapper
+	if strings.HasPrefix(inner, "<div") && strings.HasSuffix(inner, "</div>") {
+		if i := strings.Index(inner, ">"); i > 0 {
+			inner = strings.TrimSpace(inner[i+1 : len(inner)-len("</div>")])
+		}
+	}
+	text.Type = "html"
+	text.Value = inner
+	return nil
+}
+
+// String returns the text value, an empty string for nil
+func (text *AtomText) String() string {
+	if text == nil {
+		return ""
+	}
+	return text.Value
+}
+
+// rssDate converts an Atom (RFC 3339) date to the RFC 1123 form used
+// in RSS. Unparsable dates are returned unchanged.
+func rssDate(s string) string {
+	if t, err := parseDate(s); err == nil {
+		return t.Format(time.RFC1123Z)
+	}
+	return strings.TrimSpace(s)
+}
+
+// rssPerson converts an AtomPerson to an RSS author value
+func rssPerson(p AtomPerson) string {
+	name, email := strings.TrimSpace(p.Name), strings.TrimSpace(p.Email)
+	switch {
+	case email != "" && name != "":
+		return fmt.Sprintf("%s (%s)", email, name)
+	case email != "":
+		return email
+	}
+	return name
+}
+
+// alternateLink returns the href of the first link with rel
+// "alternate" (the default rel) from links.
+func alternateLink(links []AtomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+// ToRSS2 converts an Atom feed into an RSS2 value. The entry id becomes
+// the GUID, summary the description and content the content:encoded.
+func (feed *Atom) ToRSS2() *RSS2 {
+	r := new(RSS2)
+	r.Version = "2.0"
+	r.Title = strings.TrimSpace(feed.Title.String())
+	r.Link = alternateLink(feed.Links)
+	r.Description = strings.TrimSpace(feed.Subtitle.String())
+	if len(feed.Authors) > 0 {
+		r.ManagingEditor = rssPerson(feed.Authors[0])
+	}
+	if len(feed.Category) > 0 {
+		r.Category = feed.Category[0].Term
+	}
+	r.Generator = strings.TrimSpace(feed.Generator)
+	r.Copyright = strings.TrimSpace(feed.Rights)
+	if feed.Updated != "" {
+		r.LastBuildDate = rssDate(feed.Updated)
+	}
+	for _, entry := range feed.Entries {
+		item := Item{}
+		item.GUID = strings.TrimSpace(entry.ID)
+		item.Link = alternateLink(entry.Links)
+		item.Title = strings.TrimSpace(entry.Title.String())
+		if entry.Summary != nil {
+			item.Description = entry.Summary.Value
+			if entry.Content != nil {
+				item.Content = entry.Content.Value
+			}
+		} else if entry.Content != nil {
+			item.Description = entry.Content.Value
+		}
+		switch {
+		case entry.Published != "":
+			item.PubDate = rssDate(entry.Published)
+		case entry.Updated != "":
+			item.PubDate = rssDate(entry.Updated)
+		}
+		if len(entry.Authors) > 0 {
+			item.Author = rssPerson(entry.Authors[0])
+		}
+		for _, category := range entry.Category {
+			item.Category = append(item.Category, category.Term)
+		}
+		for _, link := range entry.Links {
+			switch link.Rel {
+			case "enclosure":
+				if item.Enclosure == nil {
+					item.Enclosure = &Enclosure{URL: link.Href, Length: link.Length, Type: link.Type}
+				}
+			case "replies":
+				if item.Comments == "" {
+					item.Comments = link.Href
+				}
+			}
+		}
+		if entry.Source != nil {
+			item.Source = strings.TrimSpace(entry.Source.Title.String())
+		}
+		r.ItemList = append(r.ItemList, item)
+	}
+	return r
+}
+
+// ParseAtom reads an Atom 1.0 document and returns it as an RSS2
+// structure.
+func ParseAtom(buf []byte) (*RSS2, error) {
+	feed := new(Atom)
+	if err := xml.Unmarshal(buf, &feed); err != nil {
+		return nil, err
+	}
+	return feed.ToRSS2(), nil
+}
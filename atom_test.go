@@ -0,0 +1,126 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToAtom(t *testing.T) {
+	src, err := ioutil.ReadFile(path.Join("testdata", "rsdoiel.xml"))
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	r, err := Parse(src)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	out, err := r.ToAtom()
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	again, _ := r.ToAtom()
+	if bytes.Compare(out, again) != 0 {
+		t.Errorf("expected ToAtom() to be deterministic")
+	}
+
+	feed := new(Atom)
+	if err := xml.Unmarshal(out, &feed); err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if feed.ID != r.Link {
+		t.Errorf("expected feed id %q, got %q", r.Link, feed.ID)
+	}
+	if _, err := time.Parse(time.RFC3339, feed.Updated); err != nil {
+		t.Errorf("expected RFC 3339 updated, %s", err)
+	}
+	if len(feed.Entries) != len(r.ItemList) {
+		t.Errorf("expected %d entries, got %d", len(r.ItemList), len(feed.Entries))
+		t.FailNow()
+	}
+	for i, entry := range feed.Entries {
+		if entry.ID != r.ItemList[i].Link {
+			t.Errorf("expected entry %d id %q, got %q", i, r.ItemList[i].Link, entry.ID)
+		}
+		if entry.Title == nil || entry.Title.Value != r.ItemList[i].Title {
+			t.Errorf("expected entry %d title %q, got %+v", i, r.ItemList[i].Title, entry.Title)
+		}
+	}
+	if feed.Entries[0].Published != "2016-05-28T00:00:00Z" {
+		t.Errorf("expected published 2016-05-28T00:00:00Z, got %q", feed.Entries[0].Published)
+	}
+}
+
+func TestToAtomSynthesized(t *testing.T) {
+	src := []byte(`<rss version="2.0"><channel>
+<title>No dates</title>
+<description>Items without guid, link or pubDate</description>
+<item><title>One</title><description>first</description></item>
+<item><title>Two</title><description>second</description><author>jane@example.edu (Jane Doe)</author></item>
+</channel></rss>`)
+	r, err := Parse(src)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	feed := NewAtom(r)
+	if !strings.HasPrefix(feed.ID, "urn:uuid:") {
+		t.Errorf("expected synthesized feed id, got %q", feed.ID)
+	}
+	if feed.Updated != "1970-01-01T00:00:00Z" {
+		t.Errorf("expected epoch updated, got %q", feed.Updated)
+	}
+	if len(feed.Authors) != 1 || feed.Authors[0].Name != "No dates" {
+		t.Errorf("expected feed author from channel title, got %+v", feed.Authors)
+	}
+	if feed.Entries[0].ID == feed.Entries[1].ID {
+		t.Errorf("expected distinct entry ids, got %q", feed.Entries[0].ID)
+	}
+	if feed.Entries[0].ID != NewAtom(r).Entries[0].ID {
+		t.Errorf("expected stable entry ids")
+	}
+	author := feed.Entries[1].Authors[0]
+	if author.Name != "Jane Doe" || author.Email != "jane@example.edu" {
+		t.Errorf("expected Jane Doe <jane@example.edu>, got %+v", author)
+	}
+
+	// the newest item is found by time, not by comparing dates with
+	// different offsets as text
+	r, err = Parse([]byte(`<rss version="2.0"><channel><title>Offsets</title>
+<item><title>Tokyo</title><pubDate>Mon, 01 Jan 2024 10:00:00 +0900</pubDate></item>
+<item><title>London</title><pubDate>Mon, 01 Jan 2024 05:00:00 GMT</pubDate></item>
+</channel></rss>`))
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if feed = NewAtom(r); feed.Updated != "2024-01-01T05:00:00Z" {
+		t.Errorf("expected the London item's date, got %q", feed.Updated)
+	}
+}
@@ -0,0 +1,212 @@
+//
+// mkrss is a command line utility that generates an RSS 2 feed from a
+// directory of Markdown documents.
+//
+// @author R. S. Doiel, <rsdoiel@library.caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package main
+
+import (
+	"fmt"
+	"os"
+
+	// Caltech Library Packages
+	"github.com/caltechlibrary/cli"
+	"github.com/caltechlibrary/rss2"
+)
+
+var (
+	synopsis = `mkrss generates RSS 2 XML from a directory of Markdown documents`
+
+	description = `
+_mkrss_ walks DIRECTORY for Markdown documents (files ending in
+".md" or ".markdown") and writes an RSS 2 feed with an item for
+each one, newest first.
+
+Item fields are read from the document's front matter, YAML
+between "---" lines, TOML between "+++" lines or a JSON object
+at the start of the document. These keys are used
+
++ title, defaults to the first level one heading or the file name
++ date (or pubDate, published, created), defaults to a YYYY/MM/DD
+  or YYYY-MM-DD date in the document's path
++ author (or authors, byline, creator), defaults to "-author"
++ categories (or category, tags, keywords), a list or a comma
+  separated string
++ description (or summary, abstract), defaults to a plain text
+  excerpt of the document
++ link (or url, permalink), defaults to the document's path
++ guid (or id)
+
+Documents with "draft: true" are skipped. Links are the "-base-url"
+(or the channel "-link") joined with the document's path relative
+to DIRECTORY, with ".md" replaced by ".html".
+`
+
+	examples = `
+Generate a feed for a blog kept as Markdown in *blog*, where
+*blog/2016/05/28/OPML-to-Markdown-and-back.md* is published as
+*http://rsdoiel.github.io/blog/2016/05/28/OPML-to-Markdown-and-back.html*.
+
+` + "```" + `
+    mkrss -title "R. S. Doiel" \
+        -description "Robert's ramblings and wonderigs" \
+        -link http://rsdoiel.github.io/blog \
+        -o blog/rss.xml blog
+` + "```" + `
+
+Only the ten most recent posts, without excerpts.
+
+` + "```" + `
+    mkrss -title "News" -link https://library.example.edu/news \
+        -limit 10 -excerpt -1 news
+` + "```" + `
+`
+
+	license = `
+%s %s
+
+Copyright (c) 2020, Caltech
+All rights not granted herein are expressly reserved by Caltech.
+
+Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+`
+
+	// Standard options
+	showHelp         bool
+	showVersion      bool
+	showLicense      bool
+	showExamples     bool
+	outputFName      string
+	quiet            bool
+	newLine          bool
+	generateMarkdown bool
+	generateManPage  bool
+
+	// Application options
+	channelTitle       string
+	channelLink        string
+	channelDescription string
+	baseURL            string
+	author             string
+	excerpt            int
+	limit              int
+)
+
+func main() {
+	app := cli.NewCli(rss2.Version)
+	appName := app.AppName()
+
+	// Document non-option parameters
+	app.SetParams("DIRECTORY")
+
+	// Add Help Docs
+	app.AddHelp("synopsis", []byte(synopsis))
+	app.AddHelp("description", []byte(description))
+	app.AddHelp("examples", []byte(examples))
+	app.AddHelp("license", []byte(fmt.Sprintf(license, appName, rss2.Version)))
+
+	// Standard Options
+	app.BoolVar(&showHelp, "h,help", false, "display help")
+	app.BoolVar(&showLicense, "l,license", false, "display license")
+	app.BoolVar(&showVersion, "v,version", false, "display version")
+	app.BoolVar(&showExamples, "examples", false, "display examples")
+	app.BoolVar(&quiet, "quiet", false, "suppress error messages")
+	app.BoolVar(&newLine, "nl,newline", false, "add trailing newline")
+	app.StringVar(&outputFName, "o,output", "", "set output filename")
+	app.BoolVar(&generateMarkdown, "generate-markdown", false, "generate Markdown documentation")
+	app.BoolVar(&generateManPage, "generate-manpage", false, "generate man page")
+
+	// Application Options
+	app.StringVar(&channelTitle, "title", "", "set the channel title")
+	app.StringVar(&channelLink, "link", "", "set the channel link")
+	app.StringVar(&channelDescription, "description", "", "set the channel description")
+	app.StringVar(&baseURL, "base-url", "", "set the URL documents are published under, defaults to the channel link")
+	app.StringVar(&author, "author", "", "set the author of documents without one")
+	app.IntVar(&excerpt, "excerpt", 0, "set the excerpt length, 0 for the default and -1 for none")
+	app.IntVar(&limit, "limit", 0, "include at most this many items")
+
+	// Process environment and options
+	app.Parse()
+	args := app.Args()
+
+	// Setup I/O
+	var err error
+
+	app.Eout = os.Stderr
+	app.Out, err = cli.Create(outputFName, os.Stdout)
+	cli.ExitOnError(app.Eout, err, quiet)
+	defer cli.CloseFile(outputFName, app.Out)
+
+	// Handle options
+	if generateMarkdown {
+		app.GenerateMarkdown(os.Stdout)
+		os.Exit(0)
+	}
+	if generateManPage {
+		app.GenerateManPage(os.Stdout)
+		os.Exit(0)
+	}
+	if showHelp || showExamples {
+		if len(args) > 0 {
+			fmt.Fprintln(app.Out, app.Help(args...))
+		} else {
+			app.Usage(app.Out)
+		}
+		os.Exit(0)
+	}
+	if showLicense {
+		fmt.Fprintln(app.Out, app.License())
+		os.Exit(0)
+	}
+	if showVersion {
+		fmt.Fprintln(app.Out, app.Version())
+		os.Exit(0)
+	}
+
+	if len(args) != 1 {
+		cli.ExitOnError(app.Eout, fmt.Errorf("expected a DIRECTORY, try %s -help", appName), quiet)
+	}
+	if channelLink == "" && baseURL == "" {
+		cli.ExitOnError(app.Eout, fmt.Errorf("-link or -base-url is required"), quiet)
+	}
+
+	feed, err := rss2.Generate(args[0], &rss2.GenerateOptions{
+		Title:       channelTitle,
+		Link:        channelLink,
+		Description: channelDescription,
+		BaseURL:     baseURL,
+		Author:      author,
+		Excerpt:     excerpt,
+		Limit:       limit,
+	})
+	cli.ExitOnError(app.Eout, err, quiet)
+
+	src, err := feed.ToXML()
+	cli.ExitOnError(app.Eout, err, quiet)
+	fmt.Fprintf(app.Out, "%s", src)
+	if newLine {
+		fmt.Fprintln(app.Out, "")
+	}
+}
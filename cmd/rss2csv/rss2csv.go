@@ -0,0 +1,233 @@
+//
+// rss2csv is a command line utility that converts the items of an RSS 2
+// file to CSV or TSV and back.
+//
+// @author R. S. Doiel, <rsdoiel@library.caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	// Caltech Library Packages
+	"github.com/caltechlibrary/cli"
+	"github.com/caltechlibrary/rss2"
+)
+
+var (
+	synopsis = `rss2csv converts RSS 2 items to CSV or TSV and back`
+
+	description = `
+_rss2csv_ reads an RSS 2 document and writes a row for each
+item with a column for each DATA_PATH (e.g. .item[].title).
+Any field or extension can be a column, for example
+.item[].enclosure.url or .item[].dc:creator. Without a
+DATA_PATH the columns are title, link, description, author,
+category, guid and pubDate. Fields holding several values,
+like category, are joined by the "-delimiter". Values are
+quoted following RFC 4180.
+
+With "-import" the input is CSV (or TSV) and the output is an
+RSS 2 document. The header row names the item field for each
+column using the names _rss2csv_ writes, columns with an empty
+name are skipped. The channel title, link and description are
+set with "-title", "-link" and "-description".
+`
+
+	examples = `
+Write the title, link and categories of the items in *rss.xml*
+as CSV.
+
+` + "```" + `
+    rss2csv -i rss.xml .item[].title .item[].link .item[].category
+` + "```" + `
+
+Write podcast enclosures as tab separated values.
+
+` + "```" + `
+    rss2csv -i rss.xml -tsv .item[].title .item[].enclosure.url
+` + "```" + `
+
+Create a feed from a spreadsheet exported as *items.csv*.
+
+` + "```" + `
+    rss2csv -import -i items.csv -o rss.xml \
+        -title "Library News" -link "https://library.example.edu/news" \
+        -description "News from the library"
+` + "```" + `
+`
+
+	license = `
+%s %s
+
+Copyright (c) 2020, Caltech
+All rights not granted herein are expressly reserved by Caltech.
+
+Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+`
+
+	// Standard options
+	showHelp         bool
+	showVersion      bool
+	showLicense      bool
+	showExamples     bool
+	inputFName       string
+	outputFName      string
+	quiet            bool
+	newLine          bool
+	generateMarkdown bool
+	generateManPage  bool
+
+	// Application options
+	tabSeparated       bool
+	noHeader           bool
+	delimiter          string
+	importCSV          bool
+	channelTitle       string
+	channelLink        string
+	channelDescription string
+	sanitize           bool
+)
+
+func main() {
+	app := cli.NewCli(rss2.Version)
+	appName := app.AppName()
+
+	// Document non-option parameters
+	app.SetParams("[DATA_PATH ...]")
+
+	// Add Help Docs
+	app.AddHelp("synopsis", []byte(synopsis))
+	app.AddHelp("description", []byte(description))
+	app.AddHelp("examples", []byte(examples))
+	app.AddHelp("license", []byte(fmt.Sprintf(license, appName, rss2.Version)))
+
+	// Standard Options
+	app.BoolVar(&showHelp, "h,help", false, "display help")
+	app.BoolVar(&showLicense, "l,license", false, "display license")
+	app.BoolVar(&showVersion, "v,version", false, "display version")
+	app.BoolVar(&showExamples, "examples", false, "display examples")
+	app.BoolVar(&quiet, "quiet", false, "suppress error messages")
+	app.BoolVar(&newLine, "nl,newline", false, "add trailing newline")
+	app.StringVar(&inputFName, "i,input", "", "set input filename")
+	app.StringVar(&outputFName, "o,output", "", "set output filename")
+	app.BoolVar(&generateMarkdown, "generate-markdown", false, "generate Markdown documentation")
+	app.BoolVar(&generateManPage, "generate-manpage", false, "generate man page")
+
+	// Application Options
+	app.BoolVar(&tabSeparated, "t,tsv", false, "use tab separated values instead of CSV")
+	app.BoolVar(&noHeader, "no-header", false, "leave out the header row")
+	app.StringVar(&delimiter, "d,delimiter", ";", "set delimiter joining multiple values")
+	app.BoolVar(&importCSV, "import", false, "read CSV and write RSS 2 XML")
+	app.StringVar(&channelTitle, "title", "", "set the channel title when importing")
+	app.StringVar(&channelLink, "link", "", "set the channel link when importing")
+	app.StringVar(&channelDescription, "description", "", "set the channel description when importing")
+	app.BoolVar(&sanitize, "sanitize", false, "remove unsafe HTML from descriptions and content")
+
+	// Process environment and options
+	app.Parse()
+	args := app.Args()
+
+	// Setup I/O
+	var err error
+
+	app.Eout = os.Stderr
+	app.In, err = cli.Open(inputFName, os.Stdin)
+	cli.ExitOnError(app.Eout, err, quiet)
+	defer cli.CloseFile(inputFName, app.In)
+
+	app.Out, err = cli.Create(outputFName, os.Stdout)
+	cli.ExitOnError(app.Eout, err, quiet)
+	defer cli.CloseFile(outputFName, app.Out)
+
+	// Handle options
+	if generateMarkdown {
+		app.GenerateMarkdown(os.Stdout)
+		os.Exit(0)
+	}
+	if generateManPage {
+		app.GenerateManPage(os.Stdout)
+		os.Exit(0)
+	}
+	if showHelp || showExamples {
+		if len(args) > 0 {
+			fmt.Fprintln(app.Out, app.Help(args...))
+		} else {
+			app.Usage(app.Out)
+		}
+		os.Exit(0)
+	}
+	if showLicense {
+		fmt.Fprintln(app.Out, app.License())
+		os.Exit(0)
+	}
+	if showVersion {
+		fmt.Fprintln(app.Out, app.Version())
+		os.Exit(0)
+	}
+
+	opts := &rss2.CSVOptions{
+		Delimiter: delimiter,
+		NoHeader:  noHeader,
+	}
+	if tabSeparated {
+		opts.Comma = '\t'
+	}
+
+	src, err := ioutil.ReadAll(app.In)
+	cli.ExitOnError(app.Eout, err, quiet)
+
+	if importCSV {
+		if len(args) > 0 {
+			cli.ExitOnError(app.Eout, fmt.Errorf("DATA_PATH isn't used with -import, columns are named by the header row"), quiet)
+		}
+		feed, err := rss2.ParseCSV(src, opts)
+		cli.ExitOnError(app.Eout, err, quiet)
+		feed.Title = channelTitle
+		feed.Link = channelLink
+		feed.Description = channelDescription
+		if sanitize {
+			feed.Sanitize(nil)
+		}
+		src, err = feed.ToXML()
+		cli.ExitOnError(app.Eout, err, quiet)
+		if newLine {
+			fmt.Fprintf(app.Out, "%s\n", src)
+		} else {
+			fmt.Fprintf(app.Out, "%s", src)
+		}
+		os.Exit(0)
+	}
+
+	feed, err := rss2.Parse(src)
+	cli.ExitOnError(app.Eout, err, quiet)
+	if sanitize {
+		feed.Sanitize(nil)
+	}
+
+	err = feed.WriteCSV(app.Out, args, opts)
+	cli.ExitOnError(app.Eout, err, quiet)
+}
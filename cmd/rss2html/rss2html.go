@@ -0,0 +1,235 @@
+//
+// rss2html is a command line utility that renders an RSS 2 file as HTML
+// using Go templates.
+//
+// @author R. S. Doiel, <rsdoiel@library.caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	texttemplate "text/template"
+
+	// Caltech Library Packages
+	"github.com/caltechlibrary/cli"
+	"github.com/caltechlibrary/rss2"
+)
+
+var (
+	synopsis = `rss2html renders RSS 2 XML as HTML using Go templates`
+
+	description = `
+_rss2html_ reads an RSS 2 document and renders it through a
+Go html/template. The built in template is a section with the
+channel title and an accessible list of items, each with its
+linked title, publication date and a short plain text summary,
+ready to embed in a page.
+
+A TEMPLATE_FILENAME replaces the built in template. The
+template's data is the feed, e.g. .Title and .ItemList, each
+item has .Title, .Link, .Description, .PubDate and so on. The
+"-text" option uses text/template instead, no HTML escaping is
+applied. These helper functions are available
+
++ date LAYOUT VALUE, formats an RSS date with a Go time layout
++ isodate VALUE, formats an RSS date as RFC 3339
++ localdate VALUE, formats an RSS date for the "-locale"
++ truncate N TEXT, shortens text to N characters
++ striphtml TEXT, removes HTML markup
++ sanitize TEXT, keeps only safe HTML, e.g. {{sanitize .Description}}
++ locale, the "-locale" value
+
+The locale defaults to the LANG environment variable.
+`
+
+	examples = `
+Render the first ten items of *rss.xml* as an HTML list.
+
+` + "```" + `
+    rss2html -i rss.xml -limit 10 -o latest.html
+` + "```" + `
+
+Render with a custom template and French dates.
+
+` + "```" + `
+    rss2html -i rss.xml -locale fr news.tmpl
+` + "```" + `
+
+Where *news.tmpl* might contain
+
+` + "```" + `
+    <ul lang="{{locale}}">
+    {{range .ItemList}}
+      <li><a href="{{.Link}}">{{.Title}}</a> {{localdate .PubDate}}</li>
+    {{end}}
+    </ul>
+` + "```" + `
+`
+
+	license = `
+%s %s
+
+Copyright (c) 2020, Caltech
+All rights not granted herein are expressly reserved by Caltech.
+
+Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+`
+
+	// Standard options
+	showHelp         bool
+	showVersion      bool
+	showLicense      bool
+	showExamples     bool
+	inputFName       string
+	outputFName      string
+	quiet            bool
+	newLine          bool
+	generateMarkdown bool
+	generateManPage  bool
+
+	// Application options
+	locale   string
+	limit    int
+	textMode bool
+	sanitize bool
+)
+
+func main() {
+	app := cli.NewCli(rss2.Version)
+	appName := app.AppName()
+
+	// Document non-option parameters
+	app.SetParams("[TEMPLATE_FILENAME]")
+
+	// Add Help Docs
+	app.AddHelp("synopsis", []byte(synopsis))
+	app.AddHelp("description", []byte(description))
+	app.AddHelp("examples", []byte(examples))
+	app.AddHelp("license", []byte(fmt.Sprintf(license, appName, rss2.Version)))
+
+	// Standard Options
+	app.BoolVar(&showHelp, "h,help", false, "display help")
+	app.BoolVar(&showLicense, "l,license", false, "display license")
+	app.BoolVar(&showVersion, "v,version", false, "display version")
+	app.BoolVar(&showExamples, "examples", false, "display examples")
+	app.BoolVar(&quiet, "quiet", false, "suppress error messages")
+	app.BoolVar(&newLine, "nl,newline", false, "add trailing newline")
+	app.StringVar(&inputFName, "i,input", "", "set input filename")
+	app.StringVar(&outputFName, "o,output", "", "set output filename")
+	app.BoolVar(&generateMarkdown, "generate-markdown", false, "generate Markdown documentation")
+	app.BoolVar(&generateManPage, "generate-manpage", false, "generate man page")
+
+	// Application Options
+	app.StringVar(&locale, "locale", os.Getenv("LANG"), "set the locale used to format dates, e.g. en-US")
+	app.IntVar(&limit, "limit", 0, "render at most this many items")
+	app.BoolVar(&textMode, "text", false, "use text/template instead of html/template")
+	app.BoolVar(&sanitize, "sanitize", false, "remove unsafe HTML from descriptions and content")
+
+	// Process environment and options
+	app.Parse()
+	args := app.Args()
+
+	// Setup I/O
+	var err error
+
+	app.Eout = os.Stderr
+	app.In, err = cli.Open(inputFName, os.Stdin)
+	cli.ExitOnError(app.Eout, err, quiet)
+	defer cli.CloseFile(inputFName, app.In)
+
+	app.Out, err = cli.Create(outputFName, os.Stdout)
+	cli.ExitOnError(app.Eout, err, quiet)
+	defer cli.CloseFile(outputFName, app.Out)
+
+	// Handle options
+	if generateMarkdown {
+		app.GenerateMarkdown(os.Stdout)
+		os.Exit(0)
+	}
+	if generateManPage {
+		app.GenerateManPage(os.Stdout)
+		os.Exit(0)
+	}
+	if showHelp || showExamples {
+		if len(args) > 0 {
+			fmt.Fprintln(app.Out, app.Help(args...))
+		} else {
+			app.Usage(app.Out)
+		}
+		os.Exit(0)
+	}
+	if showLicense {
+		fmt.Fprintln(app.Out, app.License())
+		os.Exit(0)
+	}
+	if showVersion {
+		fmt.Fprintln(app.Out, app.Version())
+		os.Exit(0)
+	}
+
+	if len(args) > 1 {
+		cli.ExitOnError(app.Eout, fmt.Errorf("expected one TEMPLATE_FILENAME, try %s -help", appName), quiet)
+	}
+	tmplSrc := ""
+	if len(args) == 1 {
+		src, err := ioutil.ReadFile(args[0])
+		cli.ExitOnError(app.Eout, err, quiet)
+		tmplSrc = string(src)
+	}
+	if locale == "" || strings.EqualFold(locale, "C") || strings.EqualFold(locale, "POSIX") {
+		locale = "en-US"
+	}
+
+	src, err := ioutil.ReadAll(app.In)
+	cli.ExitOnError(app.Eout, err, quiet)
+
+	feed, err := rss2.Parse(src)
+	cli.ExitOnError(app.Eout, err, quiet)
+	if sanitize {
+		feed.Sanitize(nil)
+	}
+
+	if limit > 0 && limit < len(feed.ItemList) {
+		feed.ItemList = feed.ItemList[0:limit]
+	}
+
+	if textMode {
+		if tmplSrc == "" {
+			tmplSrc = rss2.DefaultHTMLTemplate
+		}
+		tmpl, err := texttemplate.New("rss2").Funcs(texttemplate.FuncMap(rss2.TemplateFuncs(locale))).Parse(tmplSrc)
+		cli.ExitOnError(app.Eout, err, quiet)
+		err = tmpl.Execute(app.Out, feed)
+		cli.ExitOnError(app.Eout, err, quiet)
+		os.Exit(0)
+	}
+
+	tmpl, err := rss2.HTMLTemplate(tmplSrc, locale)
+	cli.ExitOnError(app.Eout, err, quiet)
+	err = feed.RenderHTML(app.Out, tmpl)
+	cli.ExitOnError(app.Eout, err, quiet)
+}
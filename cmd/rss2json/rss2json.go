@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 
 	// Caltech Library Packages
 	"github.com/caltechlibrary/cli"
@@ -35,7 +36,47 @@ var (
 
 	description = `
 _rss2json_ does one thing. It is a program that 
-converts RSS v2 XML to JSON.
+converts RSS v2 XML to JSON. By default the JSON mirrors
+the RSS 2 document, with the "-format jsonfeed" option
+the output is a JSON Feed 1.1 document
+(see https://jsonfeed.org/version/1.1). The "-format yaml"
+and "-format toml" options write the RSS 2 fields as YAML or
+TOML in the order of the RSS 2 specification, multi-line text
+is written as YAML literal blocks or TOML multi-line strings.
+
+The "-import" option works the other way, reading a document
+in the "-format" given (rss2 JSON, jsonfeed, yaml or toml)
+and writing RSS 2 XML. Only a subset of YAML and TOML is read,
+block and one line flow collections, plain, quoted and block
+scalars in YAML and tables, arrays, strings, numbers, booleans
+and dates in TOML. YAML anchors, aliases, tags and multiple
+documents are rejected with an error.
+
+The "-query" option evaluates a jq expression against the
+JSON before it is written, each result is written on its own
+line. A subset of jq is supported, pipes, comma, "//", array
+and object construction, string interpolation, comparisons,
+arithmetic, if/then/else, try and functions such as select,
+map, length, keys, has, sort_by, group_by, unique_by,
+min_by, max_by, first, last, limit, join, split, test, sub,
+gsub, ascii_downcase, startswith, endswith, ltrimstr and
+rtrimstr. "fromdate" also accepts RSS dates. Use "-raw"
+to write string results without JSON quoting.
+
+The "-ndjson" option writes newline delimited JSON, one
+item per line, reading the feed as a stream so large feeds
+aren't held in memory. Each parameter is an input file and
+output goes to the "-output" file or standard out. Items can
+be annotated with "-channel" (adds channel_title and
+channel_link) and "-filename" (adds the input filename).
+
+The "-resolve" option makes relative URLs, including href and
+src in description and content HTML, absolute. They are resolved
+against xml:base, the item link or the channel link, "-base"
+gives the URL the feed was retrieved from for feeds without an
+absolute link. "-https" rewrites http URLs to https and
+"-strip-tracking" removes utm_* and similar tracking parameters,
+both imply "-resolve".
 `
 
 	examples = `
@@ -44,6 +85,50 @@ Convert *rss.xml* to *rss.json*.
 ` + "```" + `
     rss2json rss.xml rss.json
 ` + "```" + `
+
+Convert *rss.xml* to a JSON Feed in *feed.json*.
+
+` + "```" + `
+    rss2json -format jsonfeed rss.xml feed.json
+` + "```" + `
+
+Write the items of several feeds, one per line, noting the
+feed each came from.
+
+` + "```" + `
+    rss2json -ndjson -channel -filename -o items.ndjson news.xml events.xml
+` + "```" + `
+
+Write *rss.xml* as a YAML data file for a static site
+generator.
+
+` + "```" + `
+    rss2json -format yaml rss.xml _data/news.yaml
+` + "```" + `
+
+Encode a feed authored in YAML as RSS 2.
+
+` + "```" + `
+    rss2json -import -format yaml news.yaml rss.xml
+` + "```" + `
+
+List the titles of the items in *rss.xml*.
+
+` + "```" + `
+    rss2json -raw -query '.item[] | .title' rss.xml
+` + "```" + `
+
+List the five most recent links as a JSON array.
+
+` + "```" + `
+    rss2json -query '.item | sort_by(.pubDate | fromdate) | reverse | map(.link) | .[:5]' rss.xml
+` + "```" + `
+
+Count the items in each category.
+
+` + "```" + `
+    rss2json -query '[.item[].category[]?] | group_by(.) | map({(.[0]): length}) | add' rss.xml
+` + "```" + `
 `
 
 	license = `
@@ -76,7 +161,19 @@ THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
 	generateManPage  bool
 
 	// Application options
-	prettyPrint bool
+	prettyPrint  bool
+	outputFormat string
+	query        string
+	raw          bool
+	ndjson       bool
+	withChannel  bool
+	withFilename bool
+	importFeed   bool
+	sanitize     bool
+	resolve      bool
+	feedURL      string
+	https        bool
+	noTracking   bool
 )
 
 func main() {
@@ -106,15 +203,27 @@ func main() {
 
 	// Application Options
 	app.BoolVar(&prettyPrint, "p,pretty", false, "pretty print XML output")
+	app.StringVar(&outputFormat, "f,format", "rss2", "set output format, rss2, jsonfeed, yaml or toml")
+	app.BoolVar(&importFeed, "import", false, "read the -format given and write RSS 2 XML")
+	app.StringVar(&query, "q,query", "", "evaluate a jq expression against the JSON output")
+	app.BoolVar(&raw, "r,raw", false, "write string query results without JSON quoting")
+	app.BoolVar(&ndjson, "ndjson", false, "stream items as newline delimited JSON, parameters are input files")
+	app.BoolVar(&withChannel, "channel", false, "add the channel title and link to ndjson items")
+	app.BoolVar(&withFilename, "filename", false, "add the input filename to ndjson items")
+	app.BoolVar(&sanitize, "sanitize", false, "remove unsafe HTML from descriptions and content")
+	app.BoolVar(&resolve, "resolve", false, "make relative URLs absolute")
+	app.StringVar(&feedURL, "base", "", "set the URL the feed was retrieved from, implies -resolve")
+	app.BoolVar(&https, "https", false, "rewrite http URLs to https, implies -resolve")
+	app.BoolVar(&noTracking, "strip-tracking", false, "remove utm_* and other tracking parameters from URLs, implies -resolve")
 
 	// Process environment and options
 	app.Parse()
 	args := app.Args()
 
-	if len(args) > 0 {
+	if len(args) > 0 && !ndjson {
 		inputFName = args[0]
 	}
-	if len(args) > 1 {
+	if len(args) > 1 && !ndjson {
 		outputFName = args[1]
 	}
 
@@ -156,17 +265,126 @@ func main() {
 		os.Exit(0)
 	}
 
+	resolve = resolve || feedURL != "" || https || noTracking
+	if ndjson {
+		if query != "" || strings.ToLower(outputFormat) != "rss2" || resolve {
+			cli.ExitOnError(app.Eout, fmt.Errorf("-ndjson can't be combined with -query, -format or -resolve"), quiet)
+		}
+		if len(args) == 0 {
+			args = []string{inputFName}
+		}
+		for _, fName := range args {
+			in, err := cli.Open(fName, os.Stdin)
+			cli.ExitOnError(app.Eout, err, quiet)
+			opts := &rss2.NDJSONOptions{Channel: withChannel}
+			if sanitize {
+				opts.Policy = rss2.DefaultPolicy()
+			}
+			if withFilename {
+				opts.Filename = fName
+			}
+			_, err = rss2.WriteNDJSON(app.Out, in, opts)
+			cli.CloseFile(fName, in)
+			if err != nil {
+				cli.ExitOnError(app.Eout, fmt.Errorf("%s, %s", fName, err), quiet)
+			}
+		}
+		os.Exit(0)
+	}
+
 	src, err := ioutil.ReadAll(app.In)
 	cli.ExitOnError(app.Eout, err, quiet)
 
+	if importFeed {
+		var feed *rss2.RSS2
+		switch strings.ToLower(outputFormat) {
+		case "rss2", "":
+			feed = new(rss2.RSS2)
+			err = json.Unmarshal(src, feed)
+		case "jsonfeed":
+			feed, err = rss2.ParseJSONFeed(src)
+		case "yaml":
+			feed, err = rss2.ParseYAML(src)
+		case "toml":
+			feed, err = rss2.ParseTOML(src)
+		default:
+			err = fmt.Errorf("unsupported format %q", outputFormat)
+		}
+		cli.ExitOnError(app.Eout, err, quiet)
+		if sanitize {
+			feed.Sanitize(nil)
+		}
+		src, err = feed.ToXML()
+		cli.ExitOnError(app.Eout, err, quiet)
+		fmt.Fprintf(app.Out, "%s\n", src)
+		os.Exit(0)
+	}
+
 	feed, err := rss2.Parse(src)
 	cli.ExitOnError(app.Eout, err, quiet)
+	if sanitize {
+		feed.Sanitize(nil)
+	}
+	if resolve {
+		feed.ResolveURLs(&rss2.URLOptions{
+			FeedURL:       feedURL,
+			HTTPS:         https,
+			StripTracking: noTracking,
+		})
+	}
+
+	var data interface{}
+	switch strings.ToLower(outputFormat) {
+	case "rss2", "":
+		data = feed
+	case "jsonfeed":
+		data = rss2.NewJSONFeed(feed)
+	case "yaml", "toml":
+		// queries run against the rss2 JSON model
+		data = feed
+	default:
+		cli.ExitOnError(app.Eout, fmt.Errorf("unsupported format %q", outputFormat), quiet)
+	}
 
-	if prettyPrint {
-		src, err = json.MarshalIndent(feed, "", "    ")
+	if query != "" {
+		q, err := rss2.CompileQuery(query)
 		cli.ExitOnError(app.Eout, err, quiet)
-	} else {
-		src, err = json.Marshal(feed)
+		// Query the JSON model, not the Go structs
+		src, err = json.Marshal(data)
+		cli.ExitOnError(app.Eout, err, quiet)
+		var model interface{}
+		err = json.Unmarshal(src, &model)
+		cli.ExitOnError(app.Eout, err, quiet)
+		results, err := q.Eval(model)
+		cli.ExitOnError(app.Eout, err, quiet)
+		for _, result := range results {
+			if s, ok := result.(string); ok && raw {
+				fmt.Fprintln(app.Out, s)
+				continue
+			}
+			if prettyPrint {
+				src, err = json.MarshalIndent(result, "", "    ")
+			} else {
+				src, err = json.Marshal(result)
+			}
+			cli.ExitOnError(app.Eout, err, quiet)
+			fmt.Fprintf(app.Out, "%s\n", src)
+		}
+		os.Exit(0)
+	}
+
+	switch {
+	case strings.ToLower(outputFormat) == "yaml":
+		src, err = feed.ToYAML()
+		cli.ExitOnError(app.Eout, err, quiet)
+	case strings.ToLower(outputFormat) == "toml":
+		src, err = feed.ToTOML()
+		cli.ExitOnError(app.Eout, err, quiet)
+	case prettyPrint:
+		src, err = json.MarshalIndent(data, "", "    ")
+		cli.ExitOnError(app.Eout, err, quiet)
+	default:
+		src, err = json.Marshal(data)
 		cli.ExitOnError(app.Eout, err, quiet)
 	}
 
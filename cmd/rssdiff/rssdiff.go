@@ -0,0 +1,209 @@
+//
+// rssdiff is a command line utility that reports what changed between
+// two versions of a feed.
+//
+// @author R. S. Doiel, <rsdoiel@library.caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	// Caltech Library Packages
+	"github.com/caltechlibrary/cli"
+	"github.com/caltechlibrary/rss2"
+)
+
+var (
+	synopsis = `rssdiff reports what changed between two versions of a feed`
+
+	description = `
+_rssdiff_ compares OLD_FILENAME and NEW_FILENAME, RSS 0.9x, 1.0 or
+2.0, Atom 1.0 or JSON Feed, and reports the channel fields changed
+and the items added, removed or modified. Items are matched by guid
+or else by link, ignoring case, trailing slashes, query parameter
+order and tracking parameters. A modified item is followed by its
+changed fields, e.g.
+
+` + "```" + `
+    channel lastBuildDate: "Mon, 18 Dec 2017 08:00:00 +0000" -> "Tue, 19 Dec 2017 08:00:00 +0000"
+    added Reading group <https://library.example.edu/events/1>
+    modified Holiday hours <news-2>
+        description: "Closed on Monday" -> "Closed on Monday and Tuesday"
+` + "```" + `
+
+"-format json" writes the report as a JSON object with "channel",
+"added", "removed" and "modified" lists. Like _diff_, _rssdiff_
+exits with 0 when the feeds are the same, 1 when they differ and 2
+when a feed can't be read or parsed or the options are wrong.
+`
+
+	examples = `
+Check if a harvested feed changed since the last harvest
+
+` + "```" + `
+    rssdiff news-yesterday.xml news.xml
+` + "```" + `
+
+List the titles of the items added as JSON
+
+` + "```" + `
+    rssdiff -format json news-yesterday.xml news.xml | jq '.added[].title'
+` + "```" + `
+`
+
+	license = `
+%s %s
+
+Copyright (c) 2020, Caltech
+All rights not granted herein are expressly reserved by Caltech.
+
+Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+`
+
+	// Standard options
+	showHelp         bool
+	showVersion      bool
+	showLicense      bool
+	showExamples     bool
+	outputFName      string
+	quiet            bool
+	newLine          bool
+	generateMarkdown bool
+	generateManPage  bool
+
+	// Application options
+	format string
+)
+
+// exitOnError reports err and exits with 2, exit 1 means the feeds
+// differ
+func exitOnError(eout io.Writer, err error, quiet bool) {
+	if err != nil {
+		if !quiet {
+			fmt.Fprintln(eout, err)
+		}
+		os.Exit(2)
+	}
+}
+
+func main() {
+	app := cli.NewCli(rss2.Version)
+	appName := app.AppName()
+
+	// Document non-option parameters
+	app.SetParams("OLD_FILENAME", "NEW_FILENAME")
+
+	// Add Help Docs
+	app.AddHelp("synopsis", []byte(synopsis))
+	app.AddHelp("description", []byte(description))
+	app.AddHelp("examples", []byte(examples))
+	app.AddHelp("license", []byte(fmt.Sprintf(license, appName, rss2.Version)))
+
+	// Standard Options
+	app.BoolVar(&showHelp, "h,help", false, "display help")
+	app.BoolVar(&showLicense, "l,license", false, "display license")
+	app.BoolVar(&showVersion, "v,version", false, "display version")
+	app.BoolVar(&showExamples, "examples", false, "display examples")
+	app.BoolVar(&quiet, "quiet", false, "suppress error messages")
+	app.BoolVar(&newLine, "nl,newline", false, "add trailing newline")
+	app.StringVar(&outputFName, "o,output", "", "set output filename")
+	app.BoolVar(&generateMarkdown, "generate-markdown", false, "generate Markdown documentation")
+	app.BoolVar(&generateManPage, "generate-manpage", false, "generate man page")
+
+	// Application Options
+	app.StringVar(&format, "format", "text", "set the output format, text or json")
+
+	// Process environment and options
+	app.Parse()
+	args := app.Args()
+
+	// Setup I/O
+	var err error
+
+	app.Eout = os.Stderr
+	app.Out, err = cli.Create(outputFName, os.Stdout)
+	exitOnError(app.Eout, err, quiet)
+	defer cli.CloseFile(outputFName, app.Out)
+
+	// Handle options
+	if generateMarkdown {
+		app.GenerateMarkdown(os.Stdout)
+		os.Exit(0)
+	}
+	if generateManPage {
+		app.GenerateManPage(os.Stdout)
+		os.Exit(0)
+	}
+	if showHelp || showExamples {
+		if len(args) > 0 {
+			fmt.Fprintln(app.Out, app.Help(args...))
+		} else {
+			app.Usage(app.Out)
+		}
+		os.Exit(0)
+	}
+	if showLicense {
+		fmt.Fprintln(app.Out, app.License())
+		os.Exit(0)
+	}
+	if showVersion {
+		fmt.Fprintln(app.Out, app.Version())
+		os.Exit(0)
+	}
+
+	if len(args) != 2 {
+		exitOnError(app.Eout, fmt.Errorf("expected OLD_FILENAME and NEW_FILENAME, try %s -help", appName), quiet)
+	}
+	if format != "text" && format != "json" {
+		exitOnError(app.Eout, fmt.Errorf("unsupported format %q, try text or json", format), quiet)
+	}
+
+	feeds := []*rss2.RSS2{}
+	for _, fName := range args {
+		in, err := cli.Open(fName, os.Stdin)
+		exitOnError(app.Eout, err, quiet)
+		feed, err := rss2.ParseAny(in)
+		cli.CloseFile(fName, in)
+		if err != nil {
+			exitOnError(app.Eout, fmt.Errorf("%s, %s", fName, err), quiet)
+		}
+		feeds = append(feeds, feed.RSS2())
+	}
+
+	d := rss2.Diff(feeds[0], feeds[1])
+	if format == "json" {
+		src, err := json.MarshalIndent(d, "", "    ")
+		exitOnError(app.Eout, err, quiet)
+		fmt.Fprintf(app.Out, "%s\n", src)
+	} else {
+		fmt.Fprintf(app.Out, "%s", d)
+	}
+	if !d.Empty() {
+		os.Exit(1)
+	}
+}
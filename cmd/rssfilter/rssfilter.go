@@ -0,0 +1,267 @@
+//
+// rssfilter is a command line utility that reads an RSS 2 file and
+// returns the values selected by data paths.
+//
+// @author R. S. Doiel, <rsdoiel@library.caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	// Caltech Library Packages
+	"github.com/caltechlibrary/cli"
+	"github.com/caltechlibrary/rss2"
+)
+
+var (
+	synopsis = `rssfilter selects values from RSS 2 XML using data paths`
+
+	description = `
+_rssfilter_ reads an RSS 2 document and returns the values
+selected by one or more DATA_PATH. Paths that pass through
+the item list (e.g. .item[].title and .item[].link) are
+returned as one record per item. The "-where" option keeps
+only the items matching a predicate, "@" is the item being
+tested (e.g. '@.category == "Chemistry"').
+
+Output formats are
+
++ json, an array of records (the default)
++ ndjson, one JSON record per line
++ tsv, tab separated values with a header row
++ rss, an RSS 2 document containing the selected items
+
+For the rss format a DATA_PATH, if given, must select items
+(e.g. .item[-5:]).
+
+See the rss2 package documentation for the data path syntax.
+`
+
+	examples = `
+List the title and link of the last five items in *rss.xml*.
+
+` + "```" + `
+    rssfilter -i rss.xml '.item[-5:].title' '.item[-5:].link'
+` + "```" + `
+
+Links of items in the Chemistry category published after 2016
+as tab separated values.
+
+` + "```" + `
+    rssfilter -i rss.xml -format tsv \
+        -where '@.category == "Chemistry" && @.pubDate > "2016-01-01"' \
+        '.item[].link'
+` + "```" + `
+
+Write a new feed with the last five items.
+
+` + "```" + `
+    rssfilter -i rss.xml -o latest.xml -format rss '.item[-5:]'
+` + "```" + `
+`
+
+	license = `
+%s %s
+
+Copyright (c) 2020, Caltech
+All rights not granted herein are expressly reserved by Caltech.
+
+Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+`
+
+	// Standard options
+	showHelp         bool
+	showVersion      bool
+	showLicense      bool
+	showExamples     bool
+	inputFName       string
+	outputFName      string
+	quiet            bool
+	newLine          bool
+	generateMarkdown bool
+	generateManPage  bool
+
+	// Application options
+	prettyPrint  bool
+	outputFormat string
+	where        string
+	delimiter    string
+)
+
+// tsvValue joins a field's values and removes characters that would
+// break the row structure.
+func tsvValue(vals []string) string {
+	s := strings.Join(vals, delimiter)
+	return strings.NewReplacer("\t", " ", "\r", " ", "\n", " ").Replace(s)
+}
+
+func main() {
+	app := cli.NewCli(rss2.Version)
+	appName := app.AppName()
+
+	// Document non-option parameters
+	app.SetParams("DATA_PATH", "[DATA_PATH ...]")
+
+	// Add Help Docs
+	app.AddHelp("synopsis", []byte(synopsis))
+	app.AddHelp("description", []byte(description))
+	app.AddHelp("examples", []byte(examples))
+	app.AddHelp("license", []byte(fmt.Sprintf(license, appName, rss2.Version)))
+
+	// Standard Options
+	app.BoolVar(&showHelp, "h,help", false, "display help")
+	app.BoolVar(&showLicense, "l,license", false, "display license")
+	app.BoolVar(&showVersion, "v,version", false, "display version")
+	app.BoolVar(&showExamples, "examples", false, "display examples")
+	app.BoolVar(&quiet, "quiet", false, "suppress error messages")
+	app.BoolVar(&newLine, "nl,newline", false, "add trailing newline")
+	app.StringVar(&inputFName, "i,input", "", "set input filename")
+	app.StringVar(&outputFName, "o,output", "", "set output filename")
+	app.BoolVar(&generateMarkdown, "generate-markdown", false, "generate Markdown documentation")
+	app.BoolVar(&generateManPage, "generate-manpage", false, "generate man page")
+
+	// Application Options
+	app.BoolVar(&prettyPrint, "p,pretty", false, "pretty print JSON output")
+	app.StringVar(&outputFormat, "f,format", "json", "set output format, json, ndjson, tsv or rss")
+	app.StringVar(&where, "w,where", "", "keep items matching a predicate expression")
+	app.StringVar(&delimiter, "d,delimiter", ";", "set delimiter joining multiple values in tsv output")
+
+	// Process environment and options
+	app.Parse()
+	args := app.Args()
+
+	// Setup I/O
+	var err error
+
+	app.Eout = os.Stderr
+	app.In, err = cli.Open(inputFName, os.Stdin)
+	cli.ExitOnError(app.Eout, err, quiet)
+	defer cli.CloseFile(inputFName, app.In)
+
+	app.Out, err = cli.Create(outputFName, os.Stdout)
+	cli.ExitOnError(app.Eout, err, quiet)
+	defer cli.CloseFile(outputFName, app.Out)
+
+	// Handle options
+	if generateMarkdown {
+		app.GenerateMarkdown(os.Stdout)
+		os.Exit(0)
+	}
+	if generateManPage {
+		app.GenerateManPage(os.Stdout)
+		os.Exit(0)
+	}
+	if showHelp || showExamples {
+		if len(args) > 0 {
+			fmt.Fprintln(app.Out, app.Help(args...))
+		} else {
+			app.Usage(app.Out)
+		}
+		os.Exit(0)
+	}
+	if showLicense {
+		fmt.Fprintln(app.Out, app.License())
+		os.Exit(0)
+	}
+	if showVersion {
+		fmt.Fprintln(app.Out, app.Version())
+		os.Exit(0)
+	}
+
+	outputFormat = strings.ToLower(outputFormat)
+	if len(args) == 0 && outputFormat != "rss" {
+		cli.ExitOnError(app.Eout, fmt.Errorf("missing DATA_PATH, try %s -help", appName), quiet)
+	}
+
+	src, err := ioutil.ReadAll(app.In)
+	cli.ExitOnError(app.Eout, err, quiet)
+
+	feed, err := rss2.Parse(src)
+	cli.ExitOnError(app.Eout, err, quiet)
+
+	if where != "" {
+		feed, err = feed.SelectPath(".item[?(" + where + ")]")
+		cli.ExitOnError(app.Eout, err, quiet)
+	}
+
+	if outputFormat == "rss" {
+		if len(args) > 1 {
+			cli.ExitOnError(app.Eout, fmt.Errorf("rss output takes one DATA_PATH selecting items"), quiet)
+		}
+		if len(args) == 1 {
+			feed, err = feed.SelectPath(args[0])
+			cli.ExitOnError(app.Eout, err, quiet)
+		}
+		src, err = feed.ToXML()
+		cli.ExitOnError(app.Eout, err, quiet)
+		fmt.Fprintf(app.Out, "%s\n", src)
+		os.Exit(0)
+	}
+
+	records, err := feed.Records(args)
+	cli.ExitOnError(app.Eout, err, quiet)
+
+	switch outputFormat {
+	case "json":
+		if prettyPrint {
+			src, err = json.MarshalIndent(records, "", "    ")
+		} else {
+			src, err = json.Marshal(records)
+		}
+		cli.ExitOnError(app.Eout, err, quiet)
+		if newLine {
+			fmt.Fprintf(app.Out, "%s\n", src)
+		} else {
+			fmt.Fprintf(app.Out, "%s", src)
+		}
+	case "ndjson":
+		for _, record := range records {
+			src, err = json.Marshal(record)
+			cli.ExitOnError(app.Eout, err, quiet)
+			fmt.Fprintf(app.Out, "%s\n", src)
+		}
+	case "tsv":
+		fields, err := rss2.RecordFields(args)
+		cli.ExitOnError(app.Eout, err, quiet)
+		header := []string{}
+		for _, field := range fields {
+			header = append(header, strings.TrimPrefix(field, "."))
+		}
+		fmt.Fprintln(app.Out, strings.Join(header, "\t"))
+		for _, record := range records {
+			row := []string{}
+			for _, field := range record.Fields {
+				row = append(row, tsvValue(record.Strings(field)))
+			}
+			fmt.Fprintln(app.Out, strings.Join(row, "\t"))
+		}
+	default:
+		cli.ExitOnError(app.Eout, fmt.Errorf("unsupported format %q", outputFormat), quiet)
+	}
+}
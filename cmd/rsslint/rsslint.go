@@ -0,0 +1,342 @@
+//
+// rsslint is a command line utility that checks RSS 2 XML against the
+// RSS 2.0 specification.
+//
+// @author R. S. Doiel, <rsdoiel@library.caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	// Caltech Library Packages
+	"github.com/caltechlibrary/cli"
+	"github.com/caltechlibrary/rss2"
+)
+
+var (
+	synopsis = `rsslint checks RSS 2 XML against the RSS 2.0 specification`
+
+	description = `
+_rsslint_ reads each FILENAME (or standard input) and reports the
+problems found, one per line, as
+
+` + "```" + `
+    FILENAME:LINE:COLUMN: SEVERITY RULE MESSAGE
+` + "```" + `
+
+where the line and column are the start of the element at fault.
+Errors are violations of the specification, warnings are allowed
+but likely to cause problems for feed readers. _rsslint_ exits
+with 1 when any errors are found.
+
+The "-format" option also supports "json", a list of issues, and
+"sarif", a SARIF 2.1.0 log for editors and code scanning tools.
+Rules can be turned off with "-disable", "-rules" lists them.
+`
+
+	examples = `
+Check a feed
+
+` + "```" + `
+    rsslint rss.xml
+` + "```" + `
+
+Check several feeds ignoring email addresses and dates
+
+` + "```" + `
+    rsslint -disable email,date-rfc822 *.xml
+` + "```" + `
+
+Write a SARIF log
+
+` + "```" + `
+    rsslint -format sarif -o rsslint.sarif rss.xml
+` + "```" + `
+`
+
+	license = `
+%s %s
+
+Copyright (c) 2020, Caltech
+All rights not granted herein are expressly reserved by Caltech.
+
+Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+`
+
+	// Standard options
+	showHelp         bool
+	showVersion      bool
+	showLicense      bool
+	showExamples     bool
+	inputFName       string
+	outputFName      string
+	quiet            bool
+	newLine          bool
+	generateMarkdown bool
+	generateManPage  bool
+
+	// Application options
+	format    string
+	disable   string
+	showRules bool
+)
+
+// xmlRule is reported for documents that can't be parsed
+const xmlRule = "xml"
+
+// lintIssue is an issue found in a file
+type lintIssue struct {
+	File string `json:"file"`
+	rss2.Position
+	rss2.Issue
+}
+
+// lint parses and validates src returning the issues not disabled
+func lint(fName string, src []byte, disabled map[string]bool) []lintIssue {
+	feed, sm, err := rss2.ParseWithSourceMap(src)
+	if err != nil {
+		if disabled[xmlRule] {
+			return nil
+		}
+		issue := lintIssue{File: fName}
+		issue.Position = rss2.Position{Line: 1, Column: 1}
+		if serr, ok := err.(*xml.SyntaxError); ok {
+			issue.Line = serr.Line
+		}
+		issue.Issue = rss2.Issue{Rule: xmlRule, Severity: rss2.SeverityError, Message: err.Error()}
+		return []lintIssue{issue}
+	}
+	issues := []lintIssue{}
+	for _, issue := range feed.Validate() {
+		if disabled[issue.Rule] {
+			continue
+		}
+		pos, _ := sm.Lookup(issue.Path)
+		issues = append(issues, lintIssue{File: fName, Position: pos, Issue: issue})
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Offset < issues[j].Offset
+	})
+	return issues
+}
+
+// sarifLog returns the issues as a SARIF 2.1.0 log
+func sarifLog(appName string, issues []lintIssue) map[string]interface{} {
+	ids := []string{xmlRule}
+	for id := range rss2.ValidationRules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	rules := []interface{}{}
+	for _, id := range ids {
+		text := rss2.ValidationRules[id]
+		if id == xmlRule {
+			text = "the document is well formed RSS 2 XML"
+		}
+		rules = append(rules, map[string]interface{}{
+			"id":               id,
+			"shortDescription": map[string]interface{}{"text": text},
+		})
+	}
+	results := []interface{}{}
+	for _, issue := range issues {
+		results = append(results, map[string]interface{}{
+			"ruleId":  issue.Rule,
+			"level":   string(issue.Severity),
+			"message": map[string]interface{}{"text": issue.Message},
+			"locations": []interface{}{
+				map[string]interface{}{
+					"physicalLocation": map[string]interface{}{
+						"artifactLocation": map[string]interface{}{"uri": issue.File},
+						"region": map[string]interface{}{
+							"startLine":   issue.Line,
+							"startColumn": issue.Column,
+						},
+					},
+					"logicalLocations": []interface{}{
+						map[string]interface{}{"fullyQualifiedName": issue.Path},
+					},
+				},
+			},
+		})
+	}
+	return map[string]interface{}{
+		"version": "2.1.0",
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"runs": []interface{}{
+			map[string]interface{}{
+				"tool": map[string]interface{}{
+					"driver": map[string]interface{}{
+						"name":    appName,
+						"version": rss2.Version,
+						"rules":   rules,
+					},
+				},
+				"results": results,
+			},
+		},
+	}
+}
+
+func main() {
+	app := cli.NewCli(rss2.Version)
+	appName := app.AppName()
+
+	// Document non-option parameters
+	app.SetParams("[FILENAME ...]")
+
+	// Add Help Docs
+	app.AddHelp("synopsis", []byte(synopsis))
+	app.AddHelp("description", []byte(description))
+	app.AddHelp("examples", []byte(examples))
+	app.AddHelp("license", []byte(fmt.Sprintf(license, appName, rss2.Version)))
+
+	// Standard Options
+	app.BoolVar(&showHelp, "h,help", false, "display help")
+	app.BoolVar(&showLicense, "l,license", false, "display license")
+	app.BoolVar(&showVersion, "v,version", false, "display version")
+	app.BoolVar(&showExamples, "examples", false, "display examples")
+	app.BoolVar(&quiet, "quiet", false, "suppress error messages")
+	app.BoolVar(&newLine, "nl,newline", false, "add trailing newline")
+	app.StringVar(&inputFName, "i,input", "", "set input filename")
+	app.StringVar(&outputFName, "o,output", "", "set output filename")
+	app.BoolVar(&generateMarkdown, "generate-markdown", false, "generate Markdown documentation")
+	app.BoolVar(&generateManPage, "generate-manpage", false, "generate man page")
+
+	// Application Options
+	app.StringVar(&format, "format", "text", "set the output format, text, json or sarif")
+	app.StringVar(&disable, "disable", "", "turn off a comma separated list of rules")
+	app.BoolVar(&showRules, "rules", false, "list the rules checked")
+
+	// Process environment and options
+	app.Parse()
+	args := app.Args()
+
+	// Setup I/O
+	var err error
+
+	app.Eout = os.Stderr
+	app.In, err = cli.Open(inputFName, os.Stdin)
+	cli.ExitOnError(app.Eout, err, quiet)
+	defer cli.CloseFile(inputFName, app.In)
+
+	app.Out, err = cli.Create(outputFName, os.Stdout)
+	cli.ExitOnError(app.Eout, err, quiet)
+	defer cli.CloseFile(outputFName, app.Out)
+
+	// Handle options
+	if generateMarkdown {
+		app.GenerateMarkdown(os.Stdout)
+		os.Exit(0)
+	}
+	if generateManPage {
+		app.GenerateManPage(os.Stdout)
+		os.Exit(0)
+	}
+	if showHelp || showExamples {
+		if len(args) > 0 {
+			fmt.Fprintln(app.Out, app.Help(args...))
+		} else {
+			app.Usage(app.Out)
+		}
+		os.Exit(0)
+	}
+	if showLicense {
+		fmt.Fprintln(app.Out, app.License())
+		os.Exit(0)
+	}
+	if showVersion {
+		fmt.Fprintln(app.Out, app.Version())
+		os.Exit(0)
+	}
+
+	disabled := map[string]bool{}
+	for _, id := range strings.Split(disable, ",") {
+		if id = strings.TrimSpace(id); id == "" {
+			continue
+		}
+		if _, ok := rss2.ValidationRules[id]; !ok && id != xmlRule {
+			cli.ExitOnError(app.Eout, fmt.Errorf("unknown rule %q, try %s -rules", id, appName), quiet)
+		}
+		disabled[id] = true
+	}
+	if showRules {
+		ids := []string{}
+		for id := range rss2.ValidationRules {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		fmt.Fprintf(app.Out, "%s\n    the document is well formed RSS 2 XML\n", xmlRule)
+		for _, id := range ids {
+			fmt.Fprintf(app.Out, "%s\n    %s\n", id, rss2.ValidationRules[id])
+		}
+		os.Exit(0)
+	}
+	if format != "text" && format != "json" && format != "sarif" {
+		cli.ExitOnError(app.Eout, fmt.Errorf("unsupported format %q, try text, json or sarif", format), quiet)
+	}
+
+	issues := []lintIssue{}
+	if len(args) == 0 {
+		src, err := ioutil.ReadAll(app.In)
+		cli.ExitOnError(app.Eout, err, quiet)
+		fName := inputFName
+		if fName == "" {
+			fName = "-"
+		}
+		issues = append(issues, lint(fName, src, disabled)...)
+	}
+	for _, fName := range args {
+		src, err := ioutil.ReadFile(fName)
+		cli.ExitOnError(app.Eout, err, quiet)
+		issues = append(issues, lint(fName, src, disabled)...)
+	}
+
+	switch format {
+	case "json":
+		src, err := json.MarshalIndent(issues, "", "    ")
+		cli.ExitOnError(app.Eout, err, quiet)
+		fmt.Fprintf(app.Out, "%s\n", src)
+	case "sarif":
+		src, err := json.MarshalIndent(sarifLog(appName, issues), "", "    ")
+		cli.ExitOnError(app.Eout, err, quiet)
+		fmt.Fprintf(app.Out, "%s\n", src)
+	default:
+		for _, issue := range issues {
+			fmt.Fprintf(app.Out, "%s:%d:%d: %s %s %s\n", issue.File, issue.Line, issue.Column, issue.Severity, issue.Rule, issue.Message)
+		}
+	}
+	for _, issue := range issues {
+		if issue.Severity == rss2.SeverityError {
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+//
+// rssmerge is a command line utility that combines feeds into one RSS 2
+// feed.
+//
+// @author R. S. Doiel, <rsdoiel@library.caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package main
+
+import (
+	"fmt"
+	"os"
+
+	// Caltech Library Packages
+	"github.com/caltechlibrary/cli"
+	"github.com/caltechlibrary/rss2"
+)
+
+var (
+	synopsis = `rssmerge combines feeds into one RSS 2 feed`
+
+	description = `
+_rssmerge_ reads each FILENAME, RSS 0.9x, 1.0 or 2.0, Atom 1.0
+or JSON Feed, and writes an RSS 2 feed of all their items, newest
+first. An item whose guid or link has already been seen, e.g. a
+story carried by two feeds, is left out and each item's source is
+set to the title of the feed it came from.
+
+"-dedupe" sets how duplicates are found, a comma separated list of
+"guid", "link" (ignoring case, trailing slashes, query parameter
+order and tracking parameters), "title-date" and "content" (the
+text of the item), items matching on any of them are duplicates.
+"-keep" picks the duplicate kept, "first" (the newest in the merged
+order), "newest" or "longest".
+
+The merged channel is described by "-title", "-link" and
+"-description", "-limit" caps the number of items.
+`
+
+	examples = `
+Combine department feeds into the library news feed, keeping the
+fifty most recent items.
+
+` + "```" + `
+    rssmerge -title "Library News" \
+        -link https://library.example.edu/news \
+        -description "News from across the library" \
+        -limit 50 -o news.xml archives.xml sfl.xml techfiles.xml
+` + "```" + `
+
+Treat items with the same title and date or the same text as one
+story, keeping the one with the most text.
+
+` + "```" + `
+    rssmerge -dedupe guid,link,title-date,content -keep longest \
+        archives.xml sfl.xml
+` + "```" + `
+`
+
+	license = `
+%s %s
+
+Copyright (c) 2020, Caltech
+All rights not granted herein are expressly reserved by Caltech.
+
+Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+`
+
+	// Standard options
+	showHelp         bool
+	showVersion      bool
+	showLicense      bool
+	showExamples     bool
+	outputFName      string
+	quiet            bool
+	newLine          bool
+	generateMarkdown bool
+	generateManPage  bool
+
+	// Application options
+	channelTitle       string
+	channelLink        string
+	channelDescription string
+	limit              int
+	dedupeKeys         string
+	dedupeKeep         string
+)
+
+func main() {
+	app := cli.NewCli(rss2.Version)
+	appName := app.AppName()
+
+	// Document non-option parameters
+	app.SetParams("FILENAME", "[FILENAME ...]")
+
+	// Add Help Docs
+	app.AddHelp("synopsis", []byte(synopsis))
+	app.AddHelp("description", []byte(description))
+	app.AddHelp("examples", []byte(examples))
+	app.AddHelp("license", []byte(fmt.Sprintf(license, appName, rss2.Version)))
+
+	// Standard Options
+	app.BoolVar(&showHelp, "h,help", false, "display help")
+	app.BoolVar(&showLicense, "l,license", false, "display license")
+	app.BoolVar(&showVersion, "v,version", false, "display version")
+	app.BoolVar(&showExamples, "examples", false, "display examples")
+	app.BoolVar(&quiet, "quiet", false, "suppress error messages")
+	app.BoolVar(&newLine, "nl,newline", false, "add trailing newline")
+	app.StringVar(&outputFName, "o,output", "", "set output filename")
+	app.BoolVar(&generateMarkdown, "generate-markdown", false, "generate Markdown documentation")
+	app.BoolVar(&generateManPage, "generate-manpage", false, "generate man page")
+
+	// Application Options
+	app.StringVar(&channelTitle, "title", "", "set the channel title")
+	app.StringVar(&channelLink, "link", "", "set the channel link")
+	app.StringVar(&channelDescription, "description", "", "set the channel description")
+	app.IntVar(&limit, "limit", 0, "include at most this many items")
+	app.StringVar(&dedupeKeys, "dedupe", "guid,link", "match duplicates on guid, link, title-date and/or content")
+	app.StringVar(&dedupeKeep, "keep", "first", "keep the first, newest or longest duplicate")
+
+	// Process environment and options
+	app.Parse()
+	args := app.Args()
+
+	// Setup I/O
+	var err error
+
+	app.Eout = os.Stderr
+	app.Out, err = cli.Create(outputFName, os.Stdout)
+	cli.ExitOnError(app.Eout, err, quiet)
+	defer cli.CloseFile(outputFName, app.Out)
+
+	// Handle options
+	if generateMarkdown {
+		app.GenerateMarkdown(os.Stdout)
+		os.Exit(0)
+	}
+	if generateManPage {
+		app.GenerateManPage(os.Stdout)
+		os.Exit(0)
+	}
+	if showHelp || showExamples {
+		if len(args) > 0 {
+			fmt.Fprintln(app.Out, app.Help(args...))
+		} else {
+			app.Usage(app.Out)
+		}
+		os.Exit(0)
+	}
+	if showLicense {
+		fmt.Fprintln(app.Out, app.License())
+		os.Exit(0)
+	}
+	if showVersion {
+		fmt.Fprintln(app.Out, app.Version())
+		os.Exit(0)
+	}
+
+	if len(args) == 0 {
+		cli.ExitOnError(app.Eout, fmt.Errorf("expected one or more FILENAME, try %s -help", appName), quiet)
+	}
+
+	dedupe, err := rss2.ParseDedupeOptions(dedupeKeys, dedupeKeep)
+	cli.ExitOnError(app.Eout, err, quiet)
+
+	feeds := []*rss2.RSS2{}
+	for _, fName := range args {
+		in, err := cli.Open(fName, os.Stdin)
+		cli.ExitOnError(app.Eout, err, quiet)
+		feed, err := rss2.ParseAny(in)
+		cli.CloseFile(fName, in)
+		if err != nil {
+			cli.ExitOnError(app.Eout, fmt.Errorf("%s, %s", fName, err), quiet)
+		}
+		feeds = append(feeds, feed.RSS2())
+	}
+
+	merged := rss2.MergeWithOptions(&rss2.MergeOptions{
+		Title:       channelTitle,
+		Link:        channelLink,
+		Description: channelDescription,
+		Limit:       limit,
+		Dedupe:      dedupe,
+	}, feeds...)
+
+	src, err := merged.ToXML()
+	cli.ExitOnError(app.Eout, err, quiet)
+	fmt.Fprintf(app.Out, "%s", src)
+	if newLine {
+		fmt.Fprintln(app.Out, "")
+	}
+}
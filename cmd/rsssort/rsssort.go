@@ -0,0 +1,227 @@
+//
+// rsssort is a command line utility that sorts, truncates and pages the
+// items of an RSS 2 feed.
+//
+// @author R. S. Doiel, <rsdoiel@library.caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	// Caltech Library Packages
+	"github.com/caltechlibrary/cli"
+	"github.com/caltechlibrary/rss2"
+)
+
+var (
+	synopsis = `rsssort sorts, truncates and pages the items of an RSS 2 feed`
+
+	description = `
+_rsssort_ reads RSS 2 XML and writes it with the items sorted by
+"-sort", "date" (the pubDate compared as times), "title" (ignoring
+case) or a data path relative to the item such as ".author" or
+".enclosure.length". Items without a value for the key come last,
+"-desc" sorts in descending order.
+
+"-newest" keeps that many of the newest items, in the order they
+are in after sorting.
+
+"-page-size" splits the feed into pages of that many items written
+to the files named by "-pages", where %d stands for the page number
+counting from 1. Each page links to itself and the first, last,
+previous and next pages with atom:link elements as described in
+RFC 5005, "-page-url" is the URL a page will be published at with
+%d for the page number and "-first-url" the URL of the first page
+if different, e.g. the feed readers subscribe to.
+`
+
+	examples = `
+List the newest items first
+
+` + "```" + `
+    rsssort -sort date -desc -i news.xml
+` + "```" + `
+
+Keep the fifty newest items and publish them ten to a page, the first
+page as news.xml
+
+` + "```" + `
+    rsssort -sort date -desc -newest 50 -i archive.xml \
+        -page-size 10 -pages news-%d.xml \
+        -page-url https://library.example.edu/news-%d.xml \
+        -first-url https://library.example.edu/news.xml
+    mv news-1.xml news.xml
+` + "```" + `
+`
+
+	license = `
+%s %s
+
+Copyright (c) 2020, Caltech
+All rights not granted herein are expressly reserved by Caltech.
+
+Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+`
+
+	// Standard options
+	showHelp         bool
+	showVersion      bool
+	showLicense      bool
+	showExamples     bool
+	inputFName       string
+	outputFName      string
+	quiet            bool
+	newLine          bool
+	generateMarkdown bool
+	generateManPage  bool
+
+	// Application options
+	sortKey    string
+	descending bool
+	newest     int
+	pageSize   int
+	pageFNames string
+	pageURL    string
+	firstURL   string
+)
+
+func main() {
+	app := cli.NewCli(rss2.Version)
+	appName := app.AppName()
+
+	// Add Help Docs
+	app.AddHelp("synopsis", []byte(synopsis))
+	app.AddHelp("description", []byte(description))
+	app.AddHelp("examples", []byte(examples))
+	app.AddHelp("license", []byte(fmt.Sprintf(license, appName, rss2.Version)))
+
+	// Standard Options
+	app.BoolVar(&showHelp, "h,help", false, "display help")
+	app.BoolVar(&showLicense, "l,license", false, "display license")
+	app.BoolVar(&showVersion, "v,version", false, "display version")
+	app.BoolVar(&showExamples, "examples", false, "display examples")
+	app.BoolVar(&quiet, "quiet", false, "suppress error messages")
+	app.BoolVar(&newLine, "nl,newline", false, "add trailing newline")
+	app.StringVar(&inputFName, "i,input", "", "set input filename")
+	app.StringVar(&outputFName, "o,output", "", "set output filename")
+	app.BoolVar(&generateMarkdown, "generate-markdown", false, "generate Markdown documentation")
+	app.BoolVar(&generateManPage, "generate-manpage", false, "generate man page")
+
+	// Application Options
+	app.StringVar(&sortKey, "sort", "", "sort items by date, title or a data path")
+	app.BoolVar(&descending, "desc", false, "sort in descending order")
+	app.IntVar(&newest, "newest", 0, "keep this many of the newest items")
+	app.IntVar(&pageSize, "page-size", 0, "split the feed into pages of this many items")
+	app.StringVar(&pageFNames, "pages", "", "set the page filenames, %d is the page number")
+	app.StringVar(&pageURL, "page-url", "", "set the page URLs, %d is the page number")
+	app.StringVar(&firstURL, "first-url", "", "set the URL of the first page")
+
+	// Process environment and options
+	app.Parse()
+	args := app.Args()
+
+	// Setup I/O
+	var err error
+
+	app.Eout = os.Stderr
+	app.In, err = cli.Open(inputFName, os.Stdin)
+	cli.ExitOnError(app.Eout, err, quiet)
+	defer cli.CloseFile(inputFName, app.In)
+
+	app.Out, err = cli.Create(outputFName, os.Stdout)
+	cli.ExitOnError(app.Eout, err, quiet)
+	defer cli.CloseFile(outputFName, app.Out)
+
+	// Handle options
+	if generateMarkdown {
+		app.GenerateMarkdown(os.Stdout)
+		os.Exit(0)
+	}
+	if generateManPage {
+		app.GenerateManPage(os.Stdout)
+		os.Exit(0)
+	}
+	if showHelp || showExamples {
+		if len(args) > 0 {
+			fmt.Fprintln(app.Out, app.Help(args...))
+		} else {
+			app.Usage(app.Out)
+		}
+		os.Exit(0)
+	}
+	if showLicense {
+		fmt.Fprintln(app.Out, app.License())
+		os.Exit(0)
+	}
+	if showVersion {
+		fmt.Fprintln(app.Out, app.Version())
+		os.Exit(0)
+	}
+
+	if pageSize > 0 && (!strings.Contains(pageFNames, "%d") || pageURL == "") {
+		cli.ExitOnError(app.Eout, fmt.Errorf("-page-size needs -pages and -page-url, try %s -help", appName), quiet)
+	}
+
+	src, err := ioutil.ReadAll(app.In)
+	cli.ExitOnError(app.Eout, err, quiet)
+
+	feed, err := rss2.Parse(src)
+	cli.ExitOnError(app.Eout, err, quiet)
+
+	if sortKey != "" {
+		err = feed.Sort(sortKey, descending)
+		cli.ExitOnError(app.Eout, err, quiet)
+	}
+	if newest > 0 {
+		feed.Newest(newest)
+	}
+
+	if pageSize <= 0 {
+		src, err = feed.ToXML()
+		cli.ExitOnError(app.Eout, err, quiet)
+		fmt.Fprintf(app.Out, "%s", src)
+		if newLine {
+			fmt.Fprintln(app.Out, "")
+		}
+		os.Exit(0)
+	}
+
+	pages, err := feed.Paginate(&rss2.PageOptions{
+		Size:     pageSize,
+		URL:      pageURL,
+		FirstURL: firstURL,
+	})
+	cli.ExitOnError(app.Eout, err, quiet)
+	for i, page := range pages {
+		src, err = page.ToXML()
+		cli.ExitOnError(app.Eout, err, quiet)
+		fName := strings.Replace(pageFNames, "%d", fmt.Sprintf("%d", i+1), -1)
+		err = ioutil.WriteFile(fName, src, 0664)
+		cli.ExitOnError(app.Eout, err, quiet)
+	}
+}
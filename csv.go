@@ -0,0 +1,268 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// CSVColumns are the data paths WriteCSV uses when none are given
+var CSVColumns = []string{
+	".item[].title",
+	".item[].link",
+	".item[].description",
+	".item[].author",
+	".item[].category",
+	".item[].guid",
+	".item[].pubDate",
+}
+
+// CSVOptions controls the tabular form of items read and written by
+// WriteCSV and ParseCSV. A nil *CSVOptions is comma separated with a
+// header row and ";" joining multiple values.
+type CSVOptions struct {
+	// Comma is the field separator, ',' by default or '\t' for TSV
+	Comma rune
+	// Delimiter joins the values of fields holding several, e.g.
+	// categories, defaults to ";"
+	Delimiter string
+	// NoHeader leaves out the header row when writing
+	NoHeader bool
+}
+
+func (opts *CSVOptions) comma() rune {
+	if opts == nil || opts.Comma == 0 {
+		return ','
+	}
+	return opts.Comma
+}
+
+func (opts *CSVOptions) delimiter() string {
+	if opts == nil || opts.Delimiter == "" {
+		return ";"
+	}
+	return opts.Delimiter
+}
+
+// WriteCSV writes one row per item with a column per data path (see
+// Records), multiple values are joined by the options delimiter. The
+// header row names each column by its path relative to the item, e.g.
+// "title" or "media:content.url". Values are quoted per RFC 4180.
+func (r *RSS2) WriteCSV(w io.Writer, dataPaths []string, opts *CSVOptions) error {
+	if len(dataPaths) == 0 {
+		dataPaths = CSVColumns
+	}
+	records, err := r.Records(dataPaths)
+	if err != nil {
+		return err
+	}
+	fields, err := RecordFields(dataPaths)
+	if err != nil {
+		return err
+	}
+	out := csv.NewWriter(w)
+	out.Comma = opts.comma()
+	if opts == nil || !opts.NoHeader {
+		header := []string{}
+		for _, field := range fields {
+			header = append(header, strings.TrimPrefix(field, "."))
+		}
+		if err := out.Write(header); err != nil {
+			return err
+		}
+	}
+	for _, record := range records {
+		row := []string{}
+		for _, field := range record.Fields {
+			row = append(row, strings.Join(record.Strings(field), opts.delimiter()))
+		}
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// ToCSV returns the items of r as CSV with the default columns
+func (r *RSS2) ToCSV() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := r.WriteCSV(buf, nil, nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// csvColumn is where a CSV column is stored in an item
+type csvColumn struct {
+	name string
+	// field is the item field index, -1 for an extension element
+	field int
+	// sub is the enclosure field index for enclosure columns
+	sub int
+	// space, local and attr name an extension element and attribute
+	space, local, attr string
+}
+
+var enclosureType = reflect.TypeOf(Enclosure{})
+
+// csvColumnFor maps a header name to an item field. Names are those
+// WriteCSV produces, a leading ".item[]." is allowed so data paths can
+// be used as headers. Prefixed names (e.g. dc:creator or
+// media:content.url) become extension elements.
+func csvColumnFor(name string) (*csvColumn, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(name), ".")
+	for _, prefix := range []string{"item[].", "item."} {
+		s = strings.TrimPrefix(s, prefix)
+	}
+	field, sub := s, ""
+	if i := strings.Index(s, "."); i >= 0 {
+		field, sub = s[0:i], s[i+1:]
+	}
+	col := &csvColumn{name: name, field: -1, sub: -1}
+	if i := strings.Index(field, ":"); i > 0 {
+		prefix, local := field[0:i], field[i+1:]
+		space, ok := namespacePrefixes[prefix]
+		if !ok {
+			return nil, fmt.Errorf("column %q, unknown namespace prefix %q", name, prefix)
+		}
+		if strings.Contains(sub, ".") {
+			return nil, fmt.Errorf("column %q, nested extension elements are not supported", name)
+		}
+		col.space, col.local, col.attr = space, local, sub
+		return col, nil
+	}
+	f, ok := lookupField(itemType, field, false)
+	if !ok {
+		return nil, fmt.Errorf("column %q is not an item field", name)
+	}
+	col.field = f.Index[0]
+	switch {
+	case f.Type.Kind() == reflect.String && sub == "":
+	case f.Type == reflect.TypeOf([]string{}) && sub == "":
+	case f.Type == reflect.PtrTo(enclosureType):
+		e, ok := lookupField(enclosureType, sub, false)
+		if !ok {
+			return nil, fmt.Errorf("column %q, expected enclosure.url, enclosure.length or enclosure.type", name)
+		}
+		col.sub = e.Index[0]
+	default:
+		return nil, fmt.Errorf("column %q can't be set from text", name)
+	}
+	return col, nil
+}
+
+// set stores a cell value in item
+func (col *csvColumn) set(item *Item, value, delimiter string) {
+	if col.field < 0 {
+		var ext *Extension
+		for i := range item.Extensions {
+			if item.Extensions[i].XMLName.Space == col.space && item.Extensions[i].XMLName.Local == col.local {
+				ext = &item.Extensions[i]
+				break
+			}
+		}
+		if ext == nil {
+			item.Extensions = append(item.Extensions, Extension{XMLName: xml.Name{Space: col.space, Local: col.local}})
+			ext = &item.Extensions[len(item.Extensions)-1]
+		}
+		if col.attr == "" {
+			ext.Value = value
+		} else {
+			ext.Attrs = append(ext.Attrs, xml.Attr{Name: xml.Name{Local: col.attr}, Value: value})
+		}
+		return
+	}
+	v := reflect.ValueOf(item).Elem().Field(col.field)
+	switch {
+	case col.sub >= 0:
+		if v.IsNil() {
+			v.Set(reflect.ValueOf(&Enclosure{}))
+		}
+		v.Elem().Field(col.sub).SetString(value)
+	case v.Kind() == reflect.Slice:
+		vals := []string{}
+		for _, s := range strings.Split(value, delimiter) {
+			if s = strings.TrimSpace(s); s != "" {
+				vals = append(vals, s)
+			}
+		}
+		v.Set(reflect.ValueOf(vals))
+	default:
+		v.SetString(value)
+	}
+}
+
+// ParseCSV reads items from CSV (or TSV with opts.Comma set to '\t')
+// for bulk feed authoring. The first row names the item field each
+// column holds, using the same names WriteCSV writes (e.g. title,
+// link, category, enclosure.url, dc:creator). Empty cells are left
+// unset and multi-valued fields are split on the options delimiter.
+// The channel is left empty for the caller to fill in.
+func ParseCSV(buf []byte, opts *CSVOptions) (*RSS2, error) {
+	in := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(buf, []byte("\ufeff"))))
+	in.Comma = opts.comma()
+	in.FieldsPerRecord = -1
+	header, err := in.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("missing header row")
+	}
+	if err != nil {
+		return nil, err
+	}
+	cols := []*csvColumn{}
+	for _, name := range header {
+		if strings.TrimSpace(name) == "" {
+			cols = append(cols, nil)
+			continue
+		}
+		col, err := csvColumnFor(name)
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, col)
+	}
+	r := &RSS2{Version: "2.0"}
+	for n := 2; ; n++ {
+		row, err := in.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(row) > len(cols) {
+			return nil, fmt.Errorf("row %d has %d columns, the header has %d", n, len(row), len(cols))
+		}
+		item := Item{}
+		for i, value := range row {
+			if cols[i] != nil && value != "" {
+				cols[i].set(&item, value, opts.delimiter())
+			}
+		}
+		r.ItemList = append(r.ItemList, item)
+	}
+	return r, nil
+}
@@ -0,0 +1,130 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	r, err := Parse(pathTestSrc)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	buf := new(bytes.Buffer)
+	if err := r.WriteCSV(buf, []string{".item[].title", ".item[].category", ".item[].description", ".item[].media:content.url"}, nil); err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	expected := `title,category,description,media:content.url
+New chemistry database,Chemistry;Databases,,https://library.example.edu/news/1.png
+Holiday hours,Hours,Closed on Monday,
+`
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+
+	// quoting and tab separated values without a header
+	r.ItemList[1].Title = `Holiday "hours", updated`
+	buf.Reset()
+	if err := r.WriteCSV(buf, []string{".item[].guid", ".item[].title"}, &CSVOptions{Comma: '\t', NoHeader: true}); err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	expected = "news-1\tNew chemistry database\nnews-2\t\"Holiday \"\"hours\"\", updated\"\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+
+	src, err := r.ToCSV()
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if lines := strings.Split(strings.TrimSpace(string(src)), "\n"); len(lines) != 3 || lines[0] != "title,link,description,author,category,guid,pubDate" {
+		t.Errorf("unexpected default columns %q", src)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	src := []byte(`title,link,category,pubDate,enclosure.url,enclosure.type,dc:creator,media:content.url,,.item[].guid
+First post,https://example.edu/1,"News; Chemistry",2020-03-01,https://example.edu/1.mp3,audio/mpeg,Jane Doe,https://example.edu/1.png,ignored,post-1
+"Second, with ""quotes""",https://example.edu/2,,,,,,,,post-2
+`)
+	r, err := ParseCSV(src, nil)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if len(r.ItemList) != 2 {
+		t.Errorf("expected 2 items, got %d", len(r.ItemList))
+		t.FailNow()
+	}
+	first, second := r.ItemList[0], r.ItemList[1]
+	if first.Title != "First post" || first.Link != "https://example.edu/1" || first.GUID != "post-1" || first.PubDate != "2020-03-01" {
+		t.Errorf("unexpected first item %+v", first)
+	}
+	if strings.Join(first.Category, "|") != "News|Chemistry" {
+		t.Errorf("expected two categories, got %q", first.Category)
+	}
+	if first.Enclosure == nil || first.Enclosure.URL != "https://example.edu/1.mp3" || first.Enclosure.Type != "audio/mpeg" {
+		t.Errorf("unexpected enclosure %+v", first.Enclosure)
+	}
+	if len(first.Extensions) != 2 || first.Extensions[0].Value != "Jane Doe" || first.Extensions[1].Attrs[0].Value != "https://example.edu/1.png" {
+		t.Errorf("unexpected extensions %+v", first.Extensions)
+	}
+	if second.Title != `Second, with "quotes"` || second.Enclosure != nil || second.Category != nil || len(second.Extensions) != 0 {
+		t.Errorf("unexpected second item %+v", second)
+	}
+
+	// The result round trips through XML and back to the same CSV
+	r.Title, r.Link, r.Description = "Test", "https://example.edu", "Imported items"
+	xmlSrc, err := r.ToXML()
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	r2, err := Parse(xmlSrc)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	paths := []string{".item[].title", ".item[].category", ".item[].dc:creator", ".item[].media:content.url"}
+	a, b := new(bytes.Buffer), new(bytes.Buffer)
+	r.WriteCSV(a, paths, nil)
+	r2.WriteCSV(b, paths, nil)
+	if a.String() != b.String() {
+		t.Errorf("expected\n%s\ngot\n%s", a.String(), b.String())
+	}
+
+	tsv := []byte("title\tcategory\nOne\ta|b\n")
+	r, err = ParseCSV(tsv, &CSVOptions{Comma: '\t', Delimiter: "|"})
+	if err != nil || len(r.ItemList) != 1 || len(r.ItemList[0].Category) != 2 {
+		t.Errorf("unexpected TSV result %+v, %v", r, err)
+	}
+
+	for _, bad := range []string{"", "title,nosuchfield\n", "title,xx:foo\n", "enclosure.size\n", "title\na,b\n", "extensions\n"} {
+		if _, err := ParseCSV([]byte(bad), nil); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
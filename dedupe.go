@@ -0,0 +1,245 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// DedupeKey names a way of telling two items are the same story
+type DedupeKey string
+
+const (
+	// DedupeGUID matches items with the same guid
+	DedupeGUID DedupeKey = "guid"
+	// DedupeLink matches items whose links are the same once
+	// normalized, see normalizeLink
+	DedupeLink DedupeKey = "link"
+	// DedupeTitleDate matches items with the same title, ignoring
+	// case and spacing, published at the same instant
+	DedupeTitleDate DedupeKey = "title-date"
+	// DedupeContent matches items with the same text in their
+	// content:encoded or description, ignoring markup and spacing
+	DedupeContent DedupeKey = "content"
+)
+
+// DedupeKeep names which of a set of duplicates is kept
+type DedupeKeep string
+
+const (
+	// KeepFirst keeps the duplicate seen first
+	KeepFirst DedupeKeep = "first"
+	// KeepNewest keeps the duplicate with the latest pubDate, an
+	// undated item loses to a dated one
+	KeepNewest DedupeKeep = "newest"
+	// KeepLongest keeps the duplicate with the most text, less
+	// markup, in its content:encoded or description
+	KeepLongest DedupeKeep = "longest"
+)
+
+// DedupeOptions control how Dedupe identifies duplicate items
+type DedupeOptions struct {
+	// Keys are the identities compared, two items are duplicates
+	// when any of them match. Items without a value for a key,
+	// e.g. no guid, never match on it.
+	Keys []DedupeKey
+	// Keep says which duplicate is kept, KeepFirst if empty
+	Keep DedupeKeep
+}
+
+// DefaultDedupeOptions matches items by guid or link and keeps the
+// first one seen
+var DefaultDedupeOptions = DedupeOptions{
+	Keys: []DedupeKey{DedupeGUID, DedupeLink},
+	Keep: KeepFirst,
+}
+
+// ParseDedupeOptions returns DedupeOptions for a comma separated
+// list of keys, e.g. "guid,link", and a keep policy, e.g. "newest".
+// Empty values use DefaultDedupeOptions' keys or policy.
+func ParseDedupeOptions(keys string, keep string) (*DedupeOptions, error) {
+	opts := &DedupeOptions{
+		Keys: DefaultDedupeOptions.Keys,
+		Keep: DefaultDedupeOptions.Keep,
+	}
+	if strings.TrimSpace(keys) != "" {
+		opts.Keys = []DedupeKey{}
+		for _, s := range strings.Split(keys, ",") {
+			key := DedupeKey(strings.ToLower(strings.TrimSpace(s)))
+			switch key {
+			case DedupeGUID, DedupeLink, DedupeTitleDate, DedupeContent:
+				opts.Keys = append(opts.Keys, key)
+			default:
+				return nil, fmt.Errorf("unknown dedupe key %q, expected guid, link, title-date or content", s)
+			}
+		}
+	}
+	if strings.TrimSpace(keep) != "" {
+		opts.Keep = DedupeKeep(strings.ToLower(strings.TrimSpace(keep)))
+		switch opts.Keep {
+		case KeepFirst, KeepNewest, KeepLongest:
+		default:
+			return nil, fmt.Errorf("unknown keep policy %q, expected first, newest or longest", keep)
+		}
+	}
+	return opts, nil
+}
+
+// normalizeLink returns link with the scheme and host lower case,
+// the default port, trailing slash and fragment removed and the
+// query parameters, less tracking parameters, sorted. Links that
+// aren't absolute URLs are returned trimmed.
+func normalizeLink(link string) string {
+	link = strings.TrimSpace(link)
+	u, err := url.Parse(link)
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return link
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if u.Scheme == "http" {
+		u.Host = strings.TrimSuffix(u.Host, ":80")
+	} else if u.Scheme == "https" {
+		u.Host = strings.TrimSuffix(u.Host, ":443")
+	}
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = strings.TrimRight(u.RawPath, "/")
+	u.Fragment, u.RawFragment = "", ""
+	params := []string{}
+	for _, param := range strings.Split(u.RawQuery, "&") {
+		name := param
+		if i := strings.IndexByte(param, '='); i >= 0 {
+			name = param[0:i]
+		}
+		if name == "" || trackingParams[name] || strings.HasPrefix(name, "utm_") {
+			continue
+		}
+		params = append(params, param)
+	}
+	sort.Strings(params)
+	u.RawQuery = strings.Join(params, "&")
+	return u.String()
+}
+
+// itemText returns the text of the longer of an item's
+// content:encoded and description with the spacing collapsed
+func itemText(item *Item) string {
+	content := strings.Join(strings.Fields(htmlText(item.Content)), " ")
+	description := strings.Join(strings.Fields(htmlText(item.Description)), " ")
+	if len(content) > len(description) {
+		return content
+	}
+	return description
+}
+
+// dedupeKey returns the value of key for item, "" if the item has
+// none or the key is unknown
+func dedupeKey(key DedupeKey, item *Item) string {
+	switch key {
+	case DedupeGUID:
+		return strings.TrimSpace(item.GUID)
+	case DedupeLink:
+		return normalizeLink(item.Link)
+	case DedupeTitleDate:
+		title := strings.ToLower(strings.Join(strings.Fields(item.Title), " "))
+		date := strings.TrimSpace(item.PubDate)
+		if title == "" || date == "" {
+			return ""
+		}
+		if t, err := parseDate(date); err == nil {
+			date = t.UTC().Format("2006-01-02T15:04:05Z")
+		}
+		return title + "\n" + date
+	case DedupeContent:
+		text := itemText(item)
+		if text == "" {
+			return ""
+		}
+		return fmt.Sprintf("%x", sha256.Sum256([]byte(text)))
+	}
+	return ""
+}
+
+// replaces reports if item should be kept instead of the duplicate
+// already kept
+func (opts *DedupeOptions) replaces(item *Item, kept *Item) bool {
+	switch opts.Keep {
+	case KeepNewest:
+		t, err := parseDate(item.PubDate)
+		if err != nil {
+			return false
+		}
+		k, err := parseDate(kept.PubDate)
+		return err != nil || t.After(k)
+	case KeepLongest:
+		return len(itemText(item)) > len(itemText(kept))
+	}
+	return false
+}
+
+// DedupeItems returns items with duplicates left out. The item kept
+// from a set of duplicates takes the place of the first one seen.
+// When opts is nil DefaultDedupeOptions are used.
+func DedupeItems(items []Item, opts *DedupeOptions) []Item {
+	if opts == nil {
+		opts = &DefaultDedupeOptions
+	}
+	kept := []Item{}
+	// seen maps a key's name and value to the index in kept
+	seen := map[string]int{}
+	for _, item := range items {
+		values := []string{}
+		at := -1
+		for _, key := range opts.Keys {
+			value := dedupeKey(key, &item)
+			if value == "" {
+				continue
+			}
+			value = string(key) + "\n" + value
+			values = append(values, value)
+			if i, ok := seen[value]; ok && at < 0 {
+				at = i
+			}
+		}
+		if at < 0 {
+			at = len(kept)
+			kept = append(kept, item)
+		} else if opts.replaces(&item, &kept[at]) {
+			kept[at] = item
+		}
+		for _, value := range values {
+			if _, ok := seen[value]; !ok {
+				seen[value] = at
+			}
+		}
+	}
+	return kept
+}
+
+// Dedupe removes duplicate items from the feed, see DedupeItems,
+// and returns the number removed
+func (r *RSS2) Dedupe(opts *DedupeOptions) int {
+	n := len(r.ItemList)
+	r.ItemList = DedupeItems(r.ItemList, opts)
+	return n - len(r.ItemList)
+}
@@ -0,0 +1,92 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeLink(t *testing.T) {
+	for link, expected := range map[string]string{
+		"HTTPS://Library.Example.EDU:443/news/1/":                         "https://library.example.edu/news/1",
+		"http://library.example.edu:80/news/1?b=2&a=1#comments":           "http://library.example.edu/news/1?a=1&b=2",
+		"https://library.example.edu/news/1?utm_source=rss&id=4&fbclid=x": "https://library.example.edu/news/1?id=4",
+		"https://library.example.edu/":                                    "https://library.example.edu",
+		" /news/1 ":                                                       "/news/1",
+	} {
+		if result := normalizeLink(link); result != expected {
+			t.Errorf("%q: expected %q, got %q", link, expected, result)
+		}
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	r, err := Parse([]byte(`<rss version="2.0"><channel><title>Library News</title><link>https://library.example.edu</link><description>News</description>
+<item><title>New chemistry database</title><link>https://library.example.edu/news/1</link><guid>news-1</guid><pubDate>Mon, 25 Jul 2016 09:00:00 -0700</pubDate><description>Short</description></item>
+<item><title>New Chemistry  Database</title><link>HTTPS://library.example.edu/news/1/?utm_medium=rss</link><pubDate>Mon, 25 Jul 2016 16:00:00 +0000</pubDate><description>A much longer description</description></item>
+<item><title>Holiday hours</title><link>https://library.example.edu/news/2</link><guid>news-1</guid><pubDate>Tue, 26 Jul 2016 09:00:00 -0700</pubDate><description>Closed on Monday</description></item>
+<item><title>Hours</title><link>https://library.example.edu/news/3</link><description><![CDATA[<p>Closed   on <b>Monday</b></p>]]></description></item>
+<item><title>Reading group</title><link>https://library.example.edu/news/4</link></item>
+</channel></rss>`))
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	titles := func(items []Item) string {
+		l := []string{}
+		for _, item := range items {
+			l = append(l, item.Title)
+		}
+		return strings.Join(l, "|")
+	}
+	for _, test := range []struct {
+		keys, keep, expected string
+	}{
+		{"", "", "New chemistry database|Hours|Reading group"},
+		{"guid", "", "New chemistry database|New Chemistry  Database|Hours|Reading group"},
+		{"link", "longest", "New Chemistry  Database|Holiday hours|Hours|Reading group"},
+		{"guid,link", "newest", "Holiday hours|Hours|Reading group"},
+		{"title-date", "", "New chemistry database|Holiday hours|Hours|Reading group"},
+		{"content", "", "New chemistry database|New Chemistry  Database|Holiday hours|Reading group"},
+		{"link,content", "longest", "New Chemistry  Database|Holiday hours|Reading group"},
+	} {
+		opts, err := ParseDedupeOptions(test.keys, test.keep)
+		if err != nil {
+			t.Errorf("%s", err)
+			t.FailNow()
+		}
+		if result := titles(DedupeItems(r.ItemList, opts)); result != test.expected {
+			t.Errorf("%q %q: expected %q, got %q", test.keys, test.keep, test.expected, result)
+		}
+	}
+	if _, err := ParseDedupeOptions("guid,isbn", ""); err == nil {
+		t.Errorf("expected an error for an unknown key")
+	}
+	if _, err := ParseDedupeOptions("", "oldest"); err == nil {
+		t.Errorf("expected an error for an unknown keep policy")
+	}
+
+	if n := r.Dedupe(nil); n != 2 || len(r.ItemList) != 3 {
+		t.Errorf("expected 2 of 5 items removed, got %d leaving %d", n, len(r.ItemList))
+	}
+	if n := r.Dedupe(nil); n != 0 {
+		t.Errorf("expected no more duplicates, got %d", n)
+	}
+}
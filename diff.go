@@ -0,0 +1,257 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Change is a field whose value differs between two versions of a
+// feed. Values are text, lists are joined by ", " and extensions and
+// attributes are compared as JSON.
+type Change struct {
+	// Field is the field's name relative to the channel or item,
+	// e.g. "pubDate" or "enclosure.url"
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// ItemDiff lists the changes to an item found in both versions
+type ItemDiff struct {
+	// Key is the guid or link the versions were matched by
+	Key string `json:"key"`
+	// Title is the title of the new version
+	Title   string   `json:"title,omitempty"`
+	Changes []Change `json:"changes"`
+}
+
+// FeedDiff is the difference between two versions of a feed
+type FeedDiff struct {
+	Channel  []Change   `json:"channel,omitempty"`
+	Added    []Item     `json:"added,omitempty"`
+	Removed  []Item     `json:"removed,omitempty"`
+	Modified []ItemDiff `json:"modified,omitempty"`
+}
+
+var itemListType = reflect.TypeOf([]Item{})
+
+// diffValue returns v as text for comparison
+func diffValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String())
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.String {
+			vals := []string{}
+			for i := 0; i < v.Len(); i++ {
+				vals = append(vals, strings.TrimSpace(v.Index(i).String()))
+			}
+			return strings.Join(vals, ", ")
+		}
+		if v.Len() == 0 {
+			return ""
+		}
+	}
+	src, _ := json.Marshal(v.Interface())
+	return string(src)
+}
+
+// diffFields compares the exported fields of the structs old and
+// new, pointers to structs are compared field by field with a nil
+// pointer standing for the empty struct. Field names are the JSON
+// names prefixed with prefix. Only channel fields are compared when
+// channelOnly is true.
+func diffFields(prefix string, old reflect.Value, new reflect.Value, channelOnly bool) []Change {
+	changes := []Change{}
+	t := old.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Name == "XMLName" || f.PkgPath != "" || f.Type == itemListType {
+			continue
+		}
+		if channelOnly && !isChannelField(f) {
+			continue
+		}
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "" || name == "-" {
+			name = f.Name
+		}
+		o, n := old.Field(i), new.Field(i)
+		if f.Type.Kind() == reflect.Ptr && f.Type.Elem().Kind() == reflect.Struct {
+			if o.IsNil() && n.IsNil() {
+				continue
+			}
+			zero := reflect.New(f.Type.Elem())
+			if o.IsNil() {
+				o = zero
+			}
+			if n.IsNil() {
+				n = zero
+			}
+			changes = append(changes, diffFields(prefix+name+".", o.Elem(), n.Elem(), false)...)
+			continue
+		}
+		if ov, nv := diffValue(o), diffValue(n); ov != nv {
+			changes = append(changes, Change{Field: prefix + name, Old: ov, New: nv})
+		}
+	}
+	return changes
+}
+
+// itemKeys returns the guid and the normalized link of an item
+func itemKeys(item *Item) (string, string) {
+	guid := strings.TrimSpace(item.GUID)
+	link := ""
+	if item.Link != "" {
+		link = normalizeLink(item.Link)
+	}
+	return guid, link
+}
+
+// Diff compares two versions of a feed. Items are matched by guid,
+// or else by link (see DedupeLink). Items only in newFeed are added,
+// those only in oldFeed are removed and items in both whose fields
+// differ are modified. Either feed may be nil.
+func Diff(oldFeed *RSS2, newFeed *RSS2) *FeedDiff {
+	if oldFeed == nil {
+		oldFeed = new(RSS2)
+	}
+	if newFeed == nil {
+		newFeed = new(RSS2)
+	}
+	d := new(FeedDiff)
+	d.Channel = diffFields("", reflect.ValueOf(oldFeed).Elem(), reflect.ValueOf(newFeed).Elem(), true)
+
+	guids, links := map[string]int{}, map[string]int{}
+	for i := range oldFeed.ItemList {
+		guid, link := itemKeys(&oldFeed.ItemList[i])
+		if _, ok := guids[guid]; guid != "" && !ok {
+			guids[guid] = i
+		}
+		if _, ok := links[link]; link != "" && !ok {
+			links[link] = i
+		}
+	}
+	matched := make([]bool, len(oldFeed.ItemList))
+	for _, item := range newFeed.ItemList {
+		guid, link := itemKeys(&item)
+		i, key := -1, ""
+		if k, ok := guids[guid]; guid != "" && ok && !matched[k] {
+			i, key = k, guid
+		} else if k, ok := links[link]; link != "" && ok && !matched[k] {
+			i, key = k, strings.TrimSpace(item.Link)
+		}
+		if i < 0 {
+			d.Added = append(d.Added, item)
+			continue
+		}
+		matched[i] = true
+		changes := diffFields("", reflect.ValueOf(oldFeed.ItemList[i]), reflect.ValueOf(item), false)
+		if len(changes) > 0 {
+			d.Modified = append(d.Modified, ItemDiff{
+				Key:     key,
+				Title:   strings.TrimSpace(item.Title),
+				Changes: changes,
+			})
+		}
+	}
+	for i, item := range oldFeed.ItemList {
+		if !matched[i] {
+			d.Removed = append(d.Removed, item)
+		}
+	}
+	return d
+}
+
+// Empty reports if the two versions are the same
+func (d *FeedDiff) Empty() bool {
+	return len(d.Channel) == 0 && len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// itemLabel names an item in a report by its title, guid or link
+func itemLabel(item *Item) string {
+	label := strings.TrimSpace(item.Title)
+	if label == "" {
+		label = strings.TrimSpace(item.GUID)
+	}
+	if link := strings.TrimSpace(item.Link); link != "" && link != label {
+		label = strings.TrimSpace(label + " <" + link + ">")
+	}
+	return label
+}
+
+// diffExcerpts shortens two long values for a report so the point
+// where they start to differ is shown
+func diffExcerpts(o string, n string) (string, string) {
+	or, nr := []rune(o), []rune(n)
+	i := 0
+	for i < len(or) && i < len(nr) && or[i] == nr[i] {
+		i++
+	}
+	if i > 20 {
+		// start at a word near the difference
+		start := i - 20
+		for j := start; j < i; j++ {
+			if or[j-1] == ' ' {
+				start = j
+				break
+			}
+		}
+		o, n = "…"+string(or[start:]), "…"+string(nr[start:])
+	}
+	return Truncate(60, o), Truncate(60, n)
+}
+
+// String returns the differences as a report, one line per channel
+// change, added or removed item and modified item followed by its
+// changes indented. Long values are shortened to where they differ.
+func (d *FeedDiff) String() string {
+	lines := []string{}
+	change := func(indent string, c Change) {
+		o, n := diffExcerpts(c.Old, c.New)
+		lines = append(lines, fmt.Sprintf("%s%s: %q -> %q", indent, c.Field, o, n))
+	}
+	for _, c := range d.Channel {
+		change("channel ", c)
+	}
+	for i := range d.Added {
+		lines = append(lines, "added "+itemLabel(&d.Added[i]))
+	}
+	for i := range d.Removed {
+		lines = append(lines, "removed "+itemLabel(&d.Removed[i]))
+	}
+	for _, m := range d.Modified {
+		label := m.Key
+		if m.Title != "" && m.Title != m.Key {
+			label = m.Title + " <" + m.Key + ">"
+		}
+		lines = append(lines, "modified "+label)
+		for _, c := range m.Changes {
+			change("    ", c)
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
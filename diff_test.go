@@ -0,0 +1,105 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	old, err := Parse(pathTestSrc)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	d := Diff(old, old)
+	if !d.Empty() || d.String() != "" {
+		t.Errorf("expected no differences, got %q", d.String())
+	}
+
+	updated, err := Parse(pathTestSrc)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	updated.Title = "Library News and Events"
+	updated.Image = &Image{URL: "https://library.example.edu/logo.png"}
+	updated.ItemList[0].Enclosure.Length = "2048"
+	updated.ItemList[0].Category = []string{"databases", "chemistry"}
+	updated.ItemList[1].Description = "Closed on Monday, Tuesday and Wednesday for " + strings.Repeat("the holiday ", 10)
+	updated.ItemList = append(updated.ItemList, Item{Title: "Reading group", Link: "https://library.example.edu/news/3"})
+	// a missing guid is matched by link
+	moved := old.ItemList[0]
+	moved.GUID = ""
+	moved.Link = "HTTPS://library.example.edu/news/1/"
+	old.ItemList = append(old.ItemList, Item{Title: "Gone", GUID: "news-0"})
+
+	d = Diff(old, updated)
+	if d.Empty() {
+		t.Errorf("expected differences")
+	}
+	if len(d.Channel) != 2 || d.Channel[0] != (Change{"title", "Library News", "Library News and Events"}) || d.Channel[1] != (Change{"image.url", "", "https://library.example.edu/logo.png"}) {
+		t.Errorf("unexpected channel changes %+v", d.Channel)
+	}
+	if len(d.Added) != 1 || d.Added[0].Title != "Reading group" || len(d.Removed) != 1 || d.Removed[0].Title != "Gone" {
+		t.Errorf("unexpected added %+v or removed %+v", d.Added, d.Removed)
+	}
+	if len(d.Modified) != 2 || d.Modified[0].Key != "news-1" || d.Modified[1].Title != "Holiday hours" {
+		t.Errorf("unexpected modified %+v", d.Modified)
+		t.FailNow()
+	}
+	changes := d.Modified[0].Changes
+	if len(changes) != 2 || changes[0].Field != "category" || changes[0].New != "databases, chemistry" || changes[1] != (Change{"enclosure.length", "1024", "2048"}) {
+		t.Errorf("unexpected item changes %+v", changes)
+	}
+
+	report := d.String()
+	for _, expected := range []string{
+		"channel title: \"Library News\" -> \"Library News and Events\"\n",
+		"added Reading group <https://library.example.edu/news/3>\n",
+		"removed Gone\n",
+		"modified Holiday hours <news-2>\n    description: \"Closed on Monday\" -> \"Closed on Monday, Tuesday and Wednesday for the holiday the…\"\n",
+	} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("expected %q in\n%s", expected, report)
+		}
+	}
+	// long values are shown from near where they differ
+	o, n := diffExcerpts(strings.Repeat("Closed ", 10)+"on Monday", strings.Repeat("Closed ", 10)+"on Tuesday")
+	if o != "…Closed Closed on Monday" || n != "…Closed Closed on Tuesday" {
+		t.Errorf("unexpected excerpts %q %q", o, n)
+	}
+	if o, n = diffExcerpts("https://library.example.edu/news/1", "https://library.example.edu/news/1/"); o != "…y.example.edu/news/1" || n != "…y.example.edu/news/1/" {
+		t.Errorf("unexpected excerpts %q %q", o, n)
+	}
+	if _, err := json.Marshal(d); err != nil {
+		t.Errorf("%s", err)
+	}
+
+	updated.ItemList = []Item{moved}
+	d = Diff(old, updated)
+	if len(d.Modified) != 1 || d.Modified[0].Key != "HTTPS://library.example.edu/news/1/" || d.Modified[0].Changes[0].Field != "link" {
+		t.Errorf("expected the item matched by link, got %+v", d.Modified)
+	}
+	if d = Diff(nil, old); len(d.Added) != 3 || len(d.Channel) == 0 {
+		t.Errorf("expected everything added, got %+v", d)
+	}
+}
@@ -0,0 +1,257 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// Feed formats recognized by ParseAny
+const (
+	FormatRSS2     = "rss2"
+	FormatRSS09x   = "rss0.9x"
+	FormatRSS1     = "rss1"
+	FormatAtom     = "atom"
+	FormatJSONFeed = "jsonfeed"
+)
+
+// Feed is the common view of a parsed feed regardless of the format
+// it was published in. The document is always converted to RSS 2 and
+// that value is available from RSS2().
+type Feed interface {
+	// Format returns the format detected by ParseAny, e.g. FormatAtom
+	Format() string
+	Title() string
+	Link() string
+	Description() string
+	Items() []FeedItem
+	// RSS2 returns the feed as an RSS 2 document
+	RSS2() *RSS2
+}
+
+// FeedItem is the format independent view of an item. Published is
+// the zero time when the item has no parsable date.
+type FeedItem struct {
+	ID         string
+	Title      string
+	Link       string
+	Author     string
+	Summary    string
+	Content    string
+	Categories []string
+	Published  time.Time
+}
+
+// feed implements Feed for any document converted to RSS 2
+type feed struct {
+	format string
+	rss    *RSS2
+}
+
+func (f *feed) Format() string {
+	return f.format
+}
+
+func (f *feed) Title() string {
+	return strings.TrimSpace(f.rss.Title)
+}
+
+func (f *feed) Link() string {
+	return strings.TrimSpace(f.rss.Link)
+}
+
+func (f *feed) Description() string {
+	return strings.TrimSpace(f.rss.Description)
+}
+
+func (f *feed) RSS2() *RSS2 {
+	return f.rss
+}
+
+func (f *feed) Items() []FeedItem {
+	items := []FeedItem{}
+	for _, item := range f.rss.ItemList {
+		fi := FeedItem{
+			ID:         f.rss.itemID(item),
+			Title:      strings.TrimSpace(item.Title),
+			Link:       strings.TrimSpace(item.Link),
+			Author:     strings.TrimSpace(item.Author),
+			Summary:    item.Description,
+			Content:    item.Content,
+			Categories: item.Category,
+		}
+		if fi.Content == "" {
+			fi.Content = item.Description
+		}
+		if t, err := parseDate(item.PubDate); err == nil {
+			fi.Published = t
+		}
+		items = append(items, fi)
+	}
+	return items
+}
+
+// NewFeed wraps an RSS2 value so it can be used as a Feed
+func NewFeed(r *RSS2) Feed {
+	return &feed{format: FormatRSS2, rss: r}
+}
+
+// rdf is an RSS 1.0 (or Netscape RSS 0.90) document
+type rdf struct {
+	XMLName xml.Name `xml:"RDF"`
+	Channel struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+		Language    string `xml:"http://purl.org/dc/elements/1.1/ language"`
+		Rights      string `xml:"http://purl.org/dc/elements/1.1/ rights"`
+		Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+		Publisher   string `xml:"http://purl.org/dc/elements/1.1/ publisher"`
+	} `xml:"channel"`
+	Items []struct {
+		About       string   `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+		Title       string   `xml:"title"`
+		Link        string   `xml:"link"`
+		Description string   `xml:"description"`
+		Content     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+		Date        string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+		Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+		Subject     []string `xml:"http://purl.org/dc/elements/1.1/ subject"`
+	} `xml:"item"`
+}
+
+// parseRDF reads an RSS 1.0 or 0.90 document into an RSS2 structure.
+// Dublin Core dates are rewritten in RFC 1123 form.
+func parseRDF(buf []byte) (*RSS2, error) {
+	doc := new(rdf)
+	if err := xml.Unmarshal(buf, &doc); err != nil {
+		return nil, err
+	}
+	r := new(RSS2)
+	r.Version = "2.0"
+	r.Title = doc.Channel.Title
+	r.Link = doc.Channel.Link
+	r.Description = doc.Channel.Description
+	r.Language = doc.Channel.Language
+	r.Copyright = doc.Channel.Rights
+	if doc.Channel.Date != "" {
+		r.PubDate = rssDate(doc.Channel.Date)
+	}
+	for _, entry := range doc.Items {
+		item := Item{
+			Title:       entry.Title,
+			Link:        entry.Link,
+			GUID:        entry.About,
+			Description: entry.Description,
+			Content:     entry.Content,
+			Author:      entry.Creator,
+			Category:    entry.Subject,
+		}
+		if entry.Date != "" {
+			item.PubDate = rssDate(entry.Date)
+		}
+		r.ItemList = append(r.ItemList, item)
+	}
+	return r, nil
+}
+
+// DetectFormat sniffs the feed format of buf returning one of the
+// Format constants or an error if the document isn't a known feed.
+func DetectFormat(buf []byte) (string, error) {
+	format, _, err := sniff(buf)
+	return format, err
+}
+
+// sniff returns the feed format and the local name of the root
+// element, RSS 0.90 and 0.91 share a format but not a document
+// structure.
+func sniff(buf []byte) (string, string, error) {
+	trimmed := bytes.TrimLeft(buf, " \t\r\n\ufeff")
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		if bytes.Contains(trimmed, []byte("jsonfeed.org/version/")) {
+			return FormatJSONFeed, "", nil
+		}
+		return "", "", fmt.Errorf("JSON document is not a JSON Feed")
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(buf))
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", "", fmt.Errorf("can't find a feed root element, %s", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "rss":
+			for _, attr := range start.Attr {
+				if attr.Name.Local == "version" && strings.HasPrefix(attr.Value, "0.9") {
+					return FormatRSS09x, start.Name.Local, nil
+				}
+			}
+			return FormatRSS2, start.Name.Local, nil
+		case "RDF":
+			if bytes.Contains(buf, []byte("my.netscape.com/rdf/simple/0.9/")) {
+				return FormatRSS09x, start.Name.Local, nil
+			}
+			return FormatRSS1, start.Name.Local, nil
+		case "feed":
+			if start.Name.Space == AtomNS {
+				return FormatAtom, start.Name.Local, nil
+			}
+		}
+		return "", "", fmt.Errorf("unknown feed root element <%s>", start.Name.Local)
+	}
+}
+
+// ParseAny reads RSS 0.9x, 1.0, 2.0, Atom 1.0 or JSON Feed documents
+// returning a Feed. The format is detected from the content.
+func ParseAny(in io.Reader) (Feed, error) {
+	buf, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	format, root, err := sniff(buf)
+	if err != nil {
+		return nil, err
+	}
+	var r *RSS2
+	switch {
+	case format == FormatJSONFeed:
+		r, err = ParseJSONFeed(buf)
+	case format == FormatAtom:
+		r, err = ParseAtom(buf)
+	case root == "RDF":
+		r, err = parseRDF(buf)
+	default:
+		r, err = Parse(buf)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &feed{format: format, rss: r}, nil
+}
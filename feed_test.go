@@ -0,0 +1,149 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestParseAny(t *testing.T) {
+	fp, err := os.Open(path.Join("testdata", "rsdoiel.xml"))
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	defer fp.Close()
+	f, err := ParseAny(fp)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if f.Format() != FormatRSS2 {
+		t.Errorf("expected %q, got %q", FormatRSS2, f.Format())
+	}
+	if f.Title() != "R. S. Doiel" || f.Link() != "http://rsdoiel.github.io/blog" {
+		t.Errorf("unexpected title or link, %q %q", f.Title(), f.Link())
+	}
+	items := f.Items()
+	if len(items) != len(f.RSS2().ItemList) {
+		t.Errorf("expected %d items, got %d", len(f.RSS2().ItemList), len(items))
+		t.FailNow()
+	}
+	if items[0].ID != items[0].Link || items[0].Published.IsZero() {
+		t.Errorf("expected id and date for first item, got %+v", items[0])
+	}
+
+	tests := map[string]string{
+		FormatAtom: `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Example Feed</title>
+  <link href="http://example.org/"/>
+  <updated>2003-12-13T18:30:02Z</updated>
+  <author><name>John Doe</name></author>
+  <id>urn:uuid:60a76c80-d399-11d9-b93c-0003939e0af6</id>
+  <entry>
+    <title>Atom-Powered Robots Run Amok</title>
+    <link href="http://example.org/2003/12/13/atom03"/>
+    <link rel="enclosure" type="audio/mpeg" length="1337" href="http://example.org/audio/ph34r_my_podcast.mp3"/>
+    <id>urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a</id>
+    <updated>2003-12-13T18:30:02Z</updated>
+    <summary>Some text.</summary>
+    <content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Some <b>text</b>.</p></div></content>
+  </entry>
+</feed>`,
+		FormatRSS1: `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel rdf:about="http://www.xml.com/xml/news.rss">
+    <title>XML.com</title>
+    <link>http://xml.com/pub</link>
+    <description>XML.com features a rich mix of information and services</description>
+  </channel>
+  <item rdf:about="http://xml.com/pub/2000/08/09/xslt/xslt.html">
+    <title>Processing Inclusions with XSLT</title>
+    <link>http://xml.com/pub/2000/08/09/xslt/xslt.html</link>
+    <description>Processing document inclusions with general XML tools</description>
+    <dc:date>2000-08-09T12:00:00-05:00</dc:date>
+  </item>
+</rdf:RDF>`,
+		FormatRSS09x: `<?xml version="1.0"?>
+<rss version="0.91">
+  <channel>
+    <title>WriteTheWeb</title>
+    <link>http://writetheweb.com</link>
+    <description>News for web users that write back</description>
+    <item>
+      <title>Giving the world a pluggable Gnutella</title>
+      <link>http://writetheweb.com/read.php?item=24</link>
+      <description>WorldOS is a framework on which to build programs</description>
+    </item>
+  </channel>
+</rss>`,
+		FormatJSONFeed: `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "JSON Feed",
+  "home_page_url": "https://example.org/",
+  "items": [{"id": "1", "url": "https://example.org/1", "content_text": "Hello", "date_published": "2020-01-02T03:04:05Z"}]
+}`,
+	}
+	for format, src := range tests {
+		f, err := ParseAny(strings.NewReader(src))
+		if err != nil {
+			t.Errorf("%s: %s", format, err)
+			continue
+		}
+		if f.Format() != format {
+			t.Errorf("expected format %q, got %q", format, f.Format())
+		}
+		if f.Title() == "" || f.Link() == "" {
+			t.Errorf("%s: expected title and link, got %q %q", format, f.Title(), f.Link())
+		}
+		items := f.Items()
+		if len(items) != 1 {
+			t.Errorf("%s: expected 1 item, got %d", format, len(items))
+			continue
+		}
+		if items[0].ID == "" || items[0].Link == "" || items[0].Content == "" {
+			t.Errorf("%s: expected id, link and content, got %+v", format, items[0])
+		}
+		if format != FormatRSS09x && items[0].Published.IsZero() {
+			t.Errorf("%s: expected a published date", format)
+		}
+	}
+
+	f, err = ParseAny(strings.NewReader(tests[FormatAtom]))
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	item := f.RSS2().ItemList[0]
+	if item.Content != `<p>Some <b>text</b>.</p>` {
+		t.Errorf("expected xhtml content as markup, got %q", item.Content)
+	}
+	if item.Enclosure == nil || item.Enclosure.Length != "1337" {
+		t.Errorf("expected enclosure from atom link, got %+v", item.Enclosure)
+	}
+
+	if _, err := ParseAny(bytes.NewReader([]byte(`<html><body></body></html>`))); err == nil {
+		t.Errorf("expected an error for an HTML document")
+	}
+}
@@ -0,0 +1,359 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GenerateOptions describe the channel Generate writes and how items
+// are derived from Markdown documents.
+type GenerateOptions struct {
+	// Title, Link and Description describe the channel
+	Title       string
+	Link        string
+	Description string
+	// BaseURL is joined with each document's path relative to the
+	// directory, ".md" becoming ".html", to form the item link.
+	// Link is used when BaseURL is empty.
+	BaseURL string
+	// Author is used for documents without an author
+	Author string
+	// Excerpt is the length of the description rendered from the
+	// document when the front matter has none, 0 uses 280 and a
+	// negative value leaves it out
+	Excerpt int
+	// Limit is the maximum number of items, 0 for all of them
+	Limit int
+}
+
+// frontMatterKeys are the names accepted for each item field, the
+// first present wins
+var frontMatterKeys = map[string][]string{
+	"title":       {"title"},
+	"date":        {"date", "pubdate", "published", "created"},
+	"author":      {"author", "authors", "byline", "creator"},
+	"category":    {"categories", "category", "tags", "keywords"},
+	"description": {"description", "summary", "abstract"},
+	"link":        {"link", "url", "permalink"},
+	"guid":        {"guid", "id"},
+}
+
+// ParseFrontMatter splits a Markdown document into its front matter
+// and body. Front matter is YAML between "---" lines, TOML between
+// "+++" lines or a JSON object at the start of the document. A
+// document without front matter returns an empty map and src.
+func ParseFrontMatter(src []byte) (map[string]interface{}, []byte, error) {
+	meta := map[string]interface{}{}
+	text := strings.TrimPrefix(strings.Replace(string(src), "\r\n", "\n", -1), "\ufeff")
+	if strings.HasPrefix(text, "{") {
+		decoder := json.NewDecoder(strings.NewReader(text))
+		if err := decoder.Decode(&meta); err != nil {
+			return nil, nil, fmt.Errorf("json front matter, %s", err)
+		}
+		rest := text[decoder.InputOffset():]
+		return meta, []byte(strings.TrimLeft(rest, "\n")), nil
+	}
+	var (
+		fence string
+		read  func([]byte) (*node, error)
+	)
+	switch {
+	case strings.HasPrefix(text, "---\n"):
+		fence, read = "---", yamlTree
+	case strings.HasPrefix(text, "+++\n"):
+		fence, read = "+++", tomlTree
+	default:
+		return meta, src, nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimRight(lines[i], " \t\n") != fence {
+			continue
+		}
+		n, err := read([]byte(strings.Join(lines[1:i], "")))
+		if err != nil {
+			return nil, nil, fmt.Errorf("front matter, %s", err)
+		}
+		if n.kind == mapNode {
+			buf := new(bytes.Buffer)
+			n.writeJSON(buf)
+			if err := json.Unmarshal(buf.Bytes(), &meta); err != nil {
+				return nil, nil, err
+			}
+		} else if n.kind != nullNode {
+			return nil, nil, fmt.Errorf("front matter must be a mapping")
+		}
+		return meta, []byte(strings.Join(lines[i+1:], "")), nil
+	}
+	return nil, nil, fmt.Errorf("front matter is missing its closing %q", fence)
+}
+
+// frontMatter returns the value of field from meta as strings, lists
+// are flattened and a comma separated category string is split
+func frontMatter(meta map[string]interface{}, field string) []string {
+	for _, key := range frontMatterKeys[field] {
+		for k, v := range meta {
+			if !strings.EqualFold(k, key) {
+				continue
+			}
+			vals := []string{}
+			switch v := v.(type) {
+			case []interface{}:
+				for _, item := range v {
+					if s := strings.TrimSpace(fmt.Sprintf("%v", item)); item != nil && s != "" {
+						vals = append(vals, s)
+					}
+				}
+			case map[string]interface{}:
+				// e.g. author: {name: ..., email: ...}
+				if name, ok := v["name"]; ok {
+					vals = append(vals, fmt.Sprintf("%v", name))
+				}
+			case nil:
+			default:
+				s := strings.TrimSpace(fmt.Sprintf("%v", v))
+				if field == "category" {
+					for _, c := range strings.Split(s, ",") {
+						if c = strings.TrimSpace(c); c != "" {
+							vals = append(vals, c)
+						}
+					}
+				} else if s != "" {
+					vals = append(vals, s)
+				}
+			}
+			return vals
+		}
+	}
+	return nil
+}
+
+var (
+	// pathDate finds a date in a document path such as
+	// 2016/05/28/title.md or 2016-05-28-title.md
+	pathDate = regexp.MustCompile(`(^|/)(\d{4})[/-](\d{2})[/-](\d{2})([/-]|$)`)
+
+	mdFence     = regexp.MustCompile("^\\s*(```|~~~)")
+	mdRule      = regexp.MustCompile(`^\s*([-*_]\s*){3,}$`)
+	mdLinkDef   = regexp.MustCompile(`^\s*\[[^\]]+\]:\s`)
+	mdBlockMark = regexp.MustCompile(`^\s*(#{1,6}\s+|>\s?|[-*+]\s+|\d+[.)]\s+)+`)
+	mdImage     = regexp.MustCompile(`!\[([^\]]*)\](\([^)]*\)|\[[^\]]*\])`)
+	mdInline    = regexp.MustCompile(`\[([^\]]*)\](\([^)]*\)|\[[^\]]*\])`)
+	mdEmphasis  = regexp.MustCompile("(\\*{1,3}|\\b_{1,3}|_{1,3}\\b|`+|~~)")
+	mdHeading   = regexp.MustCompile(`^#\s+(.+?)\s*#*\s*$`)
+)
+
+// markdownTitle returns the text of the first level one heading
+func markdownTitle(src string) string {
+	for _, line := range strings.Split(src, "\n") {
+		if m := mdHeading.FindStringSubmatch(line); m != nil {
+			return markdownText(m[1])
+		}
+	}
+	return ""
+}
+
+// markdownText renders Markdown as plain text for an excerpt, code
+// blocks, rules and link definitions are left out
+func markdownText(src string) string {
+	var sb strings.Builder
+	inCode := false
+	for _, line := range strings.Split(src, "\n") {
+		if mdFence.MatchString(line) {
+			inCode = !inCode
+			continue
+		}
+		if inCode || mdRule.MatchString(line) || mdLinkDef.MatchString(line) ||
+			strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+		line = mdBlockMark.ReplaceAllString(line, "")
+		line = mdImage.ReplaceAllString(line, "$1")
+		line = mdInline.ReplaceAllString(line, "$1")
+		line = mdEmphasis.ReplaceAllString(line, "")
+		line = strings.Replace(line, `\`, "", -1)
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	return stripHTML(sb.String())
+}
+
+// markdownItem builds an item from a Markdown document's front
+// matter and body, name is its slash separated path relative to the
+// directory
+func markdownItem(name string, meta map[string]interface{}, body []byte, opts *GenerateOptions) (*Item, time.Time, error) {
+	var err error
+	first := func(field string) string {
+		if vals := frontMatter(meta, field); len(vals) > 0 {
+			return vals[0]
+		}
+		return ""
+	}
+	item := new(Item)
+	item.Title = first("title")
+	if item.Title == "" {
+		item.Title = markdownTitle(string(body))
+	}
+	if item.Title == "" {
+		item.Title = strings.TrimSuffix(path.Base(name), path.Ext(name))
+	}
+
+	base := opts.BaseURL
+	if base == "" {
+		base = opts.Link
+	}
+	link := first("link")
+	if link == "" {
+		link = strings.TrimSuffix(name, path.Ext(name)) + ".html"
+	}
+	if !strings.Contains(link, "://") {
+		link = strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(link, "/")
+	}
+	item.Link = link
+	item.GUID = first("guid")
+
+	if authors := frontMatter(meta, "author"); len(authors) > 0 {
+		item.Author = strings.Join(authors, ", ")
+	} else {
+		item.Author = opts.Author
+	}
+	item.Category = frontMatter(meta, "category")
+
+	item.Description = first("description")
+	if item.Description == "" && opts.Excerpt >= 0 {
+		n := opts.Excerpt
+		if n == 0 {
+			n = 280
+		}
+		text := markdownText(string(body))
+		// the heading used as the title isn't part of the excerpt
+		text = strings.TrimSpace(strings.TrimPrefix(text, item.Title))
+		item.Description = Truncate(n, text)
+	}
+
+	var pubDate time.Time
+	if s := first("date"); s != "" {
+		if pubDate, err = parseDate(s); err != nil {
+			return nil, time.Time{}, err
+		}
+	} else if m := pathDate.FindStringSubmatch(name); m != nil {
+		pubDate, _ = time.Parse("2006-01-02", m[2]+"-"+m[3]+"-"+m[4])
+	}
+	if !pubDate.IsZero() {
+		item.PubDate = pubDate.Format(time.RFC1123Z)
+	}
+	return item, pubDate, nil
+}
+
+// Generate builds a feed from the Markdown documents (files ending in
+// ".md" or ".markdown") found walking dir. Item title, date, author,
+// categories, description and link come from each document's front
+// matter, see ParseFrontMatter. Missing titles are taken from the
+// first level one heading, missing dates from a YYYY/MM/DD or
+// YYYY-MM-DD path and missing descriptions from an excerpt of the
+// document. Documents with "draft: true" are skipped. Items are
+// sorted newest first, undated items last.
+func Generate(dir string, opts *GenerateOptions) (*RSS2, error) {
+	if opts == nil {
+		opts = new(GenerateOptions)
+	}
+	type dated struct {
+		item    *Item
+		pubDate time.Time
+	}
+	items := []dated{}
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if p != dir && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(p))
+		if ext != ".md" && ext != ".markdown" {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		src, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		meta, body, err := ParseFrontMatter(src)
+		if err != nil {
+			return fmt.Errorf("%s, %s", p, err)
+		}
+		if draft, ok := meta["draft"]; ok && fmt.Sprintf("%v", draft) == "true" {
+			return nil
+		}
+		item, pubDate, err := markdownItem(filepath.ToSlash(rel), meta, body, opts)
+		if err != nil {
+			return fmt.Errorf("%s, %s", p, err)
+		}
+		items = append(items, dated{item, pubDate})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Walk visits files in lexical order so ties keep a stable order
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[j].pubDate.IsZero() {
+			return !items[i].pubDate.IsZero()
+		}
+		return items[i].pubDate.After(items[j].pubDate)
+	})
+	if opts.Limit > 0 && opts.Limit < len(items) {
+		items = items[0:opts.Limit]
+	}
+
+	r := new(RSS2)
+	r.Version = "2.0"
+	r.Title = opts.Title
+	r.Link = opts.Link
+	if r.Link == "" {
+		r.Link = opts.BaseURL
+	}
+	r.Description = opts.Description
+	r.Generator = "rss2 mkrss " + Version
+	for _, d := range items {
+		if !d.pubDate.IsZero() && r.PubDate == "" {
+			r.PubDate = d.item.PubDate
+			r.LastBuildDate = d.item.PubDate
+		}
+		r.ItemList = append(r.ItemList, *d.item)
+	}
+	return r, nil
+}
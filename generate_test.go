@@ -0,0 +1,170 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	tests := map[string]string{
+		"---\ntitle: Hello\ntags: [a, b]\n---\n# Body\n":               "# Body\n",
+		"+++\ntitle = \"Hello\"\ntags = [\"a\", \"b\"]\n+++\n# Body\n": "# Body\n",
+		"{\"title\": \"Hello\", \"tags\": [\"a\", \"b\"]}\n# Body\n":   "# Body\n",
+	}
+	for src, expected := range tests {
+		meta, body, err := ParseFrontMatter([]byte(src))
+		if err != nil {
+			t.Errorf("%q: %s", src, err)
+			continue
+		}
+		if meta["title"] != "Hello" {
+			t.Errorf("%q: expected title Hello, got %#v", src, meta)
+		}
+		if tags := frontMatter(meta, "category"); strings.Join(tags, "|") != "a|b" {
+			t.Errorf("%q: expected tags a, b, got %q", src, tags)
+		}
+		if string(body) != expected {
+			t.Errorf("%q: expected body %q, got %q", src, expected, body)
+		}
+	}
+
+	meta, body, err := ParseFrontMatter([]byte("Just text\n---\n"))
+	if err != nil || len(meta) != 0 || string(body) != "Just text\n---\n" {
+		t.Errorf("expected no front matter, got %#v %q %v", meta, body, err)
+	}
+	for _, src := range []string{"---\ntitle: open\n", "---\n- a list\n---\n", "{\"title\": \n"} {
+		if _, _, err := ParseFrontMatter([]byte(src)); err == nil {
+			t.Errorf("%q: expected an error", src)
+		}
+	}
+}
+
+func TestMarkdownText(t *testing.T) {
+	src := "# Title\n\nSome *emphasis*, `code`, a [link](http://example.edu) and ![an image](x.png).\n\n```\nleft out\n```\n\n- one\n- snake_case\n\n> quoted &amp; <b>bold</b>\n\n[ref]: http://example.edu\n"
+	expected := "Title Some emphasis, code, a link and an image. one snake_case quoted & bold"
+	if s := markdownText(src); s != expected {
+		t.Errorf("expected %q, got %q", expected, s)
+	}
+	if s := markdownTitle("text\n# The *Title* #\n"); s != "The Title" {
+		t.Errorf("expected The Title, got %q", s)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rss2")
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	docs := map[string]string{
+		"2016/05/28/opml.md": "# OPML to Markdown and back\n\nConverting *outlines* to Markdown.\n",
+		"2016/07/04/pi-top.md": `---
+title: How to make a Pi-Top more Raspbian
+author: R. S. Doiel
+categories:
+  - Raspberry Pi
+  - Linux
+---
+Some notes on the Pi-Top.
+`,
+		"notes/toml.md": `+++
+title = "TOML post"
+date = "2017-01-02T10:00:00Z"
+tags = "go, toml"
+description = "Written with TOML front matter"
++++
+Not used.
+`,
+		"json.markdown": `{"title": "JSON post", "date": "2016-06-01", "link": "/elsewhere.html", "authors": ["A", "B"]}
+Body.
+`,
+		"undated.md":     "No title or date.\n",
+		"draft.md":       "---\ntitle: Not yet\ndraft: true\n---\n",
+		"readme.txt":     "Not Markdown.\n",
+		".hidden/old.md": "# Hidden\n",
+	}
+	for name, src := range docs {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0777)
+		if err := ioutil.WriteFile(p, []byte(src), 0666); err != nil {
+			t.Errorf("%s", err)
+			t.FailNow()
+		}
+	}
+	r, err := Generate(dir, &GenerateOptions{
+		Title:   "R. S. Doiel",
+		BaseURL: "http://rsdoiel.github.io/blog/",
+		Author:  "Default Author",
+	})
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	titles := []string{}
+	for _, item := range r.ItemList {
+		titles = append(titles, item.Title)
+	}
+	expected := "TOML post|How to make a Pi-Top more Raspbian|JSON post|OPML to Markdown and back|undated"
+	if strings.Join(titles, "|") != expected {
+		t.Errorf("expected %s, got %s", expected, strings.Join(titles, "|"))
+		t.FailNow()
+	}
+	if r.Link != "http://rsdoiel.github.io/blog/" || r.PubDate != "Mon, 02 Jan 2017 10:00:00 +0000" {
+		t.Errorf("unexpected channel %q %q", r.Link, r.PubDate)
+	}
+	toml, pi, json, opml, undated := r.ItemList[0], r.ItemList[1], r.ItemList[2], r.ItemList[3], r.ItemList[4]
+	if toml.Description != "Written with TOML front matter" || strings.Join(toml.Category, "|") != "go|toml" || toml.Link != "http://rsdoiel.github.io/blog/notes/toml.html" {
+		t.Errorf("unexpected TOML item %+v", toml)
+	}
+	if pi.Author != "R. S. Doiel" || strings.Join(pi.Category, "|") != "Raspberry Pi|Linux" || pi.PubDate != "Mon, 04 Jul 2016 00:00:00 +0000" || pi.Description != "Some notes on the Pi-Top." {
+		t.Errorf("unexpected YAML item %+v", pi)
+	}
+	if json.Author != "A, B" || json.Link != "http://rsdoiel.github.io/blog/elsewhere.html" {
+		t.Errorf("unexpected JSON item %+v", json)
+	}
+	if opml.Author != "Default Author" || opml.Description != "Converting outlines to Markdown." || opml.Link != "http://rsdoiel.github.io/blog/2016/05/28/opml.html" {
+		t.Errorf("unexpected item %+v", opml)
+	}
+	if undated.PubDate != "" || undated.Description != "No title or date." {
+		t.Errorf("unexpected undated item %+v", undated)
+	}
+
+	r, err = Generate(dir, &GenerateOptions{Link: "http://example.edu", Excerpt: -1, Limit: 2})
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if len(r.ItemList) != 2 || r.ItemList[1].Description != "" || r.ItemList[1].Link != "http://example.edu/2016/07/04/pi-top.html" {
+		t.Errorf("unexpected items %+v", r.ItemList)
+	}
+	if _, err := r.ToXML(); err != nil {
+		t.Errorf("%s", err)
+	}
+
+	ioutil.WriteFile(filepath.Join(dir, "bad.md"), []byte("---\ndate: someday\n---\n"), 0666)
+	if _, err := Generate(dir, nil); err == nil || !strings.Contains(err.Error(), "bad.md") {
+		t.Errorf("expected an error naming bad.md, got %v", err)
+	}
+}
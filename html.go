@@ -0,0 +1,265 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultHTMLTemplate renders the channel title and an accessible
+// list of items, suitable for embedding in a page
+const DefaultHTMLTemplate = `<section class="rss2-feed" aria-labelledby="rss2-feed-title">
+  <h2 id="rss2-feed-title">{{if .Link}}<a href="{{.Link}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}</h2>
+  <ul>
+{{- range .ItemList}}
+    <li>
+      <article>
+        <h3>{{if .Link}}<a href="{{.Link}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}</h3>
+{{- with .PubDate}}
+        <p><time datetime="{{isodate .}}">{{localdate .}}</time></p>
+{{- end}}
+{{- with .Description}}
+        <p>{{truncate 280 (striphtml .)}}</p>
+{{- end}}
+      </article>
+    </li>
+{{- end}}
+  </ul>
+</section>
+`
+
+// dateLocale formats dates for a locale, format holds {day},
+// {month}, {mon} (month number) and {year}
+type dateLocale struct {
+	format string
+	months []string
+}
+
+var (
+	monthsEN = []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+	monthsFR = []string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"}
+	monthsDE = []string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"}
+	monthsES = []string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"}
+	monthsIT = []string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"}
+	monthsPT = []string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"}
+	monthsNL = []string{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"}
+
+	// dateLocales are keyed by lowercase language tag, a tag not
+	// found falls back to its language (e.g. fr-ca to fr) then to en
+	dateLocales = map[string]dateLocale{
+		"en":    {"{month} {day}, {year}", monthsEN},
+		"en-gb": {"{day} {month} {year}", monthsEN},
+		"en-au": {"{day} {month} {year}", monthsEN},
+		"en-nz": {"{day} {month} {year}", monthsEN},
+		"en-ie": {"{day} {month} {year}", monthsEN},
+		"fr":    {"{day} {month} {year}", monthsFR},
+		"de":    {"{day}. {month} {year}", monthsDE},
+		"es":    {"{day} de {month} de {year}", monthsES},
+		"it":    {"{day} {month} {year}", monthsIT},
+		"pt":    {"{day} de {month} de {year}", monthsPT},
+		"nl":    {"{day} {month} {year}", monthsNL},
+		"ja":    {"{year}年{mon}月{day}日", nil},
+		"zh":    {"{year}年{mon}月{day}日", nil},
+		"ko":    {"{year}년 {mon}월 {day}일", nil},
+	}
+)
+
+// lookupLocale finds the date format for a language tag like en-US
+func lookupLocale(locale string) dateLocale {
+	tag := strings.ToLower(strings.Replace(locale, "_", "-", -1))
+	// drop an encoding, e.g. en_US.UTF-8
+	if i := strings.Index(tag, "."); i >= 0 {
+		tag = tag[0:i]
+	}
+	if loc, ok := dateLocales[tag]; ok {
+		return loc
+	}
+	if i := strings.Index(tag, "-"); i >= 0 {
+		if loc, ok := dateLocales[tag[0:i]]; ok {
+			return loc
+		}
+	}
+	return dateLocales["en"]
+}
+
+// LocalDate formats an RSS date for a locale (e.g. "en-US", "fr"),
+// values that aren't dates are returned unchanged
+func LocalDate(locale, value string) string {
+	t, err := parseDate(value)
+	if err != nil {
+		return value
+	}
+	return localDate(locale, t)
+}
+
+// localDate formats t for a locale
+func localDate(locale string, t time.Time) string {
+	loc := lookupLocale(locale)
+	month := fmt.Sprintf("%d", t.Month())
+	if loc.months != nil {
+		month = loc.months[t.Month()-1]
+	}
+	return strings.NewReplacer(
+		"{day}", fmt.Sprintf("%d", t.Day()),
+		"{month}", month,
+		"{mon}", fmt.Sprintf("%d", t.Month()),
+		"{year}", fmt.Sprintf("%d", t.Year()),
+	).Replace(loc.format)
+}
+
+// Truncate shortens s to at most n characters, breaking at the last
+// space that fits, and adds an ellipsis if s was cut. Words are only
+// cut when there is no space to break at.
+func Truncate(n int, s string) string {
+	if n <= 0 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	// leave room for the ellipsis
+	runes := []rune(s)
+	cut := n - 1
+	for i := n - 1; i > 0; i-- {
+		if runes[i] == ' ' {
+			cut = i
+			break
+		}
+	}
+	return strings.TrimRight(string(runes[0:cut]), " ,.;:") + "…"
+}
+
+// stripHTML removes tags, comments and the contents of script and
+// style elements from s, decodes entities and collapses white space
+func stripHTML(s string) string {
+	return strings.Join(strings.Fields(htmlText(s)), " ")
+}
+
+// inlineTags are the phrasing elements that don't break a run of text
+var inlineTags = map[string]bool{
+	"a": true, "abbr": true, "b": true, "bdi": true, "bdo": true,
+	"cite": true, "code": true, "data": true, "dfn": true, "em": true,
+	"i": true, "kbd": true, "mark": true, "q": true, "s": true,
+	"samp": true, "small": true, "span": true, "strong": true,
+	"sub": true, "sup": true, "time": true, "u": true, "var": true,
+}
+
+// tagName returns the lower case element name of a tag such as
+// "<em class=x" or "</em"
+func tagName(tag string) string {
+	tag = strings.TrimPrefix(strings.TrimPrefix(tag, "<"), "/")
+	if i := strings.IndexAny(tag, " \t\r\n/>"); i >= 0 {
+		tag = tag[0:i]
+	}
+	return strings.ToLower(tag)
+}
+
+// tagEnd returns the index of the ">" closing the tag at the start of
+// s, skipping quoted attribute values, or -1
+func tagEnd(s string) int {
+	quote := byte(0)
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i
+		}
+	}
+	return -1
+}
+
+// StripHTML returns the text of an HTML fragment without markup
+func StripHTML(s string) string {
+	return stripHTML(s)
+}
+
+// TemplateFuncs returns the helper functions available to feed
+// templates, they work with both text/template and html/template.
+//
+//	date LAYOUT VALUE   formats an RSS date with a Go time layout
+//	isodate VALUE       formats an RSS date as RFC 3339
+//	localdate VALUE     formats an RSS date for the locale
+//	truncate N TEXT     shortens text to N characters
+//	striphtml TEXT      removes HTML markup from text
+//	sanitize TEXT       keeps the HTML allowed by DefaultPolicy
+//	locale              the locale, e.g. for a lang attribute
+func TemplateFuncs(locale string) map[string]interface{} {
+	return map[string]interface{}{
+		"date": func(layout, value string) string {
+			t, err := parseDate(value)
+			if err != nil {
+				return value
+			}
+			return t.Format(layout)
+		},
+		"isodate": func(value string) string {
+			t, err := parseDate(value)
+			if err != nil {
+				return ""
+			}
+			return t.Format(time.RFC3339)
+		},
+		"localdate": func(value string) string {
+			return LocalDate(locale, value)
+		},
+		"truncate":  Truncate,
+		"striphtml": stripHTML,
+		"sanitize": func(s string) template.HTML {
+			return template.HTML(SanitizeHTML(s, nil))
+		},
+		"locale": func() string {
+			return locale
+		},
+	}
+}
+
+// HTMLTemplate parses src as an html/template with the TemplateFuncs
+// helpers, an empty src uses DefaultHTMLTemplate
+func HTMLTemplate(src, locale string) (*template.Template, error) {
+	if src == "" {
+		src = DefaultHTMLTemplate
+	}
+	return template.New("rss2").Funcs(template.FuncMap(TemplateFuncs(locale))).Parse(src)
+}
+
+// RenderHTML executes tmpl with r as its data
+func (r *RSS2) RenderHTML(w io.Writer, tmpl *template.Template) error {
+	return tmpl.Execute(w, r)
+}
+
+// ToHTML renders r with DefaultHTMLTemplate and dates in US English
+func (r *RSS2) ToHTML() ([]byte, error) {
+	tmpl, err := HTMLTemplate("", "en-US")
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	if err := r.RenderHTML(buf, tmpl); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,120 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTemplateHelpers(t *testing.T) {
+	date := "Mon, 25 Jul 2016 20:48:03 -0700"
+	dates := map[string]string{
+		"en-US":       "July 25, 2016",
+		"en_GB.UTF-8": "25 July 2016",
+		"fr-CA":       "25 juillet 2016",
+		"de":          "25. Juli 2016",
+		"ja":          "2016年7月25日",
+		"xx":          "July 25, 2016",
+	}
+	for locale, expected := range dates {
+		if s := LocalDate(locale, date); s != expected {
+			t.Errorf("%s: expected %q, got %q", locale, expected, s)
+		}
+	}
+	if s := LocalDate("en", "not a date"); s != "not a date" {
+		t.Errorf("expected the value unchanged, got %q", s)
+	}
+
+	truncated := map[string]string{
+		"short":                              "short",
+		"The quick brown fox jumps over":     "The quick brown fox…",
+		"Supercalifragilisticexpialidocious": "Supercalifragilisti…",
+		// a long last word is left out rather than cut
+		"See https://library.example.edu/news/1": "See…",
+	}
+	for s, expected := range truncated {
+		if got := Truncate(20, s); got != expected {
+			t.Errorf("Truncate(20, %q): expected %q, got %q", s, expected, got)
+		}
+	}
+
+	stripped := map[string]string{
+		`<p>Hello <b>world</b></p>`:                          "Hello world",
+		`<p>One</p><p>Two</p>`:                               "One Two",
+		`Fish &amp; chips &lt;3`:                             "Fish & chips <3",
+		`a < b and c > d`:                                    "a < b and c > d",
+		`<script>alert("x")</script><style>p {}</style>Text`: "Text",
+		"<!-- comment -->Visible\n\n  text":                  "Visible text",
+		`<img src="x.png" alt="a > b">After`:                 "After",
+	}
+	for s, expected := range stripped {
+		if got := StripHTML(s); got != expected {
+			t.Errorf("StripHTML(%q): expected %q, got %q", s, expected, got)
+		}
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	r, err := Parse(pathTestSrc)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	r.ItemList[1].Description = `<p>Closed on <em>Monday</em> &amp; <script>alert("x")</script>Tuesday</p>`
+	src, err := r.ToHTML()
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	s := string(src)
+	for _, expected := range []string{
+		`<h2 id="rss2-feed-title"><a href="https://library.example.edu/news">Library News</a></h2>`,
+		`<h3><a href="https://library.example.edu/news/1">New chemistry database</a></h3>`,
+		`<time datetime="2016-07-25T20:48:03-07:00">July 25, 2016</time>`,
+		`<p>Closed on Monday &amp; Tuesday</p>`,
+	} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("expected %q in\n%s", expected, s)
+		}
+	}
+	if strings.Contains(s, "alert") {
+		t.Errorf("expected script content to be removed\n%s", s)
+	}
+
+	tmpl, err := HTMLTemplate(`<ol lang="{{locale}}">{{range .ItemList}}<li>{{date "2006-01-02" .PubDate}} {{.Title}}</li>{{end}}</ol>`, "fr")
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	buf := new(bytes.Buffer)
+	if err := r.RenderHTML(buf, tmpl); err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	expected := `<ol lang="fr"><li>2016-07-25 New chemistry database</li><li> Holiday hours</li></ol>`
+	if buf.String() != expected {
+		t.Errorf("expected %s, got %s", expected, buf.String())
+	}
+
+	if _, err := HTMLTemplate(`{{nosuchfunc .Title}}`, "en"); err == nil {
+		t.Errorf("expected an error for an unknown function")
+	}
+}
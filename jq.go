@@ -0,0 +1,2294 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+//
+// Query expressions
+//
+// Query evaluates a subset of the jq language (https://stedolan.github.io/jq/)
+// over the JSON model produced by rss2json, e.g. ".item[] | .title".
+//
+// Supported
+//
+//     .  ..  .foo  ."foo"  .foo?  .[n]  .[m:n]  .[]  .["foo"]
+//     |  ,  //  ( )  literals (numbers, strings, true, false, null)
+//     [ expr ]  { key: expr, key, "key": expr, (expr): expr }
+//     "string \(expr) interpolation"
+//     ==  !=  <  <=  >  >=  and  or  +  -  *  /  %
+//     if c then a elif c then b else d end
+//     try expr [ catch expr ]
+//     @text @json @csv @tsv @html @uri @base64
+//
+// Functions
+//
+//     length, keys, values, has(k), not, empty, type, select(f),
+//     map(f), map_values(f), to_entries, from_entries, add, any,
+//     any(f), any(gen; cond), all, all(f), all(gen; cond), flatten,
+//     range(n), range(m; n), first, last, first(f), last(f),
+//     limit(n; f), reverse, sort, sort_by(f), group_by(f), unique,
+//     unique_by(f), min, max, min_by(f), max_by(f), tostring,
+//     tonumber, tojson, fromjson, ascii_downcase, ascii_upcase,
+//     ltrimstr(s), rtrimstr(s), startswith(s), endswith(s), split(s),
+//     join(s), test(re), test(re; flags), sub(re; s), gsub(re; s),
+//     contains(x), floor, ceil, fromdate, todate, now
+//
+// Only the arities listed are supported, e.g. there is no
+// sub(re; s; flags). Regular expressions use Go's syntax, the flags
+// of test are i (ignore case) and s or p (. matches newlines), g, n
+// and l are accepted and x isn't supported.
+//
+// fromdate also accepts RSS (RFC 822) dates so items can be ordered
+// with sort_by(.pubDate | fromdate). Variables, reduce, paths and
+// assignment are not supported. Objects are output with sorted keys.
+//
+
+// jqNode is a compiled query expression, eval returns every value
+// the expression produces for input in
+type jqNode interface {
+	eval(in interface{}) ([]interface{}, error)
+}
+
+type jqIdentity struct{}
+
+func (n *jqIdentity) eval(in interface{}) ([]interface{}, error) {
+	return []interface{}{in}, nil
+}
+
+type jqRecurse struct{}
+
+func (n *jqRecurse) eval(in interface{}) ([]interface{}, error) {
+	out := []interface{}{in}
+	switch x := in.(type) {
+	case []interface{}:
+		for _, v := range x {
+			vals, _ := n.eval(v)
+			out = append(out, vals...)
+		}
+	case map[string]interface{}:
+		for _, k := range sortedKeys(x) {
+			vals, _ := n.eval(x[k])
+			out = append(out, vals...)
+		}
+	}
+	return out, nil
+}
+
+type jqLiteral struct {
+	value interface{}
+}
+
+func (n *jqLiteral) eval(in interface{}) ([]interface{}, error) {
+	return []interface{}{n.value}, nil
+}
+
+// jqIndex is target[index], index is evaluated against the input
+type jqIndex struct {
+	target, index jqNode
+}
+
+func jqLookup(v interface{}, key interface{}) (interface{}, error) {
+	switch k := key.(type) {
+	case string:
+		switch x := v.(type) {
+		case map[string]interface{}:
+			return x[k], nil
+		case nil:
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cannot index %s with %q", jqType(v), k)
+	case float64:
+		switch x := v.(type) {
+		case []interface{}:
+			i := int(math.Floor(k))
+			if i < 0 {
+				i += len(x)
+			}
+			if i < 0 || i >= len(x) {
+				return nil, nil
+			}
+			return x[i], nil
+		case nil:
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cannot index %s with number", jqType(v))
+	}
+	return nil, fmt.Errorf("cannot index %s with %s", jqType(v), jqType(key))
+}
+
+func (n *jqIndex) eval(in interface{}) ([]interface{}, error) {
+	targets, err := n.target.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := n.index.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	out := []interface{}{}
+	for _, t := range targets {
+		for _, k := range keys {
+			v, err := jqLookup(t, k)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+// jqSlice is target[from:to], either bound may be nil
+type jqSlice struct {
+	target, from, to jqNode
+}
+
+func (n *jqSlice) bound(node jqNode, in interface{}, dflt int, length int) (int, error) {
+	if node == nil {
+		return dflt, nil
+	}
+	vals, err := node.eval(in)
+	if err != nil || len(vals) == 0 {
+		return 0, err
+	}
+	f, ok := vals[0].(float64)
+	if !ok {
+		if vals[0] == nil {
+			return dflt, nil
+		}
+		return 0, fmt.Errorf("slice bounds must be numbers")
+	}
+	i := int(math.Floor(f))
+	if i < 0 {
+		i += length
+	}
+	if i < 0 {
+		i = 0
+	}
+	if i > length {
+		i = length
+	}
+	return i, nil
+}
+
+func (n *jqSlice) eval(in interface{}) ([]interface{}, error) {
+	targets, err := n.target.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	out := []interface{}{}
+	for _, t := range targets {
+		length := 0
+		switch x := t.(type) {
+		case []interface{}:
+			length = len(x)
+		case string:
+			length = utf8.RuneCountInString(x)
+		case nil:
+			out = append(out, nil)
+			continue
+		default:
+			return nil, fmt.Errorf("cannot slice %s", jqType(t))
+		}
+		from, err := n.bound(n.from, in, 0, length)
+		if err != nil {
+			return nil, err
+		}
+		to, err := n.bound(n.to, in, length, length)
+		if err != nil {
+			return nil, err
+		}
+		if to < from {
+			to = from
+		}
+		switch x := t.(type) {
+		case []interface{}:
+			out = append(out, append([]interface{}{}, x[from:to]...))
+		case string:
+			out = append(out, string([]rune(x)[from:to]))
+		}
+	}
+	return out, nil
+}
+
+type jqIterate struct {
+	target jqNode
+}
+
+func (n *jqIterate) eval(in interface{}) ([]interface{}, error) {
+	targets, err := n.target.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	out := []interface{}{}
+	for _, t := range targets {
+		switch x := t.(type) {
+		case []interface{}:
+			out = append(out, x...)
+		case map[string]interface{}:
+			for _, k := range sortedKeys(x) {
+				out = append(out, x[k])
+			}
+		default:
+			return nil, fmt.Errorf("cannot iterate over %s", jqType(t))
+		}
+	}
+	return out, nil
+}
+
+// jqTry suppresses errors, with a catch expression the error message
+// is passed to it
+type jqTry struct {
+	body, catch jqNode
+}
+
+func (n *jqTry) eval(in interface{}) ([]interface{}, error) {
+	out, err := n.body.eval(in)
+	if err != nil {
+		if n.catch != nil {
+			return n.catch.eval(err.Error())
+		}
+		return []interface{}{}, nil
+	}
+	return out, nil
+}
+
+type jqPipe struct {
+	left, right jqNode
+}
+
+func (n *jqPipe) eval(in interface{}) ([]interface{}, error) {
+	vals, err := n.left.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	out := []interface{}{}
+	for _, v := range vals {
+		res, err := n.right.eval(v)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, res...)
+	}
+	return out, nil
+}
+
+type jqComma struct {
+	left, right jqNode
+}
+
+func (n *jqComma) eval(in interface{}) ([]interface{}, error) {
+	left, err := n.left.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	return append(left, right...), nil
+}
+
+type jqAlternative struct {
+	left, right jqNode
+}
+
+func (n *jqAlternative) eval(in interface{}) ([]interface{}, error) {
+	vals, _ := n.left.eval(in)
+	out := []interface{}{}
+	for _, v := range vals {
+		if jqTruthy(v) {
+			out = append(out, v)
+		}
+	}
+	if len(out) > 0 {
+		return out, nil
+	}
+	return n.right.eval(in)
+}
+
+type jqArray struct {
+	body jqNode
+}
+
+func (n *jqArray) eval(in interface{}) ([]interface{}, error) {
+	if n.body == nil {
+		return []interface{}{[]interface{}{}}, nil
+	}
+	vals, err := n.body.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{vals}, nil
+}
+
+type jqObjectEntry struct {
+	key, value jqNode
+}
+
+type jqObject struct {
+	entries []jqObjectEntry
+}
+
+func (n *jqObject) eval(in interface{}) ([]interface{}, error) {
+	objects := []map[string]interface{}{{}}
+	for _, entry := range n.entries {
+		keys, err := entry.key.eval(in)
+		if err != nil {
+			return nil, err
+		}
+		vals, err := entry.value.eval(in)
+		if err != nil {
+			return nil, err
+		}
+		next := []map[string]interface{}{}
+		for _, obj := range objects {
+			for _, k := range keys {
+				key, ok := k.(string)
+				if !ok {
+					return nil, fmt.Errorf("object keys must be strings")
+				}
+				for _, v := range vals {
+					o := map[string]interface{}{}
+					for ok, ov := range obj {
+						o[ok] = ov
+					}
+					o[key] = v
+					next = append(next, o)
+				}
+			}
+		}
+		objects = next
+	}
+	out := []interface{}{}
+	for _, obj := range objects {
+		out = append(out, obj)
+	}
+	return out, nil
+}
+
+// jqInterpolation is a string with \(expr) parts
+type jqInterpolation struct {
+	parts  []interface{} // string or jqNode
+	format string
+}
+
+func (n *jqInterpolation) eval(in interface{}) ([]interface{}, error) {
+	results := []string{""}
+	for _, part := range n.parts {
+		if s, ok := part.(string); ok {
+			for i := range results {
+				results[i] += s
+			}
+			continue
+		}
+		vals, err := part.(jqNode).eval(in)
+		if err != nil {
+			return nil, err
+		}
+		next := []string{}
+		for _, r := range results {
+			for _, v := range vals {
+				s, err := jqFormat(n.format, v)
+				if err != nil {
+					return nil, err
+				}
+				next = append(next, r+s)
+			}
+		}
+		results = next
+	}
+	out := []interface{}{}
+	for _, r := range results {
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+type jqFormatNode struct {
+	format string
+}
+
+func (n *jqFormatNode) eval(in interface{}) ([]interface{}, error) {
+	s, err := jqFormat(n.format, in)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{s}, nil
+}
+
+type jqIf struct {
+	cond, then, otherwise jqNode
+}
+
+func (n *jqIf) eval(in interface{}) ([]interface{}, error) {
+	conds, err := n.cond.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	out := []interface{}{}
+	for _, c := range conds {
+		branch := n.otherwise
+		if jqTruthy(c) {
+			branch = n.then
+		}
+		if branch == nil {
+			out = append(out, in)
+			continue
+		}
+		vals, err := branch.eval(in)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, vals...)
+	}
+	return out, nil
+}
+
+type jqNeg struct {
+	expr jqNode
+}
+
+func (n *jqNeg) eval(in interface{}) ([]interface{}, error) {
+	vals, err := n.expr.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	out := []interface{}{}
+	for _, v := range vals {
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("cannot negate %s", jqType(v))
+		}
+		out = append(out, -f)
+	}
+	return out, nil
+}
+
+type jqBinary struct {
+	op          string
+	left, right jqNode
+}
+
+func (n *jqBinary) eval(in interface{}) ([]interface{}, error) {
+	if n.op == "and" || n.op == "or" {
+		lefts, err := n.left.eval(in)
+		if err != nil {
+			return nil, err
+		}
+		out := []interface{}{}
+		for _, l := range lefts {
+			if n.op == "and" && !jqTruthy(l) {
+				out = append(out, false)
+				continue
+			}
+			if n.op == "or" && jqTruthy(l) {
+				out = append(out, true)
+				continue
+			}
+			rights, err := n.right.eval(in)
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range rights {
+				out = append(out, jqTruthy(r))
+			}
+		}
+		return out, nil
+	}
+	rights, err := n.right.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	lefts, err := n.left.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	out := []interface{}{}
+	for _, r := range rights {
+		for _, l := range lefts {
+			v, err := jqArith(n.op, l, r)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+func jqArith(op string, l, r interface{}) (interface{}, error) {
+	switch op {
+	case "==":
+		return jqCompare(l, r) == 0, nil
+	case "!=":
+		return jqCompare(l, r) != 0, nil
+	case "<":
+		return jqCompare(l, r) < 0, nil
+	case "<=":
+		return jqCompare(l, r) <= 0, nil
+	case ">":
+		return jqCompare(l, r) > 0, nil
+	case ">=":
+		return jqCompare(l, r) >= 0, nil
+	}
+	lf, lnum := l.(float64)
+	rf, rnum := r.(float64)
+	switch op {
+	case "+":
+		switch {
+		case l == nil:
+			return r, nil
+		case r == nil:
+			return l, nil
+		case lnum && rnum:
+			return lf + rf, nil
+		}
+		switch lx := l.(type) {
+		case string:
+			if rs, ok := r.(string); ok {
+				return lx + rs, nil
+			}
+		case []interface{}:
+			if ra, ok := r.([]interface{}); ok {
+				return append(append([]interface{}{}, lx...), ra...), nil
+			}
+		case map[string]interface{}:
+			if rm, ok := r.(map[string]interface{}); ok {
+				o := map[string]interface{}{}
+				for k, v := range lx {
+					o[k] = v
+				}
+				for k, v := range rm {
+					o[k] = v
+				}
+				return o, nil
+			}
+		}
+	case "-":
+		if lnum && rnum {
+			return lf - rf, nil
+		}
+		if la, ok := l.([]interface{}); ok {
+			if ra, ok := r.([]interface{}); ok {
+				out := []interface{}{}
+				for _, lv := range la {
+					keep := true
+					for _, rv := range ra {
+						if jqCompare(lv, rv) == 0 {
+							keep = false
+							break
+						}
+					}
+					if keep {
+						out = append(out, lv)
+					}
+				}
+				return out, nil
+			}
+		}
+	case "*":
+		if lnum && rnum {
+			return lf * rf, nil
+		}
+	case "/":
+		if lnum && rnum {
+			if rf == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			return lf / rf, nil
+		}
+		if ls, ok := l.(string); ok {
+			if rs, ok := r.(string); ok {
+				return jqSplit(ls, rs), nil
+			}
+		}
+	case "%":
+		if lnum && rnum {
+			if int(rf) == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			return float64(int(lf) % int(rf)), nil
+		}
+	}
+	return nil, fmt.Errorf("%s and %s cannot be combined with %s", jqType(l), jqType(r), op)
+}
+
+// jqCall is a builtin function call
+type jqCall struct {
+	name string
+	args []jqNode
+}
+
+func (n *jqCall) eval(in interface{}) ([]interface{}, error) {
+	fn := jqBuiltins[fmt.Sprintf("%s/%d", n.name, len(n.args))]
+	return fn(in, n.args)
+}
+
+//
+// Values
+//
+
+func jqType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func jqTruthy(v interface{}) bool {
+	switch x := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return x
+	}
+	return true
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// jqRank orders types as jq does, null < false < true < numbers <
+// strings < arrays < objects
+func jqRank(v interface{}) int {
+	switch x := v.(type) {
+	case nil:
+		return 0
+	case bool:
+		if x {
+			return 2
+		}
+		return 1
+	case float64:
+		return 3
+	case string:
+		return 4
+	case []interface{}:
+		return 5
+	}
+	return 6
+}
+
+func jqCompare(a, b interface{}) int {
+	ra, rb := jqRank(a), jqRank(b)
+	if ra != rb {
+		if ra < rb {
+			return -1
+		}
+		return 1
+	}
+	switch x := a.(type) {
+	case float64:
+		y := b.(float64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(x, b.(string))
+	case []interface{}:
+		y := b.([]interface{})
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if c := jqCompare(x[i], y[i]); c != 0 {
+				return c
+			}
+		}
+		return jqCompare(float64(len(x)), float64(len(y)))
+	case map[string]interface{}:
+		y := b.(map[string]interface{})
+		xk, yk := sortedKeys(x), sortedKeys(y)
+		ka, kb := []interface{}{}, []interface{}{}
+		for _, k := range xk {
+			ka = append(ka, k)
+		}
+		for _, k := range yk {
+			kb = append(kb, k)
+		}
+		if c := jqCompare(ka, kb); c != 0 {
+			return c
+		}
+		for _, k := range xk {
+			if c := jqCompare(x[k], y[k]); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+func jqSplit(s, sep string) []interface{} {
+	out := []interface{}{}
+	if s == "" {
+		return out
+	}
+	for _, part := range strings.Split(s, sep) {
+		out = append(out, part)
+	}
+	return out
+}
+
+func jqToString(v interface{}) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	src, err := json.Marshal(v)
+	return string(src), err
+}
+
+// jqFormat applies an @format to a value
+func jqFormat(format string, v interface{}) (string, error) {
+	switch format {
+	case "", "text":
+		return jqToString(v)
+	case "json":
+		src, err := json.Marshal(v)
+		return string(src), err
+	case "html":
+		s, err := jqToString(v)
+		return strings.Replace(html.EscapeString(s), "&#34;", "&quot;", -1), err
+	case "uri":
+		s, err := jqToString(v)
+		return url.QueryEscape(s), err
+	case "base64":
+		s, err := jqToString(v)
+		return base64.StdEncoding.EncodeToString([]byte(s)), err
+	case "csv", "tsv":
+		row, ok := v.([]interface{})
+		if !ok {
+			return "", fmt.Errorf("@%s requires an array", format)
+		}
+		cells := []string{}
+		for _, cell := range row {
+			s := ""
+			switch x := cell.(type) {
+			case string:
+				s = x
+				if format == "csv" {
+					s = `"` + strings.Replace(x, `"`, `""`, -1) + `"`
+				} else {
+					s = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r").Replace(x)
+				}
+			case nil:
+			default:
+				s, _ = jqToString(x)
+			}
+			cells = append(cells, s)
+		}
+		if format == "csv" {
+			return strings.Join(cells, ","), nil
+		}
+		return strings.Join(cells, "\t"), nil
+	}
+	return "", fmt.Errorf("unknown format @%s", format)
+}
+
+//
+// Builtin functions
+//
+
+type jqBuiltin func(in interface{}, args []jqNode) ([]interface{}, error)
+
+var jqBuiltins map[string]jqBuiltin
+
+func one(v interface{}) ([]interface{}, error) {
+	return []interface{}{v}, nil
+}
+
+// jqStringFn makes a builtin taking one string argument applied to a
+// string input
+func jqStringFn(name string, fn func(s, arg string) interface{}) jqBuiltin {
+	return func(in interface{}, args []jqNode) ([]interface{}, error) {
+		s, ok := in.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s input must be a string, got %s", name, jqType(in))
+		}
+		vals, err := args[0].eval(in)
+		if err != nil {
+			return nil, err
+		}
+		out := []interface{}{}
+		for _, v := range vals {
+			arg, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s argument must be a string", name)
+			}
+			out = append(out, fn(s, arg))
+		}
+		return out, nil
+	}
+}
+
+// jqByFn makes the *_by(f) builtins, each array element is paired
+// with [f] as its key
+func jqByFn(name string, fn func(elems []interface{}, keys []interface{}) interface{}) jqBuiltin {
+	return func(in interface{}, args []jqNode) ([]interface{}, error) {
+		elems, ok := in.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s input must be an array, got %s", name, jqType(in))
+		}
+		keys := []interface{}{}
+		for _, e := range elems {
+			k, err := args[0].eval(e)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, k)
+		}
+		return one(fn(elems, keys))
+	}
+}
+
+// sortByKeys returns the elements stable sorted by their keys
+func sortByKeys(elems []interface{}, keys []interface{}) ([]interface{}, []interface{}) {
+	idx := make([]int, len(elems))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		return jqCompare(keys[idx[a]], keys[idx[b]]) < 0
+	})
+	sortedElems, sortedKeys := []interface{}{}, []interface{}{}
+	for _, i := range idx {
+		sortedElems = append(sortedElems, elems[i])
+		sortedKeys = append(sortedKeys, keys[i])
+	}
+	return sortedElems, sortedKeys
+}
+
+func jqIdentityKeys(in interface{}, name string) ([]interface{}, error) {
+	elems, ok := in.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s input must be an array, got %s", name, jqType(in))
+	}
+	return elems, nil
+}
+
+// jqAnyAll applies cond to each value, for any (isAny) the result is
+// true if cond is true for one of them, for all if it is for each
+func jqAnyAll(vals []interface{}, cond jqNode, isAny bool) ([]interface{}, error) {
+	for _, v := range vals {
+		results, err := cond.eval(v)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range results {
+			if jqTruthy(r) == isAny {
+				return one(isAny)
+			}
+		}
+	}
+	return one(!isAny)
+}
+
+func jqLength(in interface{}) (interface{}, error) {
+	switch x := in.(type) {
+	case nil:
+		return float64(0), nil
+	case bool:
+		return nil, fmt.Errorf("boolean has no length")
+	case float64:
+		return math.Abs(x), nil
+	case string:
+		return float64(utf8.RuneCountInString(x)), nil
+	case []interface{}:
+		return float64(len(x)), nil
+	case map[string]interface{}:
+		return float64(len(x)), nil
+	}
+	return nil, fmt.Errorf("%s has no length", jqType(in))
+}
+
+func jqContains(a, b interface{}) bool {
+	switch x := a.(type) {
+	case string:
+		y, ok := b.(string)
+		return ok && strings.Contains(x, y)
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, bv := range y {
+			found := false
+			for _, av := range x {
+				if jqContains(av, bv) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, bv := range y {
+			av, ok := x[k]
+			if !ok || !jqContains(av, bv) {
+				return false
+			}
+		}
+		return true
+	}
+	return jqCompare(a, b) == 0
+}
+
+func jqFlatten(in []interface{}, depth int) []interface{} {
+	out := []interface{}{}
+	for _, v := range in {
+		if a, ok := v.([]interface{}); ok && depth != 0 {
+			out = append(out, jqFlatten(a, depth-1)...)
+		} else {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// jqRegexpFlags converts jq regular expression flags to Go syntax.
+// g, n and l don't change if a string matches and are accepted,
+// x (extended syntax) isn't supported.
+func jqRegexpFlags(flags string) (string, error) {
+	prefix := ""
+	for _, c := range flags {
+		switch c {
+		case 'g', 'n', 'l':
+		case 'i':
+			prefix += "i"
+		case 's', 'p':
+			prefix += "s"
+		default:
+			return "", fmt.Errorf("%q is not a supported modifier string", flags)
+		}
+	}
+	if prefix == "" {
+		return "", nil
+	}
+	return "(?" + prefix + ")", nil
+}
+
+func jqRegexp(in interface{}, args []jqNode, flags jqNode, fn func(s string, re *regexp.Regexp, repl []interface{}) (interface{}, error)) ([]interface{}, error) {
+	s, ok := in.(string)
+	if !ok {
+		return nil, fmt.Errorf("regular expressions apply to strings, got %s", jqType(in))
+	}
+	res, err := args[0].eval(in)
+	if err != nil {
+		return nil, err
+	}
+	repl := []interface{}{}
+	if len(args) > 1 {
+		if repl, err = args[1].eval(in); err != nil {
+			return nil, err
+		}
+	}
+	prefix := ""
+	if flags != nil {
+		vals, err := flags.eval(in)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range vals {
+			f, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s is not a string", jqType(v))
+			}
+			if prefix, err = jqRegexpFlags(f); err != nil {
+				return nil, err
+			}
+		}
+	}
+	out := []interface{}{}
+	for _, r := range res {
+		pattern, ok := r.(string)
+		if !ok {
+			return nil, fmt.Errorf("regular expression must be a string")
+		}
+		re, err := regexp.Compile(prefix + pattern)
+		if err != nil {
+			return nil, err
+		}
+		v, err := fn(s, re, repl)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func init() {
+	jqBuiltins = map[string]jqBuiltin{
+		"empty/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			return []interface{}{}, nil
+		},
+		"not/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			return one(!jqTruthy(in))
+		},
+		"length/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			v, err := jqLength(in)
+			if err != nil {
+				return nil, err
+			}
+			return one(v)
+		},
+		"type/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			return one(jqType(in))
+		},
+		"keys/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			switch x := in.(type) {
+			case map[string]interface{}:
+				keys := []interface{}{}
+				for _, k := range sortedKeys(x) {
+					keys = append(keys, k)
+				}
+				return one(keys)
+			case []interface{}:
+				keys := []interface{}{}
+				for i := range x {
+					keys = append(keys, float64(i))
+				}
+				return one(keys)
+			}
+			return nil, fmt.Errorf("%s has no keys", jqType(in))
+		},
+		"values/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			if in == nil {
+				return []interface{}{}, nil
+			}
+			return one(in)
+		},
+		"has/1": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			keys, err := args[0].eval(in)
+			if err != nil {
+				return nil, err
+			}
+			out := []interface{}{}
+			for _, k := range keys {
+				switch x := in.(type) {
+				case map[string]interface{}:
+					s, ok := k.(string)
+					if !ok {
+						return nil, fmt.Errorf("object keys must be strings")
+					}
+					_, found := x[s]
+					out = append(out, found)
+				case []interface{}:
+					f, ok := k.(float64)
+					if !ok {
+						return nil, fmt.Errorf("array keys must be numbers")
+					}
+					out = append(out, f >= 0 && int(f) < len(x))
+				default:
+					return nil, fmt.Errorf("cannot check whether %s has a key", jqType(in))
+				}
+			}
+			return out, nil
+		},
+		"select/1": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			conds, err := args[0].eval(in)
+			if err != nil {
+				return nil, err
+			}
+			out := []interface{}{}
+			for _, c := range conds {
+				if jqTruthy(c) {
+					out = append(out, in)
+				}
+			}
+			return out, nil
+		},
+		"map/1": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			return (&jqArray{body: &jqPipe{left: &jqIterate{target: &jqIdentity{}}, right: args[0]}}).eval(in)
+		},
+		"map_values/1": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			switch x := in.(type) {
+			case map[string]interface{}:
+				o := map[string]interface{}{}
+				for k, v := range x {
+					vals, err := args[0].eval(v)
+					if err != nil {
+						return nil, err
+					}
+					if len(vals) > 0 {
+						o[k] = vals[0]
+					}
+				}
+				return one(o)
+			case []interface{}:
+				a := []interface{}{}
+				for _, v := range x {
+					vals, err := args[0].eval(v)
+					if err != nil {
+						return nil, err
+					}
+					if len(vals) > 0 {
+						a = append(a, vals[0])
+					}
+				}
+				return one(a)
+			}
+			return nil, fmt.Errorf("cannot map_values over %s", jqType(in))
+		},
+		"to_entries/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			x, ok := in.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("to_entries input must be an object")
+			}
+			entries := []interface{}{}
+			for _, k := range sortedKeys(x) {
+				entries = append(entries, map[string]interface{}{"key": k, "value": x[k]})
+			}
+			return one(entries)
+		},
+		"from_entries/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			entries, ok := in.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("from_entries input must be an array")
+			}
+			o := map[string]interface{}{}
+			for _, e := range entries {
+				m, ok := e.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("from_entries elements must be objects")
+				}
+				k := m["key"]
+				if k == nil {
+					k = m["name"]
+				}
+				s, err := jqToString(k)
+				if err != nil {
+					return nil, err
+				}
+				o[s] = m["value"]
+			}
+			return one(o)
+		},
+		"add/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			elems, err := jqIdentityKeys(in, "add")
+			if err != nil {
+				return nil, err
+			}
+			var sum interface{}
+			for _, e := range elems {
+				if sum, err = jqArith("+", sum, e); err != nil {
+					return nil, err
+				}
+			}
+			return one(sum)
+		},
+		"any/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			elems, err := jqIdentityKeys(in, "any")
+			if err != nil {
+				return nil, err
+			}
+			for _, e := range elems {
+				if jqTruthy(e) {
+					return one(true)
+				}
+			}
+			return one(false)
+		},
+		"all/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			elems, err := jqIdentityKeys(in, "all")
+			if err != nil {
+				return nil, err
+			}
+			for _, e := range elems {
+				if !jqTruthy(e) {
+					return one(false)
+				}
+			}
+			return one(true)
+		},
+		"any/1": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			elems, err := jqIdentityKeys(in, "any")
+			if err != nil {
+				return nil, err
+			}
+			return jqAnyAll(elems, args[0], true)
+		},
+		"all/1": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			elems, err := jqIdentityKeys(in, "all")
+			if err != nil {
+				return nil, err
+			}
+			return jqAnyAll(elems, args[0], false)
+		},
+		"any/2": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			vals, err := args[0].eval(in)
+			if err != nil {
+				return nil, err
+			}
+			return jqAnyAll(vals, args[1], true)
+		},
+		"all/2": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			vals, err := args[0].eval(in)
+			if err != nil {
+				return nil, err
+			}
+			return jqAnyAll(vals, args[1], false)
+		},
+		"flatten/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			elems, err := jqIdentityKeys(in, "flatten")
+			if err != nil {
+				return nil, err
+			}
+			return one(jqFlatten(elems, -1))
+		},
+		"range/1": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			return jqBuiltins["range/2"](in, []jqNode{&jqLiteral{value: float64(0)}, args[0]})
+		},
+		"range/2": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			froms, err := args[0].eval(in)
+			if err != nil {
+				return nil, err
+			}
+			tos, err := args[1].eval(in)
+			if err != nil {
+				return nil, err
+			}
+			out := []interface{}{}
+			for _, f := range froms {
+				for _, t := range tos {
+					from, ok1 := f.(float64)
+					to, ok2 := t.(float64)
+					if !ok1 || !ok2 {
+						return nil, fmt.Errorf("range bounds must be numbers")
+					}
+					for i := from; i < to; i++ {
+						out = append(out, i)
+					}
+				}
+			}
+			return out, nil
+		},
+		"first/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			return (&jqIndex{target: &jqIdentity{}, index: &jqLiteral{value: float64(0)}}).eval(in)
+		},
+		"last/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			return (&jqIndex{target: &jqIdentity{}, index: &jqLiteral{value: float64(-1)}}).eval(in)
+		},
+		"first/1": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			vals, err := args[0].eval(in)
+			if err != nil || len(vals) == 0 {
+				return []interface{}{}, err
+			}
+			return vals[0:1], nil
+		},
+		"last/1": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			vals, err := args[0].eval(in)
+			if err != nil || len(vals) == 0 {
+				return []interface{}{}, err
+			}
+			return vals[len(vals)-1:], nil
+		},
+		"limit/2": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			ns, err := args[0].eval(in)
+			if err != nil {
+				return nil, err
+			}
+			vals, err := args[1].eval(in)
+			if err != nil {
+				return nil, err
+			}
+			out := []interface{}{}
+			for _, n := range ns {
+				f, ok := n.(float64)
+				if !ok {
+					return nil, fmt.Errorf("limit must be a number")
+				}
+				for i := 0; i < int(f) && i < len(vals); i++ {
+					out = append(out, vals[i])
+				}
+			}
+			return out, nil
+		},
+		"reverse/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			switch x := in.(type) {
+			case nil:
+				return one([]interface{}{})
+			case string:
+				r := []rune(x)
+				for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+					r[i], r[j] = r[j], r[i]
+				}
+				return one(string(r))
+			}
+			elems, err := jqIdentityKeys(in, "reverse")
+			if err != nil {
+				return nil, err
+			}
+			out := []interface{}{}
+			for i := len(elems) - 1; i >= 0; i-- {
+				out = append(out, elems[i])
+			}
+			return one(out)
+		},
+		"sort/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			elems, err := jqIdentityKeys(in, "sort")
+			if err != nil {
+				return nil, err
+			}
+			sorted, _ := sortByKeys(elems, elems)
+			return one(sorted)
+		},
+		"sort_by/1": jqByFn("sort_by", func(elems, keys []interface{}) interface{} {
+			sorted, _ := sortByKeys(elems, keys)
+			return sorted
+		}),
+		"group_by/1": jqByFn("group_by", func(elems, keys []interface{}) interface{} {
+			sorted, sortedKeys := sortByKeys(elems, keys)
+			groups := []interface{}{}
+			for i := range sorted {
+				if i == 0 || jqCompare(sortedKeys[i], sortedKeys[i-1]) != 0 {
+					groups = append(groups, []interface{}{})
+				}
+				last := len(groups) - 1
+				groups[last] = append(groups[last].([]interface{}), sorted[i])
+			}
+			return groups
+		}),
+		"unique/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			return jqBuiltins["unique_by/1"](in, []jqNode{&jqIdentity{}})
+		},
+		"unique_by/1": jqByFn("unique_by", func(elems, keys []interface{}) interface{} {
+			sorted, sortedKeys := sortByKeys(elems, keys)
+			out := []interface{}{}
+			for i := range sorted {
+				if i == 0 || jqCompare(sortedKeys[i], sortedKeys[i-1]) != 0 {
+					out = append(out, sorted[i])
+				}
+			}
+			return out
+		}),
+		"min/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			return jqBuiltins["min_by/1"](in, []jqNode{&jqIdentity{}})
+		},
+		"max/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			return jqBuiltins["max_by/1"](in, []jqNode{&jqIdentity{}})
+		},
+		"min_by/1": jqByFn("min_by", func(elems, keys []interface{}) interface{} {
+			sorted, _ := sortByKeys(elems, keys)
+			if len(sorted) == 0 {
+				return nil
+			}
+			return sorted[0]
+		}),
+		"max_by/1": jqByFn("max_by", func(elems, keys []interface{}) interface{} {
+			sorted, _ := sortByKeys(elems, keys)
+			if len(sorted) == 0 {
+				return nil
+			}
+			return sorted[len(sorted)-1]
+		}),
+		"tostring/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			s, err := jqToString(in)
+			if err != nil {
+				return nil, err
+			}
+			return one(s)
+		},
+		"tonumber/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			switch x := in.(type) {
+			case float64:
+				return one(x)
+			case string:
+				f, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+				if err != nil {
+					return nil, fmt.Errorf("cannot parse %q as a number", x)
+				}
+				return one(f)
+			}
+			return nil, fmt.Errorf("%s cannot be parsed as a number", jqType(in))
+		},
+		"tojson/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			s, err := jqFormat("json", in)
+			if err != nil {
+				return nil, err
+			}
+			return one(s)
+		},
+		"fromjson/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			s, ok := in.(string)
+			if !ok {
+				return nil, fmt.Errorf("fromjson input must be a string")
+			}
+			var v interface{}
+			if err := json.Unmarshal([]byte(s), &v); err != nil {
+				return nil, err
+			}
+			return one(v)
+		},
+		"ascii_downcase/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			s, ok := in.(string)
+			if !ok {
+				return nil, fmt.Errorf("ascii_downcase input must be a string")
+			}
+			return one(strings.ToLower(s))
+		},
+		"ascii_upcase/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			s, ok := in.(string)
+			if !ok {
+				return nil, fmt.Errorf("ascii_upcase input must be a string")
+			}
+			return one(strings.ToUpper(s))
+		},
+		"ltrimstr/1": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			if _, ok := in.(string); !ok {
+				return one(in)
+			}
+			return jqStringFn("ltrimstr", func(s, arg string) interface{} {
+				return strings.TrimPrefix(s, arg)
+			})(in, args)
+		},
+		"rtrimstr/1": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			if _, ok := in.(string); !ok {
+				return one(in)
+			}
+			return jqStringFn("rtrimstr", func(s, arg string) interface{} {
+				return strings.TrimSuffix(s, arg)
+			})(in, args)
+		},
+		"startswith/1": jqStringFn("startswith", func(s, arg string) interface{} {
+			return strings.HasPrefix(s, arg)
+		}),
+		"endswith/1": jqStringFn("endswith", func(s, arg string) interface{} {
+			return strings.HasSuffix(s, arg)
+		}),
+		"split/1": jqStringFn("split", func(s, arg string) interface{} {
+			return jqSplit(s, arg)
+		}),
+		"join/1": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			elems, err := jqIdentityKeys(in, "join")
+			if err != nil {
+				return nil, err
+			}
+			seps, err := args[0].eval(in)
+			if err != nil {
+				return nil, err
+			}
+			out := []interface{}{}
+			for _, sep := range seps {
+				s, ok := sep.(string)
+				if !ok {
+					return nil, fmt.Errorf("join separator must be a string")
+				}
+				parts := []string{}
+				for _, e := range elems {
+					switch x := e.(type) {
+					case nil:
+						parts = append(parts, "")
+					case string:
+						parts = append(parts, x)
+					case float64, bool:
+						p, _ := jqToString(x)
+						parts = append(parts, p)
+					default:
+						return nil, fmt.Errorf("cannot join %s", jqType(e))
+					}
+				}
+				out = append(out, strings.Join(parts, s))
+			}
+			return out, nil
+		},
+		"test/1": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			return jqRegexp(in, args, nil, func(s string, re *regexp.Regexp, repl []interface{}) (interface{}, error) {
+				return re.MatchString(s), nil
+			})
+		},
+		"test/2": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			return jqRegexp(in, args[0:1], args[1], func(s string, re *regexp.Regexp, repl []interface{}) (interface{}, error) {
+				return re.MatchString(s), nil
+			})
+		},
+		"sub/2": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			return jqRegexp(in, args, nil, func(s string, re *regexp.Regexp, repl []interface{}) (interface{}, error) {
+				r, err := jqToString(repl[0])
+				if err != nil {
+					return nil, err
+				}
+				done := false
+				return re.ReplaceAllStringFunc(s, func(m string) string {
+					if done {
+						return m
+					}
+					done = true
+					return re.ReplaceAllString(m, r)
+				}), nil
+			})
+		},
+		"gsub/2": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			return jqRegexp(in, args, nil, func(s string, re *regexp.Regexp, repl []interface{}) (interface{}, error) {
+				r, err := jqToString(repl[0])
+				if err != nil {
+					return nil, err
+				}
+				return re.ReplaceAllString(s, r), nil
+			})
+		},
+		"contains/1": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			vals, err := args[0].eval(in)
+			if err != nil {
+				return nil, err
+			}
+			out := []interface{}{}
+			for _, v := range vals {
+				if jqRank(in) != jqRank(v) {
+					return nil, fmt.Errorf("%s and %s cannot have their containment checked", jqType(in), jqType(v))
+				}
+				out = append(out, jqContains(in, v))
+			}
+			return out, nil
+		},
+		"floor/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			f, ok := in.(float64)
+			if !ok {
+				return nil, fmt.Errorf("floor input must be a number")
+			}
+			return one(math.Floor(f))
+		},
+		"ceil/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			f, ok := in.(float64)
+			if !ok {
+				return nil, fmt.Errorf("ceil input must be a number")
+			}
+			return one(math.Ceil(f))
+		},
+		"fromdate/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			s, ok := in.(string)
+			if !ok {
+				return nil, fmt.Errorf("fromdate input must be a string")
+			}
+			t, err := parseDate(s)
+			if err != nil {
+				return nil, err
+			}
+			return one(float64(t.Unix()))
+		},
+		"todate/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			f, ok := in.(float64)
+			if !ok {
+				return nil, fmt.Errorf("todate input must be a number")
+			}
+			return one(time.Unix(int64(f), 0).UTC().Format(time.RFC3339))
+		},
+		"now/0": func(in interface{}, args []jqNode) ([]interface{}, error) {
+			return one(float64(time.Now().Unix()))
+		},
+	}
+}
+
+//
+// Parser
+//
+
+// jqParser is a recursive descent parser over the query source
+type jqParser struct {
+	src string
+	pos int
+	// base is the offset of src in the full query, for interpolations
+	base int
+}
+
+func (p *jqParser) errorf(pos int, format string, args ...interface{}) error {
+	token := ""
+	if pos < len(p.src) {
+		token = p.src[pos:]
+		if len(token) > 10 {
+			token = token[0:10]
+		}
+	}
+	return &PathError{Path: p.src, Pos: p.base + pos, Token: token, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *jqParser) skipSpace() {
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case ' ', '\t', '\r', '\n':
+			p.pos++
+		case '#':
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// peekOp reports if the source continues with op (after whitespace)
+func (p *jqParser) peekOp(op string) bool {
+	p.skipSpace()
+	if !strings.HasPrefix(p.src[p.pos:], op) {
+		return false
+	}
+	// keywords must not run into an identifier
+	if isIdentChar(op[len(op)-1]) && p.pos+len(op) < len(p.src) && isIdentChar(p.src[p.pos+len(op)]) {
+		return false
+	}
+	return true
+}
+
+func (p *jqParser) acceptOp(op string) bool {
+	if p.peekOp(op) {
+		p.pos += len(op)
+		return true
+	}
+	return false
+}
+
+func (p *jqParser) expectOp(op string) error {
+	if !p.acceptOp(op) {
+		if p.pos >= len(p.src) {
+			return p.errorf(p.pos, "expected %q, query ended", op)
+		}
+		return p.errorf(p.pos, "expected %q", op)
+	}
+	return nil
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func (p *jqParser) ident() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.src) && isIdentChar(p.src[p.pos]) {
+		if p.pos == start && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+			break
+		}
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// parsePipe parses the lowest precedence level, "|"
+func (p *jqParser) parsePipe() (jqNode, error) {
+	left, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+	if p.acceptOp("|") {
+		right, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		return &jqPipe{left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *jqParser) parseComma() (jqNode, error) {
+	left, err := p.parseAlternative()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp(",") {
+		right, err := p.parseAlternative()
+		if err != nil {
+			return nil, err
+		}
+		left = &jqComma{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *jqParser) parseAlternative() (jqNode, error) {
+	left, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.acceptOp("//") {
+		right, err := p.parseAlternative()
+		if err != nil {
+			return nil, err
+		}
+		return &jqAlternative{left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *jqParser) parseOr() (jqNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &jqBinary{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *jqParser) parseAnd() (jqNode, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp("and") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = &jqBinary{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *jqParser) parseComparison() (jqNode, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.acceptOp(op) {
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			return &jqBinary{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *jqParser) parseAdditive() (jqNode, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := ""
+		switch {
+		case p.acceptOp("+"):
+			op = "+"
+		case p.peekOp("-") && !p.peekOp("->"):
+			p.pos++
+			op = "-"
+		default:
+			return left, nil
+		}
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &jqBinary{op: op, left: left, right: right}
+	}
+}
+
+func (p *jqParser) parseMultiplicative() (jqNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := ""
+		switch {
+		case p.acceptOp("*"):
+			op = "*"
+		case p.peekOp("/") && !p.peekOp("//"):
+			p.pos++
+			op = "/"
+		case p.acceptOp("%"):
+			op = "%"
+		default:
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &jqBinary{op: op, left: left, right: right}
+	}
+}
+
+func (p *jqParser) parseUnary() (jqNode, error) {
+	if p.acceptOp("-") {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &jqNeg{expr: expr}, nil
+	}
+	return p.parsePostfix()
+}
+
+// parsePostfix parses a term followed by .name, [..] and ? suffixes
+func (p *jqParser) parsePostfix() (jqNode, error) {
+	term, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	return p.parseSuffixes(term)
+}
+
+func (p *jqParser) parseSuffixes(term jqNode) (jqNode, error) {
+	for {
+		p.skipSpace()
+		switch {
+		case p.pos+1 < len(p.src) && p.src[p.pos] == '.' && (isIdentChar(p.src[p.pos+1]) || p.src[p.pos+1] == '"'):
+			p.pos++
+			key, err := p.parseKey()
+			if err != nil {
+				return nil, err
+			}
+			term = &jqIndex{target: term, index: key}
+		case p.pos+1 < len(p.src) && p.src[p.pos] == '.' && p.src[p.pos+1] == '[':
+			p.pos++
+		case p.pos < len(p.src) && p.src[p.pos] == '[':
+			p.pos++
+			node, err := p.parseBracket(term)
+			if err != nil {
+				return nil, err
+			}
+			term = node
+		case p.pos < len(p.src) && p.src[p.pos] == '?' && !p.peekOp("?//"):
+			p.pos++
+			term = &jqTry{body: term}
+		default:
+			return term, nil
+		}
+	}
+}
+
+// parseKey parses the name following a "." in .foo or ."foo"
+func (p *jqParser) parseKey() (jqNode, error) {
+	if p.pos < len(p.src) && p.src[p.pos] == '"' {
+		return p.parseString("")
+	}
+	start := p.pos
+	name := p.ident()
+	if name == "" {
+		return nil, p.errorf(start, "expected a field name")
+	}
+	return &jqLiteral{value: name}, nil
+}
+
+// parseBracket parses the inside of [] following a term, the opening
+// bracket has been consumed
+func (p *jqParser) parseBracket(term jqNode) (jqNode, error) {
+	if p.acceptOp("]") {
+		return &jqIterate{target: term}, nil
+	}
+	if p.acceptOp(":") {
+		to, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOp("]"); err != nil {
+			return nil, err
+		}
+		return &jqSlice{target: term, to: to}, nil
+	}
+	index, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if p.acceptOp(":") {
+		var to jqNode
+		if !p.peekOp("]") {
+			if to, err = p.parsePipe(); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expectOp("]"); err != nil {
+			return nil, err
+		}
+		return &jqSlice{target: term, from: index, to: to}, nil
+	}
+	if err := p.expectOp("]"); err != nil {
+		return nil, err
+	}
+	return &jqIndex{target: term, index: index}, nil
+}
+
+// parseString parses a double quoted string with JSON escapes and
+// \(expr) interpolation, format is applied to interpolated values
+func (p *jqParser) parseString(format string) (jqNode, error) {
+	start := p.pos
+	p.pos++
+	parts := []interface{}{}
+	var sb strings.Builder
+	for {
+		if p.pos >= len(p.src) {
+			return nil, p.errorf(start, "unterminated string")
+		}
+		c := p.src[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			if sb.Len() > 0 || len(parts) == 0 {
+				parts = append(parts, sb.String())
+			}
+			if len(parts) == 1 {
+				if s, ok := parts[0].(string); ok {
+					return &jqLiteral{value: s}, nil
+				}
+			}
+			return &jqInterpolation{parts: parts, format: format}, nil
+		case c == '\\' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '(':
+			if sb.Len() > 0 {
+				parts = append(parts, sb.String())
+				sb.Reset()
+			}
+			p.pos += 2
+			inner := &jqParser{src: p.src, pos: p.pos, base: p.base}
+			node, err := inner.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			p.pos = inner.pos
+			if err := p.expectOp(")"); err != nil {
+				return nil, err
+			}
+			parts = append(parts, node)
+		case c == '\\':
+			// reuse the JSON decoder for the escape sequence
+			end := p.pos + 2
+			if p.pos+1 < len(p.src) && p.src[p.pos+1] == 'u' {
+				end = p.pos + 6
+			}
+			if end > len(p.src) {
+				return nil, p.errorf(p.pos, "bad escape")
+			}
+			var s string
+			if err := json.Unmarshal([]byte(`"`+p.src[p.pos:end]+`"`), &s); err != nil {
+				return nil, p.errorf(p.pos, "bad escape")
+			}
+			sb.WriteString(s)
+			p.pos = end
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+func (p *jqParser) parseNumber() (jqNode, error) {
+	start := p.pos
+	for p.pos < len(p.src) && strings.IndexByte("0123456789.eE", p.src[p.pos]) >= 0 {
+		if (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') && p.pos+1 < len(p.src) && (p.src[p.pos+1] == '-' || p.src[p.pos+1] == '+') {
+			p.pos++
+		}
+		p.pos++
+	}
+	f, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+	if err != nil {
+		return nil, p.errorf(start, "bad number")
+	}
+	return &jqLiteral{value: f}, nil
+}
+
+func (p *jqParser) parseTerm() (jqNode, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, p.errorf(p.pos, "expected an expression, query ended")
+	}
+	start := p.pos
+	c := p.src[p.pos]
+	switch {
+	case strings.HasPrefix(p.src[p.pos:], ".."):
+		p.pos += 2
+		return &jqRecurse{}, nil
+	case c == '.':
+		p.pos++
+		if p.pos < len(p.src) && (isIdentChar(p.src[p.pos]) || p.src[p.pos] == '"') {
+			key, err := p.parseKey()
+			if err != nil {
+				return nil, err
+			}
+			return &jqIndex{target: &jqIdentity{}, index: key}, nil
+		}
+		return &jqIdentity{}, nil
+	case c == '"':
+		return p.parseString("")
+	case c >= '0' && c <= '9':
+		return p.parseNumber()
+	case c == '(':
+		p.pos++
+		node, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case c == '[':
+		p.pos++
+		if p.acceptOp("]") {
+			return &jqArray{}, nil
+		}
+		body, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOp("]"); err != nil {
+			return nil, err
+		}
+		return &jqArray{body: body}, nil
+	case c == '{':
+		p.pos++
+		return p.parseObject()
+	case c == '@':
+		p.pos++
+		name := p.ident()
+		if _, err := jqFormat(name, []interface{}{}); err != nil && strings.HasPrefix(err.Error(), "unknown") {
+			return nil, p.errorf(start, "unknown format @%s", name)
+		}
+		p.skipSpace()
+		if p.pos < len(p.src) && p.src[p.pos] == '"' {
+			return p.parseString(name)
+		}
+		return &jqFormatNode{format: name}, nil
+	case isIdentChar(c):
+		name := p.ident()
+		switch name {
+		case "true":
+			return &jqLiteral{value: true}, nil
+		case "false":
+			return &jqLiteral{value: false}, nil
+		case "null":
+			return &jqLiteral{value: nil}, nil
+		case "if":
+			return p.parseIf()
+		case "try":
+			body, err := p.parsePostfix()
+			if err != nil {
+				return nil, err
+			}
+			node := &jqTry{body: body}
+			if p.acceptOp("catch") {
+				if node.catch, err = p.parsePostfix(); err != nil {
+					return nil, err
+				}
+			}
+			return node, nil
+		}
+		args := []jqNode{}
+		if p.acceptOp("(") {
+			for {
+				arg, err := p.parsePipe()
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+				if !p.acceptOp(";") {
+					break
+				}
+			}
+			if err := p.expectOp(")"); err != nil {
+				return nil, err
+			}
+		}
+		if _, ok := jqBuiltins[fmt.Sprintf("%s/%d", name, len(args))]; !ok {
+			return nil, p.errorf(start, "%s/%d is not defined", name, len(args))
+		}
+		return &jqCall{name: name, args: args}, nil
+	}
+	return nil, p.errorf(start, "unexpected %q", string(c))
+}
+
+func (p *jqParser) parseIf() (jqNode, error) {
+	cond, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectOp("then"); err != nil {
+		return nil, err
+	}
+	then, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	node := &jqIf{cond: cond, then: then}
+	switch {
+	case p.acceptOp("elif"):
+		if node.otherwise, err = p.parseIf(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case p.acceptOp("else"):
+		if node.otherwise, err = p.parsePipe(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expectOp("end"); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (p *jqParser) parseObject() (jqNode, error) {
+	node := &jqObject{}
+	if p.acceptOp("}") {
+		return node, nil
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, p.errorf(p.pos, "expected an object key, query ended")
+		}
+		var (
+			key   jqNode
+			value jqNode
+			err   error
+		)
+		start := p.pos
+		switch c := p.src[p.pos]; {
+		case c == '"':
+			if key, err = p.parseString(""); err != nil {
+				return nil, err
+			}
+		case c == '(':
+			p.pos++
+			if key, err = p.parsePipe(); err != nil {
+				return nil, err
+			}
+			if err := p.expectOp(")"); err != nil {
+				return nil, err
+			}
+		case isIdentChar(c):
+			key = &jqLiteral{value: p.ident()}
+		default:
+			return nil, p.errorf(start, "expected an object key")
+		}
+		if p.acceptOp(":") {
+			// values bind tighter than ","
+			if value, err = p.parseAlternative(); err != nil {
+				return nil, err
+			}
+		} else if lit, ok := key.(*jqLiteral); ok {
+			// {title} is short for {title: .title}
+			value = &jqIndex{target: &jqIdentity{}, index: lit}
+		} else {
+			return nil, p.errorf(p.pos, "expected \":\"")
+		}
+		node.entries = append(node.entries, jqObjectEntry{key: key, value: value})
+		if p.acceptOp("}") {
+			return node, nil
+		}
+		if err := p.expectOp(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Query is a compiled jq expression, see CompileQuery
+type Query struct {
+	src  string
+	root jqNode
+}
+
+// CompileQuery parses a jq expression
+func CompileQuery(src string) (*Query, error) {
+	p := &jqParser{src: src}
+	root, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return nil, p.errorf(p.pos, "unexpected %q", p.src[p.pos:p.pos+1])
+	}
+	return &Query{src: src, root: root}, nil
+}
+
+// String returns the source of the query
+func (q *Query) String() string {
+	return q.src
+}
+
+// Eval runs the query against data, a value decoded by encoding/json
+// (maps, slices, strings, float64, bool and nil), returning every
+// value produced.
+func (q *Query) Eval(data interface{}) ([]interface{}, error) {
+	return q.root.eval(data)
+}
+
+// toJSONModel converts r to the generic JSON value rss2json produces
+func (r *RSS2) toJSONModel() (interface{}, error) {
+	src, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	var data interface{}
+	if err := json.Unmarshal(src, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Query evaluates a jq expression against the JSON form of r, e.g.
+// r.Query(`.item[] | select(.category | contains(["Chemistry"])) | .link`)
+func (r *RSS2) Query(expr string) ([]interface{}, error) {
+	q, err := CompileQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+	data, err := r.toJSONModel()
+	if err != nil {
+		return nil, err
+	}
+	return q.Eval(data)
+}
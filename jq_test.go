@@ -0,0 +1,156 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	r, err := Parse(pathTestSrc)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	tests := map[string]string{
+		`.title`:                 `["Library News"]`,
+		`.item[] | .title`:       `["New chemistry database","Holiday hours"]`,
+		`.item | length`:         `[2]`,
+		`[.item[].guid]`:         `[["news-1","news-2"]]`,
+		`.item[-1].guid`:         `["news-2"]`,
+		`.item[:1] | map(.guid)`: `[["news-1"]]`,
+		`.item[] | select(.category | contains(["Hours"])) | .link`:                `["https://library.example.edu/news/2"]`,
+		`.item | sort_by(.title) | map(.guid)`:                                     `[["news-2","news-1"]]`,
+		`.item[0].category | join(", ")`:                                           `["Chemistry, Databases"]`,
+		`.item[0].title | ascii_upcase | split(" ")`:                               `[["NEW","CHEMISTRY","DATABASE"]]`,
+		`.item[] | .description // "none"`:                                         `["none","Closed on Monday"]`,
+		`.item[0] | {guid, len: (.title | length)}`:                                `[{"guid":"news-1","len":22}]`,
+		`.item[0] | "\(.guid): \(.title)"`:                                         `["news-1: New chemistry database"]`,
+		`.item[0].pubDate | fromdate | todate`:                                     `["2016-07-26T03:48:03Z"]`,
+		`.item[] | if has("enclosure") then .enclosure.type else empty end`:        `["audio/mpeg"]`,
+		`[.item[].category[]] | unique | length`:                                   `[3]`,
+		`.item[0].title | test("^New") and startswith("New")`:                      `[true]`,
+		`.item[1].title | sub("o"; "0"), gsub("o"; "0")`:                           `["H0liday hours","H0liday h0urs"]`,
+		`1 + 2 * 3, 7 % 4, "a" + "b", [1,2,3] - [2]`:                               `[7,3,"ab",[1,3]]`,
+		`.item[0].title | ltrimstr("New ") | rtrimstr(" database")`:                `["chemistry"]`,
+		`[.item[] | .guid] | @csv`:                                                 `["\"news-1\",\"news-2\""]`,
+		`try (.title | keys) catch "bad"`:                                          `["bad"]`,
+		`.missing.deeper`:                                                          `[null]`,
+		`[limit(1; .item[])] | length, ([range(3)] | add)`:                         `[1,3]`,
+		`.item | group_by(.author) | map(length)`:                                  `[[1,1]]`,
+		`.item | max_by(.guid) | .guid`:                                            `["news-2"]`,
+		`.item | any(.title == "Holiday hours"), all(has("guid"))`:                 `[true,true]`,
+		`any(.item[]; .author == "nobody"), all(.item[]; .link | test("^https:"))`: `[false,true]`,
+		`.item[0].title | test("^new"), test("^new"; "i")`:                         `[false,true]`,
+	}
+	for expr, expected := range tests {
+		results, err := r.Query(expr)
+		if err != nil {
+			t.Errorf("%s: %s", expr, err)
+			continue
+		}
+		src, _ := json.Marshal(results)
+		if string(src) != expected {
+			t.Errorf("%s: expected %s, got %s", expr, expected, src)
+		}
+	}
+	if _, err := r.Query(`.title | test("news"; "x")`); err == nil {
+		t.Errorf("expected an error for the unsupported x flag")
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	tests := map[string]int{
+		`.item[] |`:          9,
+		`.item[0`:            7,
+		`nosuchfn(.)`:        0,
+		`select(.a; .b)`:     0,
+		`sub("a"; "b"; "g")`: 0,
+		`{title: .title`:     14,
+		`"unterminated`:      0,
+		`.item[] | @xml`:     10,
+		`if . then 1 end2`:   12,
+	}
+	for src, pos := range tests {
+		_, err := CompileQuery(src)
+		if err == nil {
+			t.Errorf("%s: expected an error", src)
+			continue
+		}
+		perr, ok := err.(*PathError)
+		if !ok {
+			t.Errorf("%s: expected a *PathError, got %T %s", src, err, err)
+			continue
+		}
+		if perr.Pos != pos {
+			t.Errorf("%s: expected error at %d, got %d, %s", src, pos, perr.Pos, err)
+		}
+	}
+
+	r, _ := Parse(pathTestSrc)
+	for _, expr := range []string{`.item.title`, `.item[0].title | .[0]`, `.title | keys`} {
+		if _, err := r.Query(expr); err == nil {
+			t.Errorf("%s: expected an evaluation error", expr)
+		}
+	}
+	if results, err := r.Query(`.item.title?`); err != nil || len(results) != 0 {
+		t.Errorf(".item.title?: expected no results, got %v, %s", results, err)
+	}
+}
+
+func TestQueryJSONFeed(t *testing.T) {
+	src, err := ioutil.ReadFile(path.Join("testdata", "rsdoiel.xml"))
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	r, err := Parse(src)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	// Queries run against the JSON model, so the same expression works
+	// for a JSON Feed once it is decoded
+	src, err = r.ToJSONFeed()
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	var data interface{}
+	if err := json.Unmarshal(src, &data); err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	q, err := CompileQuery(`.items | map(.url) | length`)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	results, err := q.Eval(data)
+	if err != nil || len(results) != 1 || results[0] != float64(len(r.ItemList)) {
+		t.Errorf("expected %d items, got %v, %s", len(r.ItemList), results, err)
+	}
+	results, err = r.Query(`.item | sort_by(.pubDate | fromdate) | last | .title`)
+	if err != nil || len(results) != 1 {
+		t.Errorf("expected the newest title, got %v, %s", results, err)
+	}
+}
@@ -0,0 +1,247 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONFeedVersion is the version URL for JSON Feed 1.1
+const JSONFeedVersion = "https://jsonfeed.org/version/1.1"
+
+// JSONFeed is a JSON Feed 1.1 document, see https://jsonfeed.org/version/1.1
+type JSONFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url,omitempty"`
+	FeedURL     string           `json:"feed_url,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Icon        string           `json:"icon,omitempty"`
+	Authors     []JSONFeedAuthor `json:"authors,omitempty"`
+	Language    string           `json:"language,omitempty"`
+	Items       []JSONFeedItem   `json:"items"`
+}
+
+// JSONFeedItem is a single item in a JSON Feed
+type JSONFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url,omitempty"`
+	ExternalURL   string               `json:"external_url,omitempty"`
+	Title         string               `json:"title,omitempty"`
+	ContentHTML   string               `json:"content_html,omitempty"`
+	ContentText   string               `json:"content_text,omitempty"`
+	Summary       string               `json:"summary,omitempty"`
+	Image         string               `json:"image,omitempty"`
+	DatePublished string               `json:"date_published,omitempty"`
+	DateModified  string               `json:"date_modified,omitempty"`
+	Authors       []JSONFeedAuthor     `json:"authors,omitempty"`
+	Tags          []string             `json:"tags,omitempty"`
+	Language      string               `json:"language,omitempty"`
+	Attachments   []JSONFeedAttachment `json:"attachments,omitempty"`
+}
+
+// JSONFeedAuthor is a JSON Feed author object
+type JSONFeedAuthor struct {
+	Name   string `json:"name,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Avatar string `json:"avatar,omitempty"`
+}
+
+// JSONFeedAttachment is a JSON Feed attachment, the equivalent of an
+// RSS enclosure.
+type JSONFeedAttachment struct {
+	URL         string `json:"url"`
+	MimeType    string `json:"mime_type"`
+	Title       string `json:"title,omitempty"`
+	SizeInBytes int64  `json:"size_in_bytes,omitempty"`
+}
+
+// jsonFeedAuthor converts an RSS author value into a JSON Feed author.
+// JSON Feed has no email field so an address becomes a mailto URL.
+func jsonFeedAuthor(s string) JSONFeedAuthor {
+	p := atomPerson(s)
+	author := JSONFeedAuthor{Name: p.Name}
+	if p.Email != "" {
+		author.URL = "mailto:" + p.Email
+		if p.Name == p.Email {
+			author.Name = ""
+		}
+	}
+	return author
+}
+
+// rssAuthor converts a JSON Feed author back into an RSS author value
+func rssAuthor(author JSONFeedAuthor) string {
+	email := strings.TrimPrefix(author.URL, "mailto:")
+	switch {
+	case email != author.URL && author.Name != "":
+		return fmt.Sprintf("%s (%s)", email, author.Name)
+	case email != author.URL:
+		return email
+	}
+	return author.Name
+}
+
+// NewJSONFeed renders an RSS2 value as a JSON Feed 1.1 document. Items
+// without a GUID or link are given a synthesized id, see NewAtom.
+func NewJSONFeed(r *RSS2) *JSONFeed {
+	feed := new(JSONFeed)
+	feed.Version = JSONFeedVersion
+	feed.Title = strings.TrimSpace(r.Title)
+	feed.HomePageURL = strings.TrimSpace(r.Link)
+	feed.Description = strings.TrimSpace(r.Description)
+	feed.Language = r.Language
+	if r.ManagingEditor != "" {
+		feed.Authors = append(feed.Authors, jsonFeedAuthor(r.ManagingEditor))
+	}
+	feed.Items = []JSONFeedItem{}
+	for _, item := range r.ItemList {
+		entry := JSONFeedItem{}
+		entry.ID = r.itemID(item)
+		entry.URL = strings.TrimSpace(item.Link)
+		entry.Title = strings.TrimSpace(item.Title)
+		if item.Content != "" {
+			entry.ContentHTML = item.Content
+			entry.Summary = strings.TrimSpace(item.Description)
+		} else {
+			entry.ContentHTML = item.Description
+		}
+		if published, ok := atomDate(item.PubDate); ok {
+			entry.DatePublished = published
+		}
+		if item.Author != "" {
+			entry.Authors = append(entry.Authors, jsonFeedAuthor(item.Author))
+		}
+		for _, category := range item.Category {
+			if strings.TrimSpace(category) != "" {
+				entry.Tags = append(entry.Tags, strings.TrimSpace(category))
+			}
+		}
+		if item.Enclosure != nil && item.Enclosure.URL != "" {
+			attachment := JSONFeedAttachment{
+				URL:      item.Enclosure.URL,
+				MimeType: item.Enclosure.Type,
+			}
+			if attachment.MimeType == "" {
+				attachment.MimeType = "application/octet-stream"
+			}
+			if size, err := strconv.ParseInt(item.Enclosure.Length, 10, 64); err == nil {
+				attachment.SizeInBytes = size
+			}
+			entry.Attachments = append(entry.Attachments, attachment)
+		}
+		feed.Items = append(feed.Items, entry)
+	}
+	return feed
+}
+
+// ToJSONFeed returns the RSS2 document rendered as JSON Feed 1.1
+func (r *RSS2) ToJSONFeed() ([]byte, error) {
+	return json.Marshal(NewJSONFeed(r))
+}
+
+// ToRSS2 converts a JSON Feed into an RSS2 value. Dates are rewritten
+// in RFC 1123 form, the first author is used when an item has several.
+func (feed *JSONFeed) ToRSS2() *RSS2 {
+	r := new(RSS2)
+	r.Version = "2.0"
+	r.Title = feed.Title
+	r.Link = feed.HomePageURL
+	r.Description = feed.Description
+	r.Language = feed.Language
+	if len(feed.Authors) > 0 {
+		r.ManagingEditor = rssAuthor(feed.Authors[0])
+	}
+	for _, entry := range feed.Items {
+		item := Item{}
+		item.GUID = entry.ID
+		item.Link = entry.URL
+		if item.Link == "" {
+			item.Link = entry.ExternalURL
+		}
+		item.Title = entry.Title
+		switch {
+		case entry.ContentHTML != "" && entry.Summary != "":
+			item.Description = entry.Summary
+			item.Content = entry.ContentHTML
+		case entry.ContentHTML != "":
+			item.Description = entry.ContentHTML
+		case entry.Summary != "":
+			item.Description = entry.Summary
+			item.Content = entry.ContentText
+		default:
+			item.Description = entry.ContentText
+		}
+		if t, err := time.Parse(time.RFC3339, entry.DatePublished); err == nil {
+			item.PubDate = t.Format(time.RFC1123Z)
+		}
+		if len(entry.Authors) > 0 {
+			item.Author = rssAuthor(entry.Authors[0])
+		} else if len(feed.Authors) > 0 {
+			item.Author = rssAuthor(feed.Authors[0])
+		}
+		item.Category = append(item.Category, entry.Tags...)
+		if len(entry.Attachments) > 0 {
+			attachment := entry.Attachments[0]
+			item.Enclosure = &Enclosure{
+				URL:  attachment.URL,
+				Type: attachment.MimeType,
+			}
+			if attachment.SizeInBytes > 0 {
+				item.Enclosure.Length = fmt.Sprintf("%d", attachment.SizeInBytes)
+			}
+		}
+		r.ItemList = append(r.ItemList, item)
+	}
+	return r
+}
+
+// ParseJSONFeed reads a JSON Feed (1.0 or 1.1) document and returns it
+// as an RSS2 structure.
+func ParseJSONFeed(buf []byte) (*RSS2, error) {
+	feed := new(JSONFeed)
+	if err := json.Unmarshal(buf, &feed); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(feed.Version, "https://jsonfeed.org/version/") {
+		return nil, fmt.Errorf("not a JSON Feed, version %q", feed.Version)
+	}
+	// JSON Feed 1.0 used a single author object
+	legacy := struct {
+		Author *JSONFeedAuthor `json:"author,omitempty"`
+		Items  []struct {
+			Author *JSONFeedAuthor `json:"author,omitempty"`
+		} `json:"items"`
+	}{}
+	if err := json.Unmarshal(buf, &legacy); err == nil {
+		if len(feed.Authors) == 0 && legacy.Author != nil {
+			feed.Authors = append(feed.Authors, *legacy.Author)
+		}
+		for i, item := range legacy.Items {
+			if i < len(feed.Items) && len(feed.Items[i].Authors) == 0 && item.Author != nil {
+				feed.Items[i].Authors = append(feed.Items[i].Authors, *item.Author)
+			}
+		}
+	}
+	return feed.ToRSS2(), nil
+}
@@ -0,0 +1,152 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONFeed(t *testing.T) {
+	src := []byte(`<rss version="2.0"><channel>
+<title>Podcast</title>
+<link>https://example.edu/podcast/</link>
+<description>Episodes</description>
+<managingEditor>jane@example.edu (Jane Doe)</managingEditor>
+<item>
+  <title>Episode 1</title>
+  <link>https://example.edu/podcast/1</link>
+  <guid>tag:example.edu,2020:1</guid>
+  <description>Show notes</description>
+  <category>Chemistry</category>
+  <category>Biology</category>
+  <pubDate>Mon, 25 Jul 2016 20:48:03 -0700</pubDate>
+  <enclosure url="https://example.edu/podcast/1.mp3" length="12345" type="audio/mpeg"/>
+</item>
+</channel></rss>`)
+	r, err := Parse(src)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	out, err := r.ToJSONFeed()
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	feed := new(JSONFeed)
+	if err := json.Unmarshal(out, &feed); err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if feed.Version != JSONFeedVersion {
+		t.Errorf("expected version %q, got %q", JSONFeedVersion, feed.Version)
+	}
+	if len(feed.Items) != 1 {
+		t.Errorf("expected one item, got %d", len(feed.Items))
+		t.FailNow()
+	}
+	item := feed.Items[0]
+	if item.ID != "tag:example.edu,2020:1" {
+		t.Errorf("expected guid as id, got %q", item.ID)
+	}
+	if item.ContentHTML != "Show notes" {
+		t.Errorf("expected content_html from description, got %q", item.ContentHTML)
+	}
+	if item.DatePublished != "2016-07-25T20:48:03-07:00" {
+		t.Errorf("expected RFC 3339 date_published, got %q", item.DatePublished)
+	}
+	if len(item.Tags) != 2 || item.Tags[1] != "Biology" {
+		t.Errorf("expected two tags, got %+v", item.Tags)
+	}
+	if len(item.Attachments) != 1 || item.Attachments[0].SizeInBytes != 12345 || item.Attachments[0].MimeType != "audio/mpeg" {
+		t.Errorf("expected mp3 attachment, got %+v", item.Attachments)
+	}
+	if len(feed.Authors) != 1 || feed.Authors[0].Name != "Jane Doe" || feed.Authors[0].URL != "mailto:jane@example.edu" {
+		t.Errorf("expected Jane Doe author, got %+v", feed.Authors)
+	}
+
+	// Round trip back to RSS
+	r2, err := ParseJSONFeed(out)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if r2.Title != r.Title || r2.Link != r.Link || r2.ManagingEditor != r.ManagingEditor {
+		t.Errorf("expected channel to round trip, got %+v", r2)
+	}
+	item2 := r2.ItemList[0]
+	if item2.GUID != r.ItemList[0].GUID || item2.Link != r.ItemList[0].Link {
+		t.Errorf("expected guid and link to round trip, got %+v", item2)
+	}
+	if item2.PubDate != r.ItemList[0].PubDate {
+		t.Errorf("expected pubDate %q, got %q", r.ItemList[0].PubDate, item2.PubDate)
+	}
+	if item2.Enclosure == nil || *item2.Enclosure != *r.ItemList[0].Enclosure {
+		t.Errorf("expected enclosure to round trip, got %+v", item2.Enclosure)
+	}
+}
+
+func TestParseJSONFeed(t *testing.T) {
+	src := []byte(`{
+    "version": "https://jsonfeed.org/version/1",
+    "title": "My Example Feed",
+    "home_page_url": "https://example.org/",
+    "author": {"name": "John Doe"},
+    "items": [
+        {
+            "id": "2",
+            "content_text": "This is a second item.",
+            "url": "https://example.org/second-item"
+        },
+        {
+            "id": "1",
+            "summary": "First",
+            "content_html": "<p>Hello, world!</p>",
+            "url": "https://example.org/initial-post",
+            "date_published": "2010-02-07T14:04:00-05:00"
+        }
+    ]
+}`)
+	r, err := ParseJSONFeed(src)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if r.Title != "My Example Feed" || r.Link != "https://example.org/" {
+		t.Errorf("unexpected channel %+v", r)
+	}
+	if len(r.ItemList) != 2 {
+		t.Errorf("expected 2 items, got %d", len(r.ItemList))
+		t.FailNow()
+	}
+	if r.ItemList[0].Description != "This is a second item." || r.ItemList[0].Author != "John Doe" {
+		t.Errorf("unexpected first item %+v", r.ItemList[0])
+	}
+	if r.ItemList[1].Description != "First" || r.ItemList[1].Content != "<p>Hello, world!</p>" {
+		t.Errorf("unexpected second item %+v", r.ItemList[1])
+	}
+	if r.ItemList[1].PubDate != "Sun, 07 Feb 2010 14:04:00 -0500" {
+		t.Errorf("unexpected pubDate %q", r.ItemList[1].PubDate)
+	}
+
+	if _, err := ParseJSONFeed([]byte(`{"title": "not a feed"}`)); err == nil {
+		t.Errorf("expected an error for a document without a JSON Feed version")
+	}
+}
@@ -0,0 +1,220 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// ParseOptions limit the resources used parsing a feed so one from an
+// untrusted source can't exhaust memory. A zero limit means no limit.
+//
+// encoding/xml only expands the five predefined entities and
+// character references, a DOCTYPE's entity declarations are ignored
+// and undeclared entities are syntax errors, so entity expansion
+// attacks such as "billion laughs" don't apply. Together MaxBytes
+// and MaxFieldLength bound the text decoded.
+type ParseOptions struct {
+	// MaxBytes is the size of the document in bytes
+	MaxBytes int64
+	// MaxItems is the number of items in the channel
+	MaxItems int
+	// MaxDepth is how deeply elements nest, the rss element is at
+	// depth 1, an item at 3
+	MaxDepth int
+	// MaxFieldLength is the number of characters in the text of an
+	// element or the value of an attribute
+	MaxFieldLength int
+}
+
+// DefaultParseOptions are limits suitable for harvesting feeds from
+// untrusted sources
+var DefaultParseOptions = ParseOptions{
+	MaxBytes:       16 << 20,
+	MaxItems:       10000,
+	MaxDepth:       32,
+	MaxFieldLength: 1 << 20,
+}
+
+// The limit errors wrapped by *LimitError, test for them with
+// errors.Is, e.g. errors.Is(err, rss2.ErrTooManyItems)
+var (
+	ErrTooLarge     = errors.New("feed is too large")
+	ErrTooManyItems = errors.New("feed has too many items")
+	ErrTooDeep      = errors.New("feed elements are nested too deeply")
+	ErrFieldTooLong = errors.New("feed field is too long")
+)
+
+// LimitError is returned when a feed exceeds one of its ParseOptions
+type LimitError struct {
+	// Err is ErrTooLarge, ErrTooManyItems, ErrTooDeep or
+	// ErrFieldTooLong
+	Err error
+	// Limit is the limit exceeded
+	Limit int64
+	// Offset is where in the document it was exceeded
+	Offset int64
+	// Element is the name of the element being decoded, if any
+	Element string
+}
+
+func (e *LimitError) Error() string {
+	if e.Element == "" {
+		return fmt.Sprintf("%s, the limit is %d, at offset %d", e.Err, e.Limit, e.Offset)
+	}
+	return fmt.Sprintf("%s, the limit is %d, at offset %d in <%s>", e.Err, e.Limit, e.Offset, e.Element)
+}
+
+// Unwrap returns the limit error, e.g. ErrTooManyItems
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
+
+// limitedReader returns ErrTooLarge once more than max bytes are read
+type limitedReader struct {
+	in   io.Reader
+	max  int64
+	read int64
+}
+
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	if lr.read > lr.max {
+		return 0, &LimitError{Err: ErrTooLarge, Limit: lr.max, Offset: lr.max}
+	}
+	// read one byte past the limit to tell a document of exactly
+	// max bytes from a larger one
+	if rest := lr.max - lr.read + 1; int64(len(p)) > rest {
+		p = p[0:rest]
+	}
+	n, err := lr.in.Read(p)
+	lr.read += int64(n)
+	if lr.read > lr.max {
+		return n - int(lr.read-lr.max), &LimitError{Err: ErrTooLarge, Limit: lr.max, Offset: lr.max}
+	}
+	return n, err
+}
+
+// limitedTokens checks each token from decoder against the options
+// before passing it on to Unmarshal
+type limitedTokens struct {
+	decoder *xml.Decoder
+	reader  *limitedReader
+	opts    *ParseOptions
+	// names of the open elements and the characters of text each
+	// has so far
+	names  []string
+	counts []int
+	items  int
+}
+
+func (lt *limitedTokens) fail(err error, limit int) error {
+	e := &LimitError{Err: err, Limit: int64(limit), Offset: lt.decoder.InputOffset()}
+	if len(lt.names) > 0 {
+		e.Element = lt.names[len(lt.names)-1]
+	}
+	return e
+}
+
+func (lt *limitedTokens) Token() (xml.Token, error) {
+	token, err := lt.decoder.Token()
+	// the decoder returns the text read before an error from the
+	// reader, report the size first
+	if lt.reader != nil && lt.reader.read > lt.reader.max {
+		return nil, &LimitError{Err: ErrTooLarge, Limit: lt.reader.max, Offset: lt.reader.max}
+	}
+	if err != nil {
+		return token, err
+	}
+	opts := lt.opts
+	switch t := token.(type) {
+	case xml.StartElement:
+		depth := len(lt.names) + 1
+		if opts.MaxDepth > 0 && depth > opts.MaxDepth {
+			return nil, lt.fail(ErrTooDeep, opts.MaxDepth)
+		}
+		if depth == 3 && t.Name.Local == "item" && lt.names[1] == "channel" {
+			lt.items++
+			if opts.MaxItems > 0 && lt.items > opts.MaxItems {
+				return nil, lt.fail(ErrTooManyItems, opts.MaxItems)
+			}
+		}
+		lt.names = append(lt.names, t.Name.Local)
+		lt.counts = append(lt.counts, 0)
+		if opts.MaxFieldLength > 0 {
+			for _, attr := range t.Attr {
+				if utf8.RuneCountInString(attr.Value) > opts.MaxFieldLength {
+					return nil, lt.fail(ErrFieldTooLong, opts.MaxFieldLength)
+				}
+			}
+		}
+	case xml.EndElement:
+		if len(lt.names) > 0 {
+			lt.names = lt.names[0 : len(lt.names)-1]
+			lt.counts = lt.counts[0 : len(lt.counts)-1]
+		}
+	case xml.CharData:
+		// text may arrive in several tokens, e.g. around CDATA
+		// sections, indentation between elements isn't counted
+		if opts.MaxFieldLength > 0 && len(lt.counts) > 0 && len(bytes.TrimSpace(t)) > 0 {
+			i := len(lt.counts) - 1
+			lt.counts[i] += utf8.RuneCount(t)
+			if lt.counts[i] > opts.MaxFieldLength {
+				return nil, lt.fail(ErrFieldTooLong, opts.MaxFieldLength)
+			}
+		}
+	}
+	return token, nil
+}
+
+// ParseReader reads an RSS 2 document from in enforcing the limits in
+// opts as it is decoded, nil uses DefaultParseOptions. A document
+// exceeding a limit returns a *LimitError.
+func ParseReader(in io.Reader, opts *ParseOptions) (*RSS2, error) {
+	if opts == nil {
+		opts = &DefaultParseOptions
+	}
+	lt := &limitedTokens{opts: opts}
+	if opts.MaxBytes > 0 {
+		lt.reader = &limitedReader{in: in, max: opts.MaxBytes}
+		in = lt.reader
+	}
+	lt.decoder = xml.NewDecoder(in)
+	data := new(RSS2)
+	if err := xml.NewTokenDecoder(lt).Decode(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// ParseWithOptions parses an RSS 2 document like Parse enforcing the
+// limits in opts, nil uses DefaultParseOptions
+func ParseWithOptions(buf []byte, opts *ParseOptions) (*RSS2, error) {
+	if opts == nil {
+		opts = &DefaultParseOptions
+	}
+	if opts.MaxBytes > 0 && int64(len(buf)) > opts.MaxBytes {
+		return nil, &LimitError{Err: ErrTooLarge, Limit: opts.MaxBytes, Offset: opts.MaxBytes}
+	}
+	return ParseReader(bytes.NewReader(buf), opts)
+}
@@ -0,0 +1,107 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestParseWithOptions(t *testing.T) {
+	rsdoiel, err := ioutil.ReadFile(path.Join("testdata", "rsdoiel.xml"))
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	// within the limits the result is the same as Parse
+	for _, src := range [][]byte{pathTestSrc, rsdoiel} {
+		expected, err := Parse(src)
+		if err != nil {
+			t.Errorf("%s", err)
+			t.FailNow()
+		}
+		r, err := ParseWithOptions(src, nil)
+		if err != nil {
+			t.Errorf("%s", err)
+			continue
+		}
+		a, _ := json.Marshal(expected)
+		b, _ := json.Marshal(r)
+		if !bytes.Equal(a, b) {
+			t.Errorf("expected\n%s\ngot\n%s", a, b)
+		}
+	}
+
+	tests := []struct {
+		opts     ParseOptions
+		src      []byte
+		expected error
+		element  string
+	}{
+		{ParseOptions{MaxBytes: int64(len(rsdoiel))}, rsdoiel, nil, ""},
+		{ParseOptions{MaxBytes: int64(len(rsdoiel)) - 10}, rsdoiel, ErrTooLarge, ""},
+		{ParseOptions{MaxItems: 10}, rsdoiel, nil, ""},
+		{ParseOptions{MaxItems: 9}, rsdoiel, ErrTooManyItems, "channel"},
+		{ParseOptions{MaxDepth: 4}, pathTestSrc, ErrTooDeep, "content"},
+		{ParseOptions{MaxDepth: 5}, pathTestSrc, nil, ""},
+		{ParseOptions{MaxFieldLength: 38}, pathTestSrc, nil, ""},
+		{ParseOptions{MaxFieldLength: 37}, pathTestSrc, ErrFieldTooLong, "enclosure"},
+		{ParseOptions{MaxFieldLength: 3}, []byte(`<rss><channel><description>abcd</description></channel></rss>`), ErrFieldTooLong, "description"},
+		{ParseOptions{MaxFieldLength: 3}, []byte(`<rss><channel><title>été</title></channel></rss>`), nil, ""},
+		{ParseOptions{MaxFieldLength: 3}, []byte(`<rss><channel><title>ab<![CDATA[cd]]></title></channel></rss>`), ErrFieldTooLong, "title"},
+		{ParseOptions{MaxFieldLength: 3}, []byte(`<rss version="2.0.0"><channel></channel></rss>`), ErrFieldTooLong, "rss"},
+	}
+	for i, test := range tests {
+		_, err := ParseWithOptions(test.src, &test.opts)
+		if test.expected == nil {
+			if err != nil {
+				t.Errorf("%d: %s", i, err)
+			}
+			continue
+		}
+		if !errors.Is(err, test.expected) {
+			t.Errorf("%d: expected %s, got %v", i, test.expected, err)
+			continue
+		}
+		if lerr, ok := err.(*LimitError); !ok || lerr.Element != test.element {
+			t.Errorf("%d: expected a *LimitError in <%s>, got %#v", i, test.element, err)
+		}
+		// the same limits apply reading a stream
+		if _, err := ParseReader(bytes.NewReader(test.src), &test.opts); !errors.Is(err, test.expected) {
+			t.Errorf("%d: ParseReader expected %s, got %v", i, test.expected, err)
+		}
+	}
+
+	// a hostile document is rejected without reading all of it
+	huge := new(bytes.Buffer)
+	huge.WriteString(`<rss version="2.0"><channel><title>`)
+	for i := 0; i < 1<<16; i++ {
+		fmt.Fprintf(huge, "%s", strings.Repeat("x", 1024))
+	}
+	_, err = ParseReader(huge, nil)
+	if !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+}
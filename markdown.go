@@ -0,0 +1,199 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// MarkdownList writes a bullet per item
+	MarkdownList = "list"
+	// MarkdownSections writes a heading per item
+	MarkdownSections = "sections"
+)
+
+// MarkdownOptions controls the layout of ToMarkdown. A nil
+// *MarkdownOptions is a list without grouping and 280 character
+// excerpts with dates in US English.
+type MarkdownOptions struct {
+	// Layout is MarkdownList or MarkdownSections
+	Layout string
+	// GroupBy puts items under date headings, "day", "week",
+	// "month" or "year", items without a date are grouped last
+	GroupBy string
+	// Excerpt is the length of the plain text excerpt of each
+	// description, 0 uses 280 and a negative value leaves it out
+	Excerpt int
+	// Locale formats dates, e.g. "en-US" or "fr"
+	Locale string
+}
+
+// mdEscaper escapes characters with a meaning in inline Markdown
+var mdEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`,
+	`[`, `\[`, `]`, `\]`, `<`, `\<`, `>`, `\>`,
+)
+
+// mdText escapes s for use in a line of Markdown
+func mdText(s string) string {
+	s = mdEscaper.Replace(strings.Join(strings.Fields(s), " "))
+	// a leading "#" would start a heading
+	if strings.HasPrefix(s, "#") {
+		s = `\` + s
+	}
+	return s
+}
+
+// mdLink returns a Markdown link, or the escaped text without a URL
+func mdLink(text, url string) string {
+	text = mdText(text)
+	if text == "" {
+		text = mdText(url)
+	}
+	if url == "" {
+		return text
+	}
+	if strings.ContainsAny(url, " ()<>") {
+		url = "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(url) + ">"
+	}
+	return "[" + text + "](" + url + ")"
+}
+
+// dateGroup returns the heading of the group t belongs to
+func dateGroup(groupBy string, t time.Time, locale string) (string, error) {
+	switch groupBy {
+	case "day":
+		return localDate(locale, t), nil
+	case "week":
+		// weeks start on Monday
+		offset := (int(t.Weekday()) + 6) % 7
+		monday := t.AddDate(0, 0, -offset)
+		return "Week of " + localDate(locale, monday), nil
+	case "month":
+		loc := lookupLocale(locale)
+		if loc.months == nil {
+			return fmt.Sprintf("%d-%02d", t.Year(), t.Month()), nil
+		}
+		return fmt.Sprintf("%s %d", loc.months[t.Month()-1], t.Year()), nil
+	case "year":
+		return fmt.Sprintf("%d", t.Year()), nil
+	}
+	return "", fmt.Errorf("unknown date grouping %q, expected day, week, month or year", groupBy)
+}
+
+// ToMarkdown renders r as Markdown, a heading for the channel
+// followed by each item's link, date and a plain text excerpt of its
+// description.
+func (r *RSS2) ToMarkdown(opts *MarkdownOptions) ([]byte, error) {
+	if opts == nil {
+		opts = new(MarkdownOptions)
+	}
+	layout, excerpt, locale := opts.Layout, opts.Excerpt, opts.Locale
+	if layout == "" {
+		layout = MarkdownList
+	}
+	if layout != MarkdownList && layout != MarkdownSections {
+		return nil, fmt.Errorf("unknown layout %q, expected %s or %s", layout, MarkdownList, MarkdownSections)
+	}
+	if excerpt == 0 {
+		excerpt = 280
+	}
+	if locale == "" {
+		locale = "en-US"
+	}
+
+	// group the items keeping the order groups first appear in
+	groups, items := []string{""}, map[string][]Item{}
+	if opts.GroupBy != "" {
+		groups = []string{}
+		undated := []Item{}
+		for _, item := range r.ItemList {
+			t, err := parseDate(item.PubDate)
+			if err != nil {
+				undated = append(undated, item)
+				continue
+			}
+			heading, err := dateGroup(opts.GroupBy, t, locale)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := items[heading]; !ok {
+				groups = append(groups, heading)
+			}
+			items[heading] = append(items[heading], item)
+		}
+		if len(undated) > 0 {
+			groups = append(groups, "Undated")
+			items["Undated"] = append(items["Undated"], undated...)
+		}
+	} else {
+		items[""] = r.ItemList
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "# %s\n\n", mdLink(r.Title, r.Link))
+	if s := mdText(stripHTML(r.Description)); s != "" {
+		fmt.Fprintf(buf, "%s\n\n", s)
+	}
+	itemHeading := "##"
+	if opts.GroupBy != "" {
+		itemHeading = "###"
+	}
+	for _, group := range groups {
+		if group != "" {
+			fmt.Fprintf(buf, "## %s\n\n", mdText(group))
+		}
+		for _, item := range items[group] {
+			date := ""
+			if item.PubDate != "" {
+				date = LocalDate(locale, item.PubDate)
+			}
+			text := ""
+			if excerpt > 0 {
+				text = mdText(Truncate(excerpt, stripHTML(item.Description)))
+			}
+			if layout == MarkdownList {
+				fmt.Fprintf(buf, "- %s", mdLink(item.Title, item.Link))
+				if date != "" {
+					fmt.Fprintf(buf, " (%s)", mdText(date))
+				}
+				buf.WriteString("\n")
+				if text != "" {
+					fmt.Fprintf(buf, "  %s\n", text)
+				}
+				continue
+			}
+			fmt.Fprintf(buf, "%s %s\n\n", itemHeading, mdLink(item.Title, item.Link))
+			if date != "" {
+				fmt.Fprintf(buf, "*%s*\n\n", mdText(date))
+			}
+			if text != "" {
+				fmt.Fprintf(buf, "%s\n\n", text)
+			}
+		}
+		if layout == MarkdownList && len(items[group]) > 0 {
+			buf.WriteString("\n")
+		}
+	}
+	return append(bytes.TrimRight(buf.Bytes(), "\n"), '\n'), nil
+}
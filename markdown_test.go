@@ -0,0 +1,113 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"io/ioutil"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestToMarkdown(t *testing.T) {
+	r, err := Parse(pathTestSrc)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	r.ItemList[1].Description = `<p>Closed on <em>Monday</em>, [see below] &amp; *more*</p>`
+	src, err := r.ToMarkdown(nil)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	expected := `# [Library News](https://library.example.edu/news)
+
+News from the library
+
+- [New chemistry database](https://library.example.edu/news/1) (July 25, 2016)
+- [Holiday hours](https://library.example.edu/news/2)
+  Closed on Monday, \[see below\] & \*more\*
+`
+	if string(src) != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, src)
+	}
+
+	src, err = r.ToMarkdown(&MarkdownOptions{Layout: MarkdownSections, Excerpt: -1, Locale: "fr"})
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	expected = `# [Library News](https://library.example.edu/news)
+
+News from the library
+
+## [New chemistry database](https://library.example.edu/news/1)
+
+*25 juillet 2016*
+
+## [Holiday hours](https://library.example.edu/news/2)
+`
+	if string(src) != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, src)
+	}
+
+	if _, err := r.ToMarkdown(&MarkdownOptions{Layout: "table"}); err == nil {
+		t.Errorf("expected an error for an unknown layout")
+	}
+	if _, err := r.ToMarkdown(&MarkdownOptions{GroupBy: "decade"}); err == nil {
+		t.Errorf("expected an error for an unknown grouping")
+	}
+}
+
+func TestMarkdownGroups(t *testing.T) {
+	src, err := ioutil.ReadFile(path.Join("testdata", "rsdoiel.xml"))
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	r, err := Parse(src)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	r.ItemList[9].PubDate = ""
+	for groupBy, headings := range map[string]int{"year": 3, "month": 0, "week": 0, "day": 0} {
+		md, err := r.ToMarkdown(&MarkdownOptions{GroupBy: groupBy})
+		if err != nil {
+			t.Errorf("%s: %s", groupBy, err)
+			continue
+		}
+		s := string(md)
+		// every item is listed once
+		if n := strings.Count(s, "\n- ["); n != len(r.ItemList) {
+			t.Errorf("%s: expected %d items, got %d\n%s", groupBy, len(r.ItemList), n, s)
+		}
+		if !strings.HasSuffix(s, "## Undated\n\n- ["+r.ItemList[9].Title+"]("+r.ItemList[9].Link+")\n") {
+			t.Errorf("%s: expected the undated item last\n%s", groupBy, s)
+		}
+		if headings > 0 && strings.Count(s, "\n## ") != headings {
+			t.Errorf("%s: expected %d headings\n%s", groupBy, headings, s)
+		}
+	}
+	md, _ := r.ToMarkdown(&MarkdownOptions{GroupBy: "month", Layout: MarkdownSections})
+	if !strings.Contains(string(md), "\n## May 2016\n\n### [OPML to Markdown and back]") {
+		t.Errorf("expected item headings under month headings\n%s", md)
+	}
+}
@@ -0,0 +1,142 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// MergeOptions describe the channel written by MergeWithOptions
+type MergeOptions struct {
+	// Title, Link and Description describe the merged channel
+	Title       string
+	Link        string
+	Description string
+	// Limit is the maximum number of items, 0 for all of them
+	Limit int
+	// Dedupe identifies duplicate items, DefaultDedupeOptions when
+	// nil
+	Dedupe *DedupeOptions
+}
+
+// Merge combines the items of feeds into one feed, see
+// MergeWithOptions. The channel title, link and description are left
+// for the caller to set.
+func Merge(feeds ...*RSS2) *RSS2 {
+	return MergeWithOptions(nil, feeds...)
+}
+
+// MergeWithOptions combines the items of feeds into one feed sorted
+// newest first, items without a date follow in the order of the
+// feeds. Duplicates, e.g. a story carried by two feeds, are left out
+// as DedupeItems does, by default an item whose guid or link has
+// already been seen. Each item's source is set
+// to the title of the channel it came from unless it already names
+// one. The merged channel's pubDate and lastBuildDate are the newest
+// item's date. The items are copies, changing them leaves feeds as
+// they were.
+func MergeWithOptions(opts *MergeOptions, feeds ...*RSS2) *RSS2 {
+	if opts == nil {
+		opts = new(MergeOptions)
+	}
+	type dated struct {
+		item    Item
+		pubDate time.Time
+	}
+	list := []dated{}
+	for _, feed := range feeds {
+		if feed == nil {
+			continue
+		}
+		for _, item := range feed.ItemList {
+			item = copyItem(item)
+			if item.Source == "" {
+				item.Source = strings.TrimSpace(feed.Title)
+			}
+			pubDate, _ := parseDate(item.PubDate)
+			list = append(list, dated{item, pubDate})
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[j].pubDate.IsZero() {
+			return !list[i].pubDate.IsZero()
+		}
+		return list[i].pubDate.After(list[j].pubDate)
+	})
+	items := make([]Item, len(list))
+	for i, d := range list {
+		items[i] = d.item
+	}
+	items = DedupeItems(items, opts.Dedupe)
+	if opts.Limit > 0 && len(items) > opts.Limit {
+		items = items[0:opts.Limit]
+	}
+
+	r := new(RSS2)
+	r.Version = "2.0"
+	r.Title = opts.Title
+	r.Link = opts.Link
+	r.Description = opts.Description
+	r.ItemList = items
+	for _, item := range items {
+		if _, err := parseDate(item.PubDate); err == nil {
+			r.PubDate = item.PubDate
+			r.LastBuildDate = item.PubDate
+			break
+		}
+	}
+	return r
+}
+
+// copyItem returns item with its own categories, enclosure, attributes
+// and extensions
+func copyItem(item Item) Item {
+	if item.Category != nil {
+		item.Category = append([]string{}, item.Category...)
+	}
+	if item.Enclosure != nil {
+		enclosure := *item.Enclosure
+		item.Enclosure = &enclosure
+	}
+	item.OtherAttr = copyAttrs(item.OtherAttr)
+	item.Extensions = copyExtensions(item.Extensions)
+	return item
+}
+
+func copyAttrs(attrs CustomAttrs) CustomAttrs {
+	if attrs == nil {
+		return nil
+	}
+	return append(CustomAttrs{}, attrs...)
+}
+
+func copyExtensions(exts []Extension) []Extension {
+	if exts == nil {
+		return nil
+	}
+	out := make([]Extension, len(exts))
+	for i, ext := range exts {
+		ext.Attrs = copyAttrs(ext.Attrs)
+		ext.Children = copyExtensions(ext.Children)
+		out[i] = ext
+	}
+	return out
+}
@@ -0,0 +1,111 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"io/ioutil"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	news, err := Parse(pathTestSrc)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	src, err := ioutil.ReadFile(path.Join("testdata", "rsdoiel.xml"))
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	blog, err := Parse(src)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	events, err := Parse([]byte(`<rss version="2.0"><channel><title>Events</title><link>https://library.example.edu/events</link><description>Events</description>
+<item><title>Reading group</title><link>https://library.example.edu/events/1</link><pubDate>Tue, 26 Jul 2016 09:00:00 +0000</pubDate></item>
+<item><title>Chemistry database (again)</title><link>https://library.example.edu/news/1</link><pubDate>Wed, 27 Jul 2016 09:00:00 +0000</pubDate></item>
+<item><title>Same guid</title><guid>news-2</guid><link>https://library.example.edu/events/2</link></item>
+<item><title>Republished</title><link>https://library.example.edu/events/3</link><source>Campus News</source></item>
+</channel></rss>`))
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+
+	r := Merge(news, nil, blog, events)
+	if r.Title != "" || r.Version != "2.0" {
+		t.Errorf("expected an untitled 2.0 channel, got %q %q", r.Title, r.Version)
+	}
+	titles := []string{}
+	for _, item := range r.ItemList {
+		titles = append(titles, item.Title+" ("+item.Source+")")
+	}
+	// newest first, the PST and -0700 dates sort by their instant
+	if titles[0] != blog.ItemList[9].Title+" (R. S. Doiel)" {
+		t.Errorf("expected the newest blog post first, got %q", titles)
+	}
+	// the newer item with the same link replaces New chemistry database
+	expected := "Exploring Bash for Windows 10 Pro (R. S. Doiel)|Chemistry database (again) (Events)|Reading group (Events)|How to make a Pi-Top more Raspbian (R. S. Doiel)"
+	if !strings.Contains(strings.Join(titles, "|"), expected) {
+		t.Errorf("expected %q in %q", expected, titles)
+	}
+	// undated items follow in feed order
+	last := titles[len(titles)-2:]
+	if last[0] != "Holiday hours (Library News)" || last[1] != "Republished (Campus News)" {
+		t.Errorf("expected the undated items last, got %q", last)
+	}
+	if len(r.ItemList) != 2+10+2 {
+		t.Errorf("expected duplicates to be left out, got %d items %q", len(r.ItemList), titles)
+	}
+	if r.PubDate != r.ItemList[0].PubDate || r.LastBuildDate != r.PubDate {
+		t.Errorf("expected the channel dates from the newest item, got %q %q", r.PubDate, r.LastBuildDate)
+	}
+	// the feeds merged aren't changed
+	if news.ItemList[0].Source != "" {
+		t.Errorf("expected the input items unchanged")
+	}
+	r = Merge(news)
+	merged := r.ItemList[0]
+	merged.Category[0] = "Changed"
+	merged.Enclosure.URL = "https://example.edu/changed.mp3"
+	merged.Extensions[0].Attrs[0].Value = "https://example.edu/changed.png"
+	merged.Extensions[0].Children[0].Value = "Changed"
+	item := news.ItemList[0]
+	if item.Category[0] != "Chemistry" || item.Enclosure.URL != "https://library.example.edu/news/1.mp3" || item.Extensions[0].Attrs[0].Value != "https://library.example.edu/news/1.png" || item.Extensions[0].Children[0].Value != "Cover" {
+		t.Errorf("expected changes to the merged items to leave the input alone, got %+v", item)
+	}
+
+	r = MergeWithOptions(&MergeOptions{Title: "All News", Link: "https://library.example.edu", Description: "Everything", Limit: 3}, news, events)
+	if r.Title != "All News" || r.Link != "https://library.example.edu" || r.Description != "Everything" || len(r.ItemList) != 3 {
+		t.Errorf("unexpected merged channel %+v", r)
+	}
+	if r.ItemList[0].Title != "Chemistry database (again)" || r.ItemList[2].Title != "Holiday hours" {
+		t.Errorf("unexpected items %+v", r.ItemList)
+	}
+
+	// matching on guid alone keeps both chemistry database items
+	r = MergeWithOptions(&MergeOptions{Dedupe: &DedupeOptions{Keys: []DedupeKey{DedupeGUID}}}, news, events)
+	if len(r.ItemList) != 5 {
+		t.Errorf("expected 5 items, got %d", len(r.ItemList))
+	}
+}
@@ -0,0 +1,109 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PageOptions describe how Paginate splits a feed into pages
+type PageOptions struct {
+	// Size is the number of items on a page
+	Size int
+	// URL is the URL of a page with %d standing for the page number
+	// counting from 1, e.g. "https://library.example.edu/news-%d.xml"
+	URL string
+	// FirstURL, if set, is the URL of the first page instead, e.g.
+	// the feed readers subscribe to
+	FirstURL string
+	// Type is the media type of the pages, "application/rss+xml"
+	// if empty
+	Type string
+}
+
+// pagingRels are the link relations Paginate sets, links with these
+// relations are left out of the pages
+var pagingRels = map[string]bool{
+	"self": true, "first": true, "last": true, "next": true,
+	"prev": true, "previous": true,
+}
+
+// pageURL returns the URL of page n counting from 1
+func (opts *PageOptions) pageURL(n int) string {
+	if n == 1 && opts.FirstURL != "" {
+		return opts.FirstURL
+	}
+	return strings.Replace(opts.URL, "%d", strconv.Itoa(n), -1)
+}
+
+// Paginate splits the feed into pages of opts.Size items in their
+// order in the feed, sort them first with Sort. Each page has the
+// channel of r and atom:link elements to itself ("self") and, as in
+// RFC 5005 paged feeds, to the "first" and "last" pages and the
+// "prev" and "next" pages when there are any. A feed without items
+// is a single page.
+func (r *RSS2) Paginate(opts *PageOptions) ([]*RSS2, error) {
+	if opts == nil || opts.Size <= 0 {
+		return nil, fmt.Errorf("page size must be greater than zero")
+	}
+	if !strings.Contains(opts.URL, "%d") {
+		return nil, fmt.Errorf("page URL %q needs %%d for the page number", opts.URL)
+	}
+	n := (len(r.ItemList) + opts.Size - 1) / opts.Size
+	if n == 0 {
+		n = 1
+	}
+	links := []AtomLink{}
+	for _, link := range r.AtomLinks {
+		if !pagingRels[strings.ToLower(strings.TrimSpace(link.Rel))] {
+			links = append(links, link)
+		}
+	}
+	mediaType := opts.Type
+	if mediaType == "" {
+		mediaType = "application/rss+xml"
+	}
+	pages := []*RSS2{}
+	for i := 1; i <= n; i++ {
+		page := new(RSS2)
+		*page = *r
+		page.AtomLinks = append([]AtomLink{}, links...)
+		link := func(rel string, k int) {
+			page.AtomLinks = append(page.AtomLinks, AtomLink{Href: opts.pageURL(k), Rel: rel, Type: mediaType})
+		}
+		link("self", i)
+		link("first", 1)
+		if i > 1 {
+			link("prev", i-1)
+		}
+		if i < n {
+			link("next", i+1)
+		}
+		link("last", n)
+		start, end := (i-1)*opts.Size, i*opts.Size
+		if end > len(r.ItemList) {
+			end = len(r.ItemList)
+		}
+		page.ItemList = append([]Item{}, r.ItemList[start:end]...)
+		pages = append(pages, page)
+	}
+	return pages, nil
+}
@@ -0,0 +1,127 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"io/ioutil"
+	"path"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	src, err := ioutil.ReadFile(path.Join("testdata", "rsdoiel.xml"))
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	r, err := Parse(src)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	r.AtomLinks = []AtomLink{
+		{Href: "https://rsdoiel.github.io/rss.xml", Rel: "self"},
+		{Href: "https://pubsubhubbub.appspot.com/", Rel: "hub"},
+	}
+	if err := r.Sort("date", true); err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	pages, err := r.Paginate(&PageOptions{Size: 4, URL: "https://rsdoiel.github.io/rss-%d.xml", FirstURL: "https://rsdoiel.github.io/rss.xml"})
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if len(pages) != 3 || len(pages[0].ItemList) != 4 || len(pages[2].ItemList) != 2 {
+		t.Errorf("expected pages of 4, 4 and 2 items, got %d pages", len(pages))
+		t.FailNow()
+	}
+	if pages[0].ItemList[0].Title != r.ItemList[0].Title || pages[2].ItemList[1].Title != r.ItemList[9].Title || pages[1].Title != r.Title {
+		t.Errorf("expected the items in order with the channel on each page")
+	}
+	expected := [][]string{
+		{"hub https://pubsubhubbub.appspot.com/", "self https://rsdoiel.github.io/rss.xml", "first https://rsdoiel.github.io/rss.xml", "next https://rsdoiel.github.io/rss-2.xml", "last https://rsdoiel.github.io/rss-3.xml"},
+		{"hub https://pubsubhubbub.appspot.com/", "self https://rsdoiel.github.io/rss-2.xml", "first https://rsdoiel.github.io/rss.xml", "prev https://rsdoiel.github.io/rss.xml", "next https://rsdoiel.github.io/rss-3.xml", "last https://rsdoiel.github.io/rss-3.xml"},
+		{"hub https://pubsubhubbub.appspot.com/", "self https://rsdoiel.github.io/rss-3.xml", "first https://rsdoiel.github.io/rss.xml", "prev https://rsdoiel.github.io/rss-2.xml", "last https://rsdoiel.github.io/rss-3.xml"},
+	}
+	for i, page := range pages {
+		links := []string{}
+		for _, link := range page.AtomLinks {
+			links = append(links, link.Rel+" "+link.Href)
+		}
+		if len(links) != len(expected[i]) {
+			t.Errorf("page %d: expected %q, got %q", i+1, expected[i], links)
+			continue
+		}
+		for j := range links {
+			if links[j] != expected[i][j] {
+				t.Errorf("page %d: expected %q, got %q", i+1, expected[i], links)
+				break
+			}
+		}
+	}
+	if len(r.AtomLinks) != 2 || len(r.ItemList) != 10 {
+		t.Errorf("expected the feed unchanged")
+	}
+
+	// atom:link elements are kept apart from the channel link
+	src, err = pages[1].ToXML()
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	page, err := Parse(src)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if page.Link != r.Link || len(page.AtomLinks) != 6 || page.AtomLinks[4] != pages[1].AtomLinks[4] {
+		t.Errorf("unexpected link %q and atom links %+v in\n%s", page.Link, page.AtomLinks, src)
+	}
+	results, err := page.Filter([]string{".link", ".atom:link[3].href"})
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if hrefs, ok := results[".atom:link[3].href"].([]string); results[".link"] != r.Link || !ok || len(hrefs) != 1 || hrefs[0] != "https://rsdoiel.github.io/rss.xml" {
+		t.Errorf("unexpected %+v", results)
+	}
+	// the RSS self link isn't the Atom document's
+	if feed := NewAtom(page); len(feed.Links) != 6 || feed.Links[1].Rel != "hub" || feed.Links[2].Rel != "first" {
+		t.Errorf("expected the links in the Atom feed, got %+v", feed.Links)
+	}
+	if pages[0].AtomLinks[2].Type != "application/rss+xml" {
+		t.Errorf("expected RSS page links, got %+v", pages[0].AtomLinks[2])
+	}
+
+	empty := &RSS2{Title: "Empty"}
+	if pages, err := empty.Paginate(&PageOptions{Size: 10, URL: "page-%d.xml"}); err != nil || len(pages) != 1 || len(pages[0].AtomLinks) != 3 {
+		t.Errorf("expected a single page, got %+v, %v", pages, err)
+	}
+	pages, err = r.Paginate(&PageOptions{Size: 5, URL: "https://rsdoiel.github.io/feed-%d.json", Type: "application/feed+json"})
+	if err != nil || len(pages) != 2 || pages[1].AtomLinks[3].Type != "application/feed+json" {
+		t.Errorf("expected JSON Feed page links, got %+v, %v", pages, err)
+	}
+	if _, err := r.Paginate(&PageOptions{Size: 0, URL: "page-%d.xml"}); err == nil {
+		t.Errorf("expected an error for a page size of 0")
+	}
+	if _, err := r.Paginate(&PageOptions{Size: 4, URL: "page.xml"}); err == nil {
+		t.Errorf("expected an error for a URL without %%d")
+	}
+}
@@ -0,0 +1,1007 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+//
+// Data paths
+//
+// A data path selects values from an RSS2 document. It is a sequence
+// of steps, each either a field name introduced by a period or a
+// selector in square brackets.
+//
+//     path     = step { step }
+//     step     = "." name | "[" [ selector ] "]"
+//     name     = [ prefix ":" ] ident
+//     selector = index { "," index }
+//              | index "-" [ index ]
+//              | [ index ] ":" [ index ] [ ":" [ index ] ]
+//              | "?(" expr ")"
+//     index    = [ "-" ] digits
+//
+// Field names are matched against the XML element name, the JSON name
+// or the Go field name (case insensitive), e.g. .item[].content and
+// .item[].encoded are the same. Names with a namespace prefix, like
+// media:content, select item extension elements. A name applied to a
+// list applies to each element in turn and "[]" selects every element.
+//
+// Selectors pick list elements by position counting from zero,
+// negative indexes count back from the end of the list.
+//
+//     [2]         the third element
+//     [-1]        the last element
+//     [1,3,5]     the listed elements
+//     [0-3]       an inclusive range, the first four elements
+//     [5-]        an open range, the sixth element to the end
+//     [-5:]       a slice, the last five elements
+//     [:5]        a slice, the first five elements (end is exclusive)
+//     [::2]       a slice with a step, every other element
+//     [::-1]      every element in reverse order
+//
+// Elements can also be selected by their content with a predicate,
+// e.g. [?(@.category == "Chemistry")], see predicate.go.
+//
+// Examples: .version, .channel.title, .item[].link,
+// .item[].enclosure.url, .item[0-3].title, .item[-5:].link,
+// .item[].media:content.url
+//
+
+// PathError reports a data path that can't be parsed or doesn't
+// match the structure of an RSS2 document. Pos is the byte offset of
+// the offending token in Path.
+type PathError struct {
+	Path  string
+	Pos   int
+	Token string
+	Msg   string
+}
+
+func (e *PathError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("%s in %q at offset %d", e.Msg, e.Path, e.Pos)
+	}
+	return fmt.Sprintf("%s in %q at offset %d, %q", e.Msg, e.Path, e.Pos, e.Token)
+}
+
+// namespacePrefixes maps commonly used prefixes to their namespace so
+// prefixed names in paths match the right extension elements. Unknown
+// prefixes match on the local name only.
+var namespacePrefixes = map[string]string{
+	"atom":    "http://www.w3.org/2005/Atom",
+	"content": "http://purl.org/rss/1.0/modules/content/",
+	"dc":      "http://purl.org/dc/elements/1.1/",
+	"georss":  "http://www.georss.org/georss",
+	"itunes":  "http://www.itunes.com/dtds/podcast-1.0.dtd",
+	"media":   "http://search.yahoo.com/mrss/",
+	"slash":   "http://purl.org/rss/1.0/modules/slash/",
+	"wfw":     "http://wellformedweb.org/CommentAPI/",
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenDot
+	tokenName
+	tokenNumber
+	tokenLBracket
+	tokenRBracket
+	tokenDash
+	tokenColon
+	tokenComma
+	tokenQuestion
+	tokenAt
+	tokenLParen
+	tokenRParen
+	tokenString
+	tokenOp
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	pos   int
+	value int
+}
+
+func isNameChar(c byte, first bool) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		return true
+	case first:
+		return false
+	case c >= '0' && c <= '9', c == '-', c == ':':
+		return true
+	}
+	return false
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// tokenizePath splits a data path into tokens. Whitespace is only
+// allowed inside brackets.
+func tokenizePath(src string) ([]token, error) {
+	tokens := []token{}
+	depth := 0
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' && depth > 0:
+			i++
+		case c == '.':
+			tokens = append(tokens, token{kind: tokenDot, text: ".", pos: i})
+			i++
+		case c == '[':
+			tokens = append(tokens, token{kind: tokenLBracket, text: "[", pos: i})
+			depth++
+			i++
+		case c == ']':
+			tokens = append(tokens, token{kind: tokenRBracket, text: "]", pos: i})
+			depth--
+			i++
+		case c == '-' && depth > 0:
+			tokens = append(tokens, token{kind: tokenDash, text: "-", pos: i})
+			i++
+		case c == ':' && depth > 0:
+			tokens = append(tokens, token{kind: tokenColon, text: ":", pos: i})
+			i++
+		case c == ',' && depth > 0:
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: i})
+			i++
+		case c == '?' && depth > 0:
+			tokens = append(tokens, token{kind: tokenQuestion, text: "?", pos: i})
+			i++
+		case c == '@' && depth > 0:
+			tokens = append(tokens, token{kind: tokenAt, text: "@", pos: i})
+			i++
+		case c == '(' && depth > 0:
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case c == ')' && depth > 0:
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case (c == '"' || c == '\'') && depth > 0:
+			s, j, err := scanString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: s, pos: i})
+			i = j
+		case strings.IndexByte("=!<>&|", c) >= 0 && depth > 0:
+			op := ""
+			for _, candidate := range []string{"==", "!=", "=~", "<=", ">=", "&&", "||", "<", ">", "!"} {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, &PathError{Path: src, Pos: i, Token: string(c), Msg: "unknown operator"}
+			}
+			tokens = append(tokens, token{kind: tokenOp, text: op, pos: i})
+			i += len(op)
+		case isDigit(c):
+			j := i
+			for j < len(src) && isDigit(src[j]) {
+				j++
+			}
+			n, err := strconv.Atoi(src[i:j])
+			if err != nil {
+				return nil, &PathError{Path: src, Pos: i, Token: src[i:j], Msg: "invalid number"}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: src[i:j], pos: i, value: n})
+			i = j
+		case isNameChar(c, true):
+			j := i + 1
+			for j < len(src) && isNameChar(src[j], false) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenName, text: src[i:j], pos: i})
+			i = j
+		default:
+			return nil, &PathError{Path: src, Pos: i, Token: string(c), Msg: "unexpected character"}
+		}
+	}
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(src)})
+	return tokens, nil
+}
+
+// scanString reads a quoted string starting at src[i] returning its
+// value and the offset following the closing quote. Backslash escapes
+// the next character.
+func scanString(src string, i int) (string, int, error) {
+	quote := src[i]
+	var sb strings.Builder
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+			if j < len(src) {
+				sb.WriteByte(src[j])
+			}
+		case quote:
+			return sb.String(), j + 1, nil
+		default:
+			sb.WriteByte(src[j])
+		}
+	}
+	return "", len(src), &PathError{Path: src, Pos: i, Token: src[i:], Msg: "unterminated string"}
+}
+
+// selectorKind distinguishes the forms of bracket expressions
+type selectorKind int
+
+const (
+	selectAll selectorKind = iota
+	selectIndexes
+	selectRange
+	selectSlice
+	selectFilter
+)
+
+// selector picks list elements by position. Negative values count
+// from the end of the list. For slices start, end and step are
+// optional as in Python, for ranges an absent end means the end of
+// the list.
+type selector struct {
+	kind     selectorKind
+	indexes  []int
+	start    int
+	end      int
+	step     int
+	hasStart bool
+	hasEnd   bool
+	filter   predicate
+}
+
+// normalize converts a possibly negative index into an offset in a
+// list of length n
+func normalize(i, n int) int {
+	if i < 0 {
+		return n + i
+	}
+	return i
+}
+
+// pick returns the indexes selected from a list of length n
+func (sel *selector) pick(n int) []int {
+	indexes := []int{}
+	switch sel.kind {
+	case selectAll:
+		for i := 0; i < n; i++ {
+			indexes = append(indexes, i)
+		}
+	case selectIndexes:
+		for _, i := range sel.indexes {
+			if i = normalize(i, n); i >= 0 && i < n {
+				indexes = append(indexes, i)
+			}
+		}
+	case selectRange:
+		first, last := normalize(sel.start, n), n-1
+		if sel.hasEnd {
+			last = normalize(sel.end, n)
+		}
+		if first < 0 {
+			first = 0
+		}
+		if last > n-1 {
+			last = n - 1
+		}
+		for i := first; i <= last; i++ {
+			indexes = append(indexes, i)
+		}
+	case selectSlice:
+		step := sel.step
+		if step == 0 {
+			step = 1
+		}
+		// clamp follows Python's slice semantics
+		clamp := func(i, low, high int) int {
+			i = normalize(i, n)
+			if i < low {
+				return low
+			}
+			if i > high {
+				return high
+			}
+			return i
+		}
+		if step > 0 {
+			start, end := 0, n
+			if sel.hasStart {
+				start = clamp(sel.start, 0, n)
+			}
+			if sel.hasEnd {
+				end = clamp(sel.end, 0, n)
+			}
+			for i := start; i < end; i += step {
+				indexes = append(indexes, i)
+			}
+		} else {
+			start, end := n-1, -1
+			if sel.hasStart {
+				start = clamp(sel.start, -1, n-1)
+			}
+			if sel.hasEnd {
+				end = clamp(sel.end, -1, n-1)
+			}
+			for i := start; i > end; i += step {
+				indexes = append(indexes, i)
+			}
+		}
+	}
+	return indexes
+}
+
+type stepKind int
+
+const (
+	stepField stepKind = iota
+	stepSelect
+)
+
+type pathStep struct {
+	kind   stepKind
+	name   string
+	space  string
+	local  string
+	sel    *selector
+	pos    int
+	start  int
+	isList bool
+	// typ is the type of the values the step selects, set by checkSteps
+	typ reflect.Type
+}
+
+// Path is a compiled data path, see CompilePath
+type Path struct {
+	src    string
+	steps  []pathStep
+	isList bool
+	leaf   reflect.Type
+}
+
+// String returns the source of the data path
+func (p *Path) String() string {
+	return p.src
+}
+
+// parser turns tokens into path steps
+type parser struct {
+	src    string
+	tokens []token
+	i      int
+}
+
+func (ps *parser) peek() token {
+	return ps.tokens[ps.i]
+}
+
+func (ps *parser) next() token {
+	t := ps.tokens[ps.i]
+	if t.kind != tokenEOF {
+		ps.i++
+	}
+	return t
+}
+
+func (ps *parser) errorf(t token, format string, args ...interface{}) error {
+	return &PathError{Path: ps.src, Pos: t.pos, Token: t.text, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (ps *parser) expect(kind tokenKind, what string) (token, error) {
+	t := ps.next()
+	if t.kind != kind {
+		if t.kind == tokenEOF {
+			return t, ps.errorf(t, "expected %s, path ended", what)
+		}
+		return t, ps.errorf(t, "expected %s", what)
+	}
+	return t, nil
+}
+
+// parseIndex parses an optionally negative integer
+func (ps *parser) parseIndex() (int, error) {
+	sign := 1
+	if ps.peek().kind == tokenDash {
+		ps.next()
+		sign = -1
+	}
+	t, err := ps.expect(tokenNumber, "an index")
+	if err != nil {
+		return 0, err
+	}
+	return sign * t.value, nil
+}
+
+// atIndex reports if the next token starts an index
+func (ps *parser) atIndex() bool {
+	k := ps.peek().kind
+	return k == tokenNumber || k == tokenDash
+}
+
+// parseSelector parses the inside of a bracket expression, the
+// opening bracket has been consumed.
+func (ps *parser) parseSelector() (*selector, error) {
+	var err error
+	sel := new(selector)
+	t := ps.peek()
+	switch {
+	case t.kind == tokenQuestion:
+		ps.next()
+		if _, err := ps.expect(tokenLParen, "\"(\""); err != nil {
+			return nil, err
+		}
+		if sel.filter, err = ps.parseOr(); err != nil {
+			return nil, err
+		}
+		if _, err := ps.expect(tokenRParen, "\")\""); err != nil {
+			return nil, err
+		}
+		sel.kind = selectFilter
+		return sel, nil
+	case t.kind == tokenRBracket:
+		sel.kind = selectAll
+		return sel, nil
+	case t.kind == tokenColon:
+		// slice without a start
+	case ps.atIndex():
+		if sel.start, err = ps.parseIndex(); err != nil {
+			return nil, err
+		}
+		sel.hasStart = true
+	default:
+		return nil, ps.errorf(t, "expected an index")
+	}
+
+	t = ps.peek()
+	switch t.kind {
+	case tokenRBracket:
+		sel.kind = selectIndexes
+		sel.indexes = []int{sel.start}
+	case tokenComma:
+		sel.kind = selectIndexes
+		sel.indexes = []int{sel.start}
+		for ps.peek().kind == tokenComma {
+			ps.next()
+			i, err := ps.parseIndex()
+			if err != nil {
+				return nil, err
+			}
+			sel.indexes = append(sel.indexes, i)
+		}
+	case tokenDash:
+		ps.next()
+		sel.kind = selectRange
+		if ps.atIndex() {
+			t = ps.peek()
+			if sel.end, err = ps.parseIndex(); err != nil {
+				return nil, err
+			}
+			sel.hasEnd = true
+			if sel.start >= 0 && sel.end >= 0 && sel.end < sel.start {
+				return nil, ps.errorf(t, "range end before start")
+			}
+		}
+	case tokenColon:
+		ps.next()
+		sel.kind = selectSlice
+		if ps.atIndex() {
+			if sel.end, err = ps.parseIndex(); err != nil {
+				return nil, err
+			}
+			sel.hasEnd = true
+		}
+		if ps.peek().kind == tokenColon {
+			ps.next()
+			if ps.atIndex() {
+				t = ps.peek()
+				if sel.step, err = ps.parseIndex(); err != nil {
+					return nil, err
+				}
+				if sel.step == 0 {
+					return nil, ps.errorf(t, "slice step can't be zero")
+				}
+			}
+		}
+	}
+	return sel, nil
+}
+
+// parseSteps parses field and selector steps until a token that
+// can't continue the path.
+func (ps *parser) parseSteps() ([]pathStep, error) {
+	steps := []pathStep{}
+	for {
+		t := ps.peek()
+		switch t.kind {
+		case tokenDot:
+			ps.next()
+			n, err := ps.expect(tokenName, "field name")
+			if err != nil {
+				return nil, err
+			}
+			step := pathStep{kind: stepField, name: n.text, local: n.text, pos: n.pos, start: t.pos}
+			if i := strings.Index(n.text, ":"); i >= 0 {
+				prefix, local := n.text[0:i], n.text[i+1:]
+				if prefix == "" || local == "" || strings.Contains(local, ":") {
+					return nil, ps.errorf(n, "malformed prefixed name")
+				}
+				step.space, step.local = namespacePrefixes[prefix], local
+			}
+			steps = append(steps, step)
+		case tokenLBracket:
+			ps.next()
+			sel, err := ps.parseSelector()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := ps.expect(tokenRBracket, "\"]\""); err != nil {
+				return nil, err
+			}
+			steps = append(steps, pathStep{kind: stepSelect, sel: sel, pos: t.pos, start: t.pos})
+		default:
+			return steps, nil
+		}
+	}
+}
+
+func (ps *parser) parse() ([]pathStep, error) {
+	if ps.peek().kind != tokenDot {
+		return nil, ps.errorf(ps.peek(), "path must start with \".\"")
+	}
+	if len(ps.tokens) == 2 {
+		// "." is the whole document
+		return []pathStep{}, nil
+	}
+	steps, err := ps.parseSteps()
+	if err != nil {
+		return nil, err
+	}
+	if t := ps.peek(); t.kind != tokenEOF {
+		return nil, ps.errorf(t, "expected \".\" or \"[\"")
+	}
+	return steps, nil
+}
+
+var (
+	rss2Type      = reflect.TypeOf(RSS2{})
+	extensionType = reflect.TypeOf(Extension{})
+	attrsType     = reflect.TypeOf(CustomAttrs{})
+	stringType    = reflect.TypeOf("")
+)
+
+// channelMarker is the static type of the ".channel" step, it stands
+// for the RSS2 value restricted to its channel fields.
+type channelMarker struct{}
+
+var channelType = reflect.TypeOf(channelMarker{})
+
+// fieldNames returns the names a struct field answers to in a path
+func fieldNames(f reflect.StructField) []string {
+	names := []string{strings.ToLower(f.Name)}
+	if tag := f.Tag.Get("xml"); tag != "" {
+		name, prefix := strings.SplitN(tag, ",", 2)[0], ""
+		// use the prefix of a known namespace, e.g. atom:link,
+		// or else drop the namespace
+		if i := strings.LastIndex(name, " "); i >= 0 {
+			for p, ns := range namespacePrefixes {
+				if ns == name[0:i] {
+					prefix = p + ":"
+					break
+				}
+			}
+			name = name[i+1:]
+		}
+		if i := strings.LastIndex(name, ">"); i >= 0 {
+			name = name[i+1:]
+		}
+		if name != "" {
+			name = prefix + name
+		}
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	if tag := f.Tag.Get("json"); tag != "" {
+		name := strings.SplitN(tag, ",", 2)[0]
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// isChannelField reports if f is an RSS2 field in the channel element
+func isChannelField(f reflect.StructField) bool {
+	tag := strings.SplitN(f.Tag.Get("xml"), ",", 2)[0]
+	if i := strings.LastIndex(tag, " "); i >= 0 {
+		tag = tag[i+1:]
+	}
+	return strings.HasPrefix(tag, "channel>")
+}
+
+// lookupField finds the field of struct type t named name
+func lookupField(t reflect.Type, name string, channelOnly bool) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Name == "XMLName" || f.PkgPath != "" {
+			continue
+		}
+		if channelOnly && !isChannelField(f) {
+			continue
+		}
+		for _, n := range fieldNames(f) {
+			if n == name || strings.EqualFold(n, name) && n == strings.ToLower(f.Name) {
+				return f, true
+			}
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// isList reports if t is a slice other than the attribute list
+func isList(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t != attrsType
+}
+
+// checkSteps walks path steps over the types starting from t,
+// reporting unknown fields and selectors applied to values that
+// aren't lists. It returns the type of the values selected and if
+// there can be more than one. The ".channel" step is only allowed at
+// the root of a path.
+func checkSteps(src string, t reflect.Type, steps []pathStep, root bool) (reflect.Type, bool, error) {
+	many := false
+	for i := range steps {
+		step := &steps[i]
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		perr := &PathError{Path: src, Pos: step.pos, Token: step.name}
+		if step.kind == stepSelect {
+			if !isList(t) {
+				perr.Token = "["
+				perr.Msg = fmt.Sprintf("can't select from %s", describeType(t))
+				return nil, false, perr
+			}
+			step.isList = true
+			many = true
+			t = t.Elem()
+			if step.sel.kind == selectFilter {
+				if err := step.sel.filter.check(src, t); err != nil {
+					return nil, false, err
+				}
+			}
+			step.typ = t
+			continue
+		}
+		// A field name applied to a list applies to each element
+		if isList(t) {
+			step.isList = true
+			many = true
+			t = t.Elem()
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+		}
+		switch {
+		case t == rss2Type && step.name == "channel" && i == 0 && root:
+			t = channelType
+		case t == rss2Type || t == channelType:
+			f, ok := lookupField(rss2Type, step.name, t == channelType)
+			if !ok {
+				perr.Msg = "unknown channel field"
+				return nil, false, perr
+			}
+			t = f.Type
+		case t == extensionType || t == attrsType:
+			// extension elements and attributes are only known at runtime
+			if t == attrsType {
+				t = stringType
+			}
+		case t.Kind() == reflect.Struct:
+			if step.space != "" || strings.Contains(step.name, ":") {
+				if _, ok := t.FieldByName("Extensions"); ok {
+					t = reflect.SliceOf(extensionType)
+					step.typ = t
+					continue
+				}
+			}
+			f, ok := lookupField(t, step.name, false)
+			if !ok {
+				perr.Msg = fmt.Sprintf("unknown %s field", describeType(t))
+				return nil, false, perr
+			}
+			t = f.Type
+		default:
+			perr.Msg = fmt.Sprintf("%s has no fields", describeType(t))
+			return nil, false, perr
+		}
+		step.typ = t
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if isList(t) {
+		many = true
+		t = t.Elem()
+	}
+	return t, many, nil
+}
+
+func describeType(t reflect.Type) string {
+	switch {
+	case t == channelType:
+		return "channel"
+	case t == rss2Type:
+		return "rss"
+	case t == extensionType:
+		return "extension"
+	case isList(t):
+		return "list"
+	case t.Kind() == reflect.Struct:
+		return strings.ToLower(t.Name())
+	}
+	return t.Kind().String()
+}
+
+// CompilePath parses a data path and checks it against the structure
+// of an RSS2 document.
+func CompilePath(src string) (*Path, error) {
+	tokens, err := tokenizePath(src)
+	if err != nil {
+		return nil, err
+	}
+	ps := &parser{src: src, tokens: tokens}
+	steps, err := ps.parse()
+	if err != nil {
+		return nil, err
+	}
+	p := &Path{src: src, steps: steps}
+	if p.leaf, p.isList, err = checkSteps(src, rss2Type, steps, true); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// IsList reports if the path can select more than one value
+func (p *Path) IsList() bool {
+	return p.isList
+}
+
+// matchName reports if an XML name matches a path step
+func (step *pathStep) matchName(name xml.Name) bool {
+	if name.Local != step.local {
+		return false
+	}
+	return step.space == "" || step.space == name.Space
+}
+
+// apply applies a step to a single value adding the results to out
+func (step *pathStep) apply(v reflect.Value, channel bool, out []reflect.Value) []reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return out
+		}
+		v = v.Elem()
+	}
+	if step.kind == stepSelect && step.sel.kind == selectFilter {
+		for i := 0; i < v.Len(); i++ {
+			if step.sel.filter.test(v.Index(i)) {
+				out = append(out, v.Index(i))
+			}
+		}
+		return out
+	}
+	if step.kind == stepSelect {
+		for _, i := range step.sel.pick(v.Len()) {
+			out = append(out, v.Index(i))
+		}
+		return out
+	}
+	if isList(v.Type()) {
+		for i := 0; i < v.Len(); i++ {
+			out = step.apply(v.Index(i), channel, out)
+		}
+		return out
+	}
+	switch {
+	case v.Type() == attrsType:
+		for _, attr := range v.Interface().(CustomAttrs) {
+			if step.matchName(attr.Name) {
+				out = append(out, reflect.ValueOf(attr.Value))
+			}
+		}
+	case v.Type() == extensionType:
+		ext := v.Interface().(Extension)
+		for _, attr := range ext.Attrs {
+			if step.matchName(attr.Name) {
+				out = append(out, reflect.ValueOf(attr.Value))
+			}
+		}
+		for _, child := range ext.Children {
+			if step.matchName(child.XMLName) {
+				out = append(out, reflect.ValueOf(child))
+			}
+		}
+	case v.Kind() == reflect.Struct:
+		if step.space != "" || strings.Contains(step.name, ":") {
+			// the matches are one list, as checkSteps types them,
+			// so selectors can follow
+			if exts := v.FieldByName("Extensions"); exts.IsValid() {
+				matches := []Extension{}
+				for _, ext := range exts.Interface().([]Extension) {
+					if step.matchName(ext.XMLName) {
+						matches = append(matches, ext)
+					}
+				}
+				return append(out, reflect.ValueOf(matches))
+			}
+		}
+		if f, ok := lookupField(v.Type(), step.name, channel && v.Type() == rss2Type); ok {
+			out = append(out, v.FieldByIndex(f.Index))
+		}
+	}
+	return out
+}
+
+// evalSteps applies the steps to values in turn, channel is true
+// when the values are an RSS2 document restricted to channel fields.
+func evalSteps(values []reflect.Value, steps []pathStep, channel bool) []reflect.Value {
+	for _, step := range steps {
+		out := []reflect.Value{}
+		for _, v := range values {
+			out = step.apply(v, channel, out)
+		}
+		values = out
+	}
+	return values
+}
+
+// flatten dereferences pointers, dropping nil ones, and expands lists
+// into their elements.
+func flatten(values []reflect.Value) []reflect.Value {
+	out := []reflect.Value{}
+	for _, v := range values {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				break
+			}
+			v = v.Elem()
+		}
+		switch {
+		case v.Kind() == reflect.Ptr:
+			// nil, nothing to report
+		case isList(v.Type()):
+			for i := 0; i < v.Len(); i++ {
+				out = append(out, v.Index(i))
+			}
+		default:
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// eval returns the values selected by the path, lists at the end of
+// the path are flattened.
+func (p *Path) eval(r *RSS2) []reflect.Value {
+	values := []reflect.Value{reflect.ValueOf(r).Elem()}
+	steps := p.steps
+	channel := false
+	if len(steps) > 0 && steps[0].kind == stepField && steps[0].name == "channel" {
+		channel = true
+		steps = steps[1:]
+	}
+	return flatten(evalSteps(values, steps, channel))
+}
+
+// channelMap returns the non-empty channel fields keyed by XML name
+func (r *RSS2) channelMap() map[string]interface{} {
+	m := map[string]interface{}{}
+	v := reflect.ValueOf(r).Elem()
+	for i := 0; i < rss2Type.NumField(); i++ {
+		f := rss2Type.Field(i)
+		tag := strings.SplitN(f.Tag.Get("xml"), ",", 2)[0]
+		if !strings.HasPrefix(tag, "channel>") || f.Name == "ItemList" {
+			continue
+		}
+		fv := v.Field(i)
+		if fv.Kind() != reflect.String {
+			// e.g. the image
+			if !fv.IsZero() {
+				m[strings.TrimPrefix(tag, "channel>")] = fv.Interface()
+			}
+		} else if s := fv.String(); s != "" {
+			m[strings.TrimPrefix(tag, "channel>")] = s
+		}
+	}
+	return m
+}
+
+// leafValue converts a selected value for reporting, extensions are
+// reported by their text.
+func leafValue(v reflect.Value) interface{} {
+	if v.Type() == extensionType {
+		return strings.TrimSpace(v.Interface().(Extension).Value)
+	}
+	return v.Interface()
+}
+
+// Select returns the values the path selects from r. Paths selecting
+// strings return a string, or a []string for paths over lists, other
+// values are returned as interface{} or []interface{}.
+func (p *Path) Select(r *RSS2) interface{} {
+	if len(p.steps) == 1 && p.steps[0].name == "channel" {
+		return r.channelMap()
+	}
+	values := p.eval(r)
+	stringLeaf := p.leaf.Kind() == reflect.String || p.leaf == extensionType
+	if p.isList {
+		if stringLeaf {
+			vals := []string{}
+			for _, v := range values {
+				vals = append(vals, fmt.Sprintf("%s", leafValue(v)))
+			}
+			return vals
+		}
+		vals := []interface{}{}
+		for _, v := range values {
+			vals = append(vals, leafValue(v))
+		}
+		return vals
+	}
+	if len(values) == 0 {
+		if stringLeaf {
+			return ""
+		}
+		return nil
+	}
+	return leafValue(values[0])
+}
+
+// Filter given an RSS2 document return all the entries matching so we
+// can apply return each of the data paths requested. Results are
+// keyed by data path, see CompilePath for the path syntax.
+// e.g. .version, .channel.title, .channel.link, .item[].link,
+// .item[].guid, .item[].title, .item[].description
+func (r *RSS2) Filter(dataPaths []string) (map[string]interface{}, error) {
+	if len(dataPaths) == 0 {
+		return nil, fmt.Errorf("No data paths found")
+	}
+	result := make(map[string]interface{})
+	for _, dataPath := range dataPaths {
+		p, err := CompilePath(dataPath)
+		if err != nil {
+			return nil, err
+		}
+		result[dataPath] = p.Select(r)
+	}
+	return result, nil
+}
@@ -0,0 +1,304 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"io/ioutil"
+	"path"
+	"strings"
+	"testing"
+)
+
+var pathTestSrc = []byte(`<rss version="2.0" xmlns:media="http://search.yahoo.com/mrss/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<channel>
+  <title>Library News</title>
+  <link>https://library.example.edu/news</link>
+  <description>News from the library</description>
+  <managingEditor>news@library.example.edu (News Desk)</managingEditor>
+  <item>
+    <title>New chemistry database</title>
+    <link>https://library.example.edu/news/1</link>
+    <guid>news-1</guid>
+    <author>jane@example.edu (Jane Doe)</author>
+    <category>Chemistry</category>
+    <category>Databases</category>
+    <pubDate>Mon, 25 Jul 2016 20:48:03 -0700</pubDate>
+    <enclosure url="https://library.example.edu/news/1.mp3" length="1024" type="audio/mpeg"/>
+    <media:content url="https://library.example.edu/news/1.png" type="image/png"><media:title>Cover</media:title></media:content>
+    <dc:creator>Jane Doe</dc:creator>
+  </item>
+  <item>
+    <title>Holiday hours</title>
+    <link>https://library.example.edu/news/2</link>
+    <guid>news-2</guid>
+    <category>Hours</category>
+    <description>Closed on Monday</description>
+  </item>
+</channel>
+</rss>`)
+
+func TestFilterPaths(t *testing.T) {
+	r, err := Parse(pathTestSrc)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	strs := map[string]string{
+		".version":                "2.0",
+		".channel.title":          "Library News",
+		".channel.managingEditor": "news@library.example.edu (News Desk)",
+		".title":                  "Library News",
+	}
+	lists := map[string][]string{
+		".item[].title":                   {"New chemistry database", "Holiday hours"},
+		".channel.item[].guid":            {"news-1", "news-2"},
+		".item[].author":                  {"jane@example.edu (Jane Doe)", ""},
+		".item[].category":                {"Chemistry", "Databases", "Hours"},
+		".item[1].category":               {"Hours"},
+		".item[0].category[1]":            {"Databases"},
+		".item[].enclosure.url":           {"https://library.example.edu/news/1.mp3"},
+		".item[].enclosure.length":        {"1024"},
+		".item[].media:content.url":       {"https://library.example.edu/news/1.png"},
+		".item[].media:content.title":     {"Cover"},
+		".item[].media:content":           {""},
+		".item[].dc:creator":              {"Jane Doe"},
+		".item[].Content":                 {"", ""},
+		".item[].encoded":                 {"", ""},
+		".item[0-1].link":                 {"https://library.example.edu/news/1", "https://library.example.edu/news/2"},
+		".item[1].description":            {"Closed on Monday"},
+		".item.title":                     {"New chemistry database", "Holiday hours"},
+		".item[ 1 ].guid":                 {"news-2"},
+		".item[].media:content.type":      {"image/png"},
+		".item[].media:content.missing":   {},
+		".item[].itunes:duration":         {},
+		".item[].enclosure.type":          {"audio/mpeg"},
+		".channel.item[0].enclosure.type": {"audio/mpeg"},
+	}
+	paths := []string{}
+	for p := range strs {
+		paths = append(paths, p)
+	}
+	for p := range lists {
+		paths = append(paths, p)
+	}
+	results, err := r.Filter(paths)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	for p, expected := range strs {
+		if s, ok := results[p].(string); !ok || s != expected {
+			t.Errorf("%s: expected %q, got %#v", p, expected, results[p])
+		}
+	}
+	for p, expected := range lists {
+		vals, ok := results[p].([]string)
+		if !ok {
+			t.Errorf("%s: expected []string, got %#v", p, results[p])
+			continue
+		}
+		if strings.Join(vals, "|") != strings.Join(expected, "|") {
+			t.Errorf("%s: expected %q, got %q", p, expected, vals)
+		}
+	}
+
+	results, err = r.Filter([]string{".channel", ".item[1]", ".item[0].enclosure"})
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if m, ok := results[".channel"].(map[string]interface{}); !ok || m["title"] != "Library News" || m["item"] != nil || m["image"] != nil {
+		t.Errorf(".channel: unexpected %#v", results[".channel"])
+	}
+	// the image is only reported when the channel has one
+	withImage, err := Parse([]byte(`<rss version="2.0"><channel><title>Library News</title><link>https://library.example.edu/news</link><description>News</description><image><url>https://library.example.edu/logo.png</url><title>Library News</title><link>https://library.example.edu/news</link></image></channel></rss>`))
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	imageResults, err := withImage.Filter([]string{".channel"})
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if m, ok := imageResults[".channel"].(map[string]interface{}); !ok {
+		t.Errorf(".channel: unexpected %#v", imageResults[".channel"])
+	} else if image, ok := m["image"].(*Image); !ok || image.URL != "https://library.example.edu/logo.png" {
+		t.Errorf(".channel: unexpected image %#v", m["image"])
+	}
+	if items, ok := results[".item[1]"].([]interface{}); !ok || len(items) != 1 || items[0].(Item).GUID != "news-2" {
+		t.Errorf(".item[1]: unexpected %#v", results[".item[1]"])
+	}
+	if encs, ok := results[".item[0].enclosure"].([]interface{}); !ok || len(encs) != 1 || encs[0].(Enclosure).Type != "audio/mpeg" {
+		t.Errorf(".item[0].enclosure: unexpected %#v", results[".item[0].enclosure"])
+	}
+}
+
+func TestFilterExtensionSelectors(t *testing.T) {
+	r, err := Parse([]byte(`<rss version="2.0" xmlns:media="http://search.yahoo.com/mrss/"><channel><title>Library News</title><link>https://library.example.edu/news</link><description>News</description>
+<item><title>Gallery</title>
+<media:content url="https://library.example.edu/1.png" type="image/png"/>
+<media:content url="https://library.example.edu/2.jpg" type="image/jpeg"/>
+<media:content url="https://library.example.edu/3.gif" type="image/gif"/>
+</item>
+<item><title>No media</title></item>
+</channel></rss>`))
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	lists := map[string][]string{
+		".item[].media:content[].url":     {"https://library.example.edu/1.png", "https://library.example.edu/2.jpg", "https://library.example.edu/3.gif"},
+		".item[].media:content[0].url":    {"https://library.example.edu/1.png"},
+		".item[].media:content[1-2].type": {"image/jpeg", "image/gif"},
+		".item[0].media:content[-1].url":  {"https://library.example.edu/3.gif"},
+		".item[].media:content[]":         {"", "", ""},
+	}
+	paths := []string{}
+	for p := range lists {
+		paths = append(paths, p)
+	}
+	results, err := r.Filter(paths)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	for p, expected := range lists {
+		vals, ok := results[p].([]string)
+		if !ok {
+			t.Errorf("%s: expected []string, got %#v", p, results[p])
+			continue
+		}
+		if strings.Join(vals, "|") != strings.Join(expected, "|") {
+			t.Errorf("%s: expected %q, got %q", p, expected, vals)
+		}
+	}
+}
+
+func TestPathErrors(t *testing.T) {
+	tests := map[string]int{
+		".channel.foo.title":  9,
+		".item[].foo":         8,
+		".item[].title.first": 14,
+		"item[].title":        0,
+		".channel[]":          8,
+		".item[x]":            6,
+		".item[2-1]":          8,
+		".item[].":            8,
+		".item[0":             7,
+		".item$":              5,
+		".item[].:title":      8,
+	}
+	for src, pos := range tests {
+		_, err := CompilePath(src)
+		if err == nil {
+			t.Errorf("%s: expected an error", src)
+			continue
+		}
+		perr, ok := err.(*PathError)
+		if !ok {
+			t.Errorf("%s: expected a *PathError, got %T %s", src, err, err)
+			continue
+		}
+		if perr.Pos != pos {
+			t.Errorf("%s: expected error at %d, got %d, %s", src, pos, perr.Pos, err)
+		}
+	}
+
+	r, _ := Parse(pathTestSrc)
+	if _, err := r.Filter([]string{".item[].title", ".channel.foo.title"}); err == nil {
+		t.Errorf("expected Filter to report the bad path")
+	}
+}
+
+func TestFilterSelectors(t *testing.T) {
+	src, err := ioutil.ReadFile(path.Join("testdata", "rsdoiel.xml"))
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	r, err := Parse(src)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	// titles of the ten items in rsdoiel.xml, in document order
+	titles := []string{}
+	for _, item := range r.ItemList {
+		titles = append(titles, item.Title)
+	}
+	if len(titles) != 10 {
+		t.Errorf("expected 10 items in rsdoiel.xml, got %d", len(titles))
+		t.FailNow()
+	}
+	pick := func(indexes ...int) []string {
+		vals := []string{}
+		for _, i := range indexes {
+			vals = append(vals, titles[i])
+		}
+		return vals
+	}
+	tests := map[string][]string{
+		".item[].title":         titles,
+		".item[2].title":        pick(2),
+		".item[-1].title":       pick(9),
+		".item[-10].title":      pick(0),
+		".item[10].title":       {},
+		".item[-11].title":      {},
+		".item[1,3,-2].title":   pick(1, 3, 8),
+		".item[0-3].title":      pick(0, 1, 2, 3),
+		".item[7-20].title":     pick(7, 8, 9),
+		".item[8-].title":       pick(8, 9),
+		".item[-3-].title":      pick(7, 8, 9),
+		".item[-5:].title":      pick(5, 6, 7, 8, 9),
+		".item[:3].title":       pick(0, 1, 2),
+		".item[2:4].title":      pick(2, 3),
+		".item[::3].title":      pick(0, 3, 6, 9),
+		".item[1:6:2].title":    pick(1, 3, 5),
+		".item[::-1].title":     pick(9, 8, 7, 6, 5, 4, 3, 2, 1, 0),
+		".item[-1:-4:-1].title": pick(9, 8, 7),
+		".item[4:2].title":      {},
+		".item[:].title":        titles,
+		".item[-5:].link": {
+			r.ItemList[5].Link, r.ItemList[6].Link, r.ItemList[7].Link,
+			r.ItemList[8].Link, r.ItemList[9].Link,
+		},
+	}
+	for p, expected := range tests {
+		results, err := r.Filter([]string{p})
+		if err != nil {
+			t.Errorf("%s: %s", p, err)
+			continue
+		}
+		vals, ok := results[p].([]string)
+		if !ok {
+			t.Errorf("%s: expected []string, got %#v", p, results[p])
+			continue
+		}
+		if strings.Join(vals, "|") != strings.Join(expected, "|") {
+			t.Errorf("%s: expected %q, got %q", p, expected, vals)
+		}
+	}
+
+	for _, p := range []string{".item[::0].title", ".item[1,].title", ".item[--1].title", ".item[-2:][0].title"} {
+		if _, err := CompilePath(p); err == nil {
+			t.Errorf("%s: expected an error", p)
+		}
+	}
+}
@@ -0,0 +1,397 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//
+// Predicates
+//
+// A selector of the form [?( expr )] keeps the list elements for
+// which expr is true. Inside the expression "@" is the element being
+// tested and may be followed by a path relative to it.
+//
+//     expr       = and { "||" and }
+//     and        = unary { "&&" unary }
+//     unary      = "!" unary | "(" expr ")" | comparison
+//     comparison = operand [ op operand ]
+//     operand    = "@" { step } | string | number
+//     op         = "==" | "!=" | "<" | "<=" | ">" | ">="
+//                | "=~" | "contains"
+//
+// An operand without an operator is true when the path selects a
+// non-empty value. Strings are quoted with single or double quotes.
+// "=~" matches a Go regular expression, "contains" a substring.
+// Ordering operators compare dates when both sides parse as dates
+// (e.g. pubDate against "2016-01-01"), numbers when both sides are
+// numbers and strings otherwise. When a path selects several values
+// (e.g. @.category) the comparison is true if any value satisfies it,
+// "!=" is true when none are equal.
+//
+// Examples: .item[?(@.category == "Chemistry")].title,
+// .item[?(@.pubDate > "2016-01-01")].link,
+// .item[?(@.title =~ "(?i)raspbian" && !@.enclosure)].link
+//
+
+// predicate is a compiled filter expression
+type predicate interface {
+	// check validates paths against the element type t
+	check(src string, t reflect.Type) error
+	// test evaluates the expression for an element
+	test(v reflect.Value) bool
+}
+
+type orExpr struct {
+	left, right predicate
+}
+
+func (e *orExpr) check(src string, t reflect.Type) error {
+	if err := e.left.check(src, t); err != nil {
+		return err
+	}
+	return e.right.check(src, t)
+}
+
+func (e *orExpr) test(v reflect.Value) bool {
+	return e.left.test(v) || e.right.test(v)
+}
+
+type andExpr struct {
+	left, right predicate
+}
+
+func (e *andExpr) check(src string, t reflect.Type) error {
+	if err := e.left.check(src, t); err != nil {
+		return err
+	}
+	return e.right.check(src, t)
+}
+
+func (e *andExpr) test(v reflect.Value) bool {
+	return e.left.test(v) && e.right.test(v)
+}
+
+type notExpr struct {
+	expr predicate
+}
+
+func (e *notExpr) check(src string, t reflect.Type) error {
+	return e.expr.check(src, t)
+}
+
+func (e *notExpr) test(v reflect.Value) bool {
+	return !e.expr.test(v)
+}
+
+// operand is either a path relative to the element or a literal
+type operand struct {
+	isPath  bool
+	steps   []pathStep
+	literal string
+}
+
+func (o *operand) check(src string, t reflect.Type) error {
+	if o.isPath {
+		_, _, err := checkSteps(src, t, o.steps, false)
+		return err
+	}
+	return nil
+}
+
+// values returns the operand's values for the element v as strings.
+// Empty fields are treated as missing, use "!@.field" to test for them.
+func (o *operand) values(v reflect.Value) []string {
+	if !o.isPath {
+		return []string{o.literal}
+	}
+	vals := []string{}
+	for _, val := range flatten(evalSteps([]reflect.Value{v}, o.steps, false)) {
+		s := ""
+		switch x := leafValue(val).(type) {
+		case string:
+			s = x
+		case fmt.Stringer:
+			s = x.String()
+		default:
+			s = fmt.Sprintf("%v", x)
+		}
+		if s != "" {
+			vals = append(vals, s)
+		}
+	}
+	return vals
+}
+
+// existsExpr is true when its operand selects a non-empty value
+type existsExpr struct {
+	operand *operand
+}
+
+func (e *existsExpr) check(src string, t reflect.Type) error {
+	return e.operand.check(src, t)
+}
+
+func (e *existsExpr) test(v reflect.Value) bool {
+	if !e.operand.isPath {
+		return e.operand.literal != ""
+	}
+	for _, val := range flatten(evalSteps([]reflect.Value{v}, e.operand.steps, false)) {
+		if !val.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+type compareExpr struct {
+	left, right *operand
+	op          string
+	re          *regexp.Regexp
+}
+
+func (e *compareExpr) check(src string, t reflect.Type) error {
+	if err := e.left.check(src, t); err != nil {
+		return err
+	}
+	return e.right.check(src, t)
+}
+
+// compareValues orders a and b as dates, numbers or strings in that
+// order of preference.
+func compareValues(a, b string) int {
+	if ta, err := parseDate(a); err == nil {
+		if tb, err := parseDate(b); err == nil {
+			return compareTimes(ta, tb)
+		}
+	}
+	if fa, err := strconv.ParseFloat(strings.TrimSpace(a), 64); err == nil {
+		if fb, err := strconv.ParseFloat(strings.TrimSpace(b), 64); err == nil {
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+func compareTimes(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+// match applies the operator to a single pair of values
+func (e *compareExpr) match(a, b string) bool {
+	switch e.op {
+	case "==", "!=":
+		return a == b || strings.TrimSpace(a) == strings.TrimSpace(b)
+	case "<":
+		return compareValues(a, b) < 0
+	case "<=":
+		return compareValues(a, b) <= 0
+	case ">":
+		return compareValues(a, b) > 0
+	case ">=":
+		return compareValues(a, b) >= 0
+	case "=~":
+		return e.re.MatchString(a)
+	case "contains":
+		return strings.Contains(a, b)
+	}
+	return false
+}
+
+func (e *compareExpr) test(v reflect.Value) bool {
+	left, right := e.left.values(v), e.right.values(v)
+	for _, a := range left {
+		for _, b := range right {
+			if e.match(a, b) {
+				return e.op != "!="
+			}
+		}
+	}
+	return e.op == "!="
+}
+
+func (ps *parser) isOp(text string) bool {
+	t := ps.peek()
+	return t.kind == tokenOp && t.text == text
+}
+
+func (ps *parser) parseOr() (predicate, error) {
+	left, err := ps.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for ps.isOp("||") {
+		ps.next()
+		right, err := ps.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (ps *parser) parseAnd() (predicate, error) {
+	left, err := ps.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for ps.isOp("&&") {
+		ps.next()
+		right, err := ps.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (ps *parser) parseUnary() (predicate, error) {
+	switch {
+	case ps.isOp("!"):
+		ps.next()
+		expr, err := ps.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{expr: expr}, nil
+	case ps.peek().kind == tokenLParen:
+		ps.next()
+		expr, err := ps.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := ps.expect(tokenRParen, "\")\""); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	}
+	return ps.parseComparison()
+}
+
+func (ps *parser) parseOperand() (*operand, error) {
+	t := ps.next()
+	switch t.kind {
+	case tokenAt:
+		steps, err := ps.parseSteps()
+		if err != nil {
+			return nil, err
+		}
+		return &operand{isPath: true, steps: steps}, nil
+	case tokenString:
+		return &operand{literal: t.text}, nil
+	case tokenNumber:
+		return &operand{literal: t.text}, nil
+	case tokenDash:
+		n, err := ps.expect(tokenNumber, "a number")
+		if err != nil {
+			return nil, err
+		}
+		return &operand{literal: "-" + n.text}, nil
+	case tokenEOF:
+		return nil, ps.errorf(t, "expected \"@\", a string or a number, path ended")
+	}
+	return nil, ps.errorf(t, "expected \"@\", a string or a number")
+}
+
+func (ps *parser) parseComparison() (predicate, error) {
+	left, err := ps.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	t := ps.peek()
+	op := ""
+	switch {
+	case t.kind == tokenOp && t.text != "&&" && t.text != "||" && t.text != "!":
+		op = t.text
+	case t.kind == tokenName && t.text == "contains":
+		op = t.text
+	default:
+		return &existsExpr{operand: left}, nil
+	}
+	ps.next()
+	right, err := ps.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	expr := &compareExpr{left: left, right: right, op: op}
+	if op == "=~" {
+		if right.isPath {
+			return nil, ps.errorf(t, "regular expression must be a string")
+		}
+		if expr.re, err = regexp.Compile(right.literal); err != nil {
+			return nil, ps.errorf(t, "bad regular expression, %s", err)
+		}
+	}
+	return expr, nil
+}
+
+// Select returns a new RSS2 document with the channel of r and only
+// the items for which keep returns true.
+func (r *RSS2) Select(keep func(Item) bool) *RSS2 {
+	selected := new(RSS2)
+	*selected = *r
+	selected.ItemList = []Item{}
+	for _, item := range r.ItemList {
+		if keep(item) {
+			selected.ItemList = append(selected.ItemList, item)
+		}
+	}
+	return selected
+}
+
+// SelectPath returns a new RSS2 document with the channel of r and
+// the items selected by dataPath, e.g. .item[-5:] or
+// .item[?(@.category == "Chemistry")].
+func (r *RSS2) SelectPath(dataPath string) (*RSS2, error) {
+	p, err := CompilePath(dataPath)
+	if err != nil {
+		return nil, err
+	}
+	prefix, suffix, ok := splitAtItem(p)
+	if !ok || len(suffix) > 0 {
+		return nil, &PathError{Path: dataPath, Pos: 0, Msg: "path doesn't select items"}
+	}
+	selected := new(RSS2)
+	*selected = *r
+	selected.ItemList = []Item{}
+	for _, v := range (&Path{steps: prefix}).eval(r) {
+		selected.ItemList = append(selected.ItemList, v.Interface().(Item))
+	}
+	return selected, nil
+}
@@ -0,0 +1,137 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"io/ioutil"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestPredicates(t *testing.T) {
+	r, err := Parse(pathTestSrc)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	tests := map[string][]string{
+		`.item[?(@.category == "Chemistry")].guid`:                                {"news-1"},
+		`.item[?(@.category == 'Hours')].guid`:                                    {"news-2"},
+		`.item[?(@.category != "Chemistry")].guid`:                                {"news-2"},
+		`.item[?(@.title contains "hours")].guid`:                                 {"news-2"},
+		`.item[?(@.title =~ "(?i)^new")].guid`:                                    {"news-1"},
+		`.item[?(@.enclosure)].guid`:                                              {"news-1"},
+		`.item[?(!@.enclosure)].guid`:                                             {"news-2"},
+		`.item[?(@.enclosure.length > 1000)].guid`:                                {"news-1"},
+		`.item[?(@.enclosure.length > 2000)].guid`:                                {},
+		`.item[?(@.media:content.type == "image/png")].guid`:                      {"news-1"},
+		`.item[?(@.author || @.description)].guid`:                                {"news-1", "news-2"},
+		`.item[?(@.author && @.description)].guid`:                                {},
+		`.item[?((@.guid == "news-1" || @.guid == "news-2") && !@.author)].title`: {"Holiday hours"},
+		`.item[?(@.pubDate > "2016-01-01")].guid`:                                 {"news-1"},
+		`.item[?(@.pubDate < "2016-07-26T12:00:00Z")].guid`:                       {"news-1"},
+		`.item[?(@.pubDate < "2016-07-25")].guid`:                                 {},
+		`.item[].category[?(@ =~ "^[CD]")]`:                                       {"Chemistry", "Databases"},
+		`.item[?(@.guid == "news-2")].category`:                                   {"Hours"},
+	}
+	for p, expected := range tests {
+		results, err := r.Filter([]string{p})
+		if err != nil {
+			t.Errorf("%s: %s", p, err)
+			continue
+		}
+		vals, ok := results[p].([]string)
+		if !ok {
+			t.Errorf("%s: expected []string, got %#v", p, results[p])
+			continue
+		}
+		if strings.Join(vals, "|") != strings.Join(expected, "|") {
+			t.Errorf("%s: expected %q, got %q", p, expected, vals)
+		}
+	}
+
+	for _, p := range []string{
+		`.item[?(@.foo == "x")].title`,
+		`.item[?(@.title =~ "(")].title`,
+		`.item[?(@.title =~ @.link)].title`,
+		`.item[?(@.title == )].title`,
+		`.item[?(@.title == "x"].title`,
+		`.item[?(@.title == "x)].title`,
+		`.item[?(@.title = "x")].title`,
+	} {
+		if _, err := CompilePath(p); err == nil {
+			t.Errorf("%s: expected an error", p)
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	src, err := ioutil.ReadFile(path.Join("testdata", "rsdoiel.xml"))
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	r, err := Parse(src)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	selected := r.Select(func(item Item) bool {
+		return strings.Contains(item.Title, "Raspbian")
+	})
+	if len(selected.ItemList) != 2 {
+		t.Errorf("expected 2 items, got %d", len(selected.ItemList))
+	}
+	if selected.Title != r.Title || selected.Link != r.Link {
+		t.Errorf("expected channel to be copied, got %q %q", selected.Title, selected.Link)
+	}
+	if len(r.ItemList) != 10 {
+		t.Errorf("expected original feed to be unchanged, got %d items", len(r.ItemList))
+	}
+}
+
+func TestSelectPath(t *testing.T) {
+	r, err := Parse(pathTestSrc)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	selected, err := r.SelectPath(`.item[?(@.category == "Hours")]`)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if len(selected.ItemList) != 1 || selected.ItemList[0].GUID != "news-2" || selected.Title != r.Title {
+		t.Errorf("unexpected selection %+v", selected)
+	}
+	selected, err = r.SelectPath(".channel.item[-1:]")
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if len(selected.ItemList) != 1 || selected.ItemList[0].GUID != "news-2" {
+		t.Errorf("unexpected selection %+v", selected.ItemList)
+	}
+	for _, p := range []string{".item[].title", ".channel.title"} {
+		if _, err := r.SelectPath(p); err == nil {
+			t.Errorf("%s: expected an error", p)
+		}
+	}
+}
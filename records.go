@@ -0,0 +1,262 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var itemType = reflect.TypeOf(Item{})
+
+// Record holds the values selected for a single item. Fields lists
+// the field names in the order requested, item fields are named by
+// their path relative to the item (e.g. ".title"), other fields by
+// their full path (e.g. ".channel.title").
+type Record struct {
+	Fields []string
+	Values map[string][]string
+	// multi is true for fields that can hold several values
+	multi map[string]bool
+}
+
+// String returns the first value of field or an empty string
+func (rec Record) String(field string) string {
+	if vals := rec.Values[field]; len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+// Strings returns all the values of field
+func (rec Record) Strings(field string) []string {
+	return rec.Values[field]
+}
+
+// Time parses the first value of field as a date
+func (rec Record) Time(field string) (time.Time, error) {
+	return parseDate(rec.String(field))
+}
+
+// MarshalJSON renders the record as a JSON object with the fields in
+// requested order. Keys drop the leading period of the field name,
+// fields that can hold several values are rendered as arrays.
+func (rec Record) MarshalJSON() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteString("{")
+	for i, field := range rec.Fields {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		key, _ := json.Marshal(strings.TrimPrefix(field, "."))
+		buf.Write(key)
+		buf.WriteString(":")
+		var (
+			src []byte
+			err error
+		)
+		if rec.multi[field] {
+			vals := rec.Values[field]
+			if vals == nil {
+				vals = []string{}
+			}
+			src, err = json.Marshal(vals)
+		} else {
+			src, err = json.Marshal(rec.String(field))
+		}
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(src)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+// splitAtItem splits a compiled path into the steps that select items
+// and the steps relative to each item. ok is false if the path
+// doesn't pass through the item list.
+func splitAtItem(p *Path) ([]pathStep, []pathStep, bool) {
+	k := -1
+	for i, step := range p.steps {
+		if step.typ == itemType || step.typ == reflect.SliceOf(itemType) {
+			k = i
+			break
+		}
+	}
+	if k < 0 {
+		return nil, nil, false
+	}
+	for k+1 < len(p.steps) && p.steps[k+1].kind == stepSelect && p.steps[k+1].typ == itemType {
+		k++
+	}
+	return p.steps[:k+1], p.steps[k+1:], true
+}
+
+// stringValues converts selected values to strings
+func stringValues(values []reflect.Value) []string {
+	vals := []string{}
+	for _, v := range values {
+		vals = append(vals, fmt.Sprintf("%v", leafValue(v)))
+	}
+	return vals
+}
+
+// recordSpec is the compiled form of the data paths passed to Records
+type recordSpec struct {
+	fields    []string
+	itemSteps []pathStep
+	relative  map[string][]pathStep
+	constant  map[string]*Path
+	multi     map[string]bool
+}
+
+// compileRecords compiles data paths into a recordSpec checking that
+// item paths all select items the same way.
+func compileRecords(dataPaths []string) (*recordSpec, error) {
+	if len(dataPaths) == 0 {
+		return nil, fmt.Errorf("No data paths found")
+	}
+	spec := &recordSpec{
+		fields:   []string{},
+		relative: map[string][]pathStep{},
+		constant: map[string]*Path{},
+		multi:    map[string]bool{},
+	}
+	itemPrefix := ""
+	for _, dataPath := range dataPaths {
+		p, err := CompilePath(dataPath)
+		if err != nil {
+			return nil, err
+		}
+		prefix, suffix, ok := splitAtItem(p)
+		if !ok {
+			spec.fields = append(spec.fields, dataPath)
+			spec.constant[dataPath] = p
+			spec.multi[dataPath] = p.isList
+			continue
+		}
+		src := dataPath
+		if len(suffix) > 0 {
+			src = dataPath[0:suffix[0].start]
+		}
+		if spec.itemSteps == nil {
+			spec.itemSteps, itemPrefix = prefix, src
+		} else if src != itemPrefix {
+			return nil, &PathError{Path: dataPath, Pos: 0, Token: src, Msg: fmt.Sprintf("items selected differently than %q", itemPrefix)}
+		}
+		field := strings.TrimPrefix(dataPath, src)
+		if field == "" {
+			field = "."
+		}
+		spec.fields = append(spec.fields, field)
+		spec.relative[field] = suffix
+		_, many, _ := checkSteps(dataPath, itemType, suffix, false)
+		spec.multi[field] = many
+	}
+	return spec, nil
+}
+
+// RecordFields returns the field names Records uses for dataPaths
+func RecordFields(dataPaths []string) ([]string, error) {
+	spec, err := compileRecords(dataPaths)
+	if err != nil {
+		return nil, err
+	}
+	return spec.fields, nil
+}
+
+// Records projects the items of r into one Record per item. Each data
+// path passing through the item list (e.g. .item[-5:].title and
+// .item[-5:].link) contributes a field to every record, all of them
+// must select items the same way. Other paths, like .channel.title,
+// are repeated in each record.
+func (r *RSS2) Records(dataPaths []string) ([]Record, error) {
+	spec, err := compileRecords(dataPaths)
+	if err != nil {
+		return nil, err
+	}
+	constant := map[string][]string{}
+	for field, p := range spec.constant {
+		constant[field] = p.Strings(r)
+	}
+	records := []Record{}
+	if spec.itemSteps == nil {
+		rec := Record{Fields: spec.fields, Values: constant, multi: spec.multi}
+		return append(records, rec), nil
+	}
+	for _, item := range (&Path{steps: spec.itemSteps}).eval(r) {
+		rec := Record{Fields: spec.fields, Values: map[string][]string{}, multi: spec.multi}
+		for _, field := range spec.fields {
+			if vals, ok := constant[field]; ok {
+				rec.Values[field] = vals
+				continue
+			}
+			rec.Values[field] = stringValues(flatten(evalSteps([]reflect.Value{item}, spec.relative[field], false)))
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// Strings returns the values the path selects from r as strings
+func (p *Path) Strings(r *RSS2) []string {
+	return stringValues(p.eval(r))
+}
+
+// Times returns the values the path selects from r parsed as dates.
+// Empty values are skipped, values that aren't dates are an error.
+func (p *Path) Times(r *RSS2) ([]time.Time, error) {
+	times := []time.Time{}
+	for _, s := range p.Strings(r) {
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		t, err := parseDate(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", p.src, err)
+		}
+		times = append(times, t)
+	}
+	return times, nil
+}
+
+// Strings returns the values selected by a data path as strings,
+// e.g. r.Strings(".item[].link")
+func (r *RSS2) Strings(dataPath string) ([]string, error) {
+	p, err := CompilePath(dataPath)
+	if err != nil {
+		return nil, err
+	}
+	return p.Strings(r), nil
+}
+
+// Times returns the values selected by a data path parsed as dates,
+// e.g. r.Times(".item[].pubDate")
+func (r *RSS2) Times(dataPath string) ([]time.Time, error) {
+	p, err := CompilePath(dataPath)
+	if err != nil {
+		return nil, err
+	}
+	return p.Times(r)
+}
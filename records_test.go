@@ -0,0 +1,119 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestRecords(t *testing.T) {
+	r, err := Parse(pathTestSrc)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	records, err := r.Records([]string{".item[].title", ".item[].link", ".item[].category", ".item[].pubDate", ".channel.title"})
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if len(records) != 2 {
+		t.Errorf("expected 2 records, got %d", len(records))
+		t.FailNow()
+	}
+	rec := records[1]
+	if rec.String(".title") != "Holiday hours" || rec.String(".link") != "https://library.example.edu/news/2" {
+		t.Errorf("unexpected record %+v", rec)
+	}
+	if rec.String(".channel.title") != "Library News" {
+		t.Errorf("expected channel title in each record, got %+v", rec)
+	}
+	if vals := records[0].Strings(".category"); len(vals) != 2 || vals[1] != "Databases" {
+		t.Errorf("expected two categories, got %q", vals)
+	}
+	if d, err := records[0].Time(".pubDate"); err != nil || d.Year() != 2016 {
+		t.Errorf("expected 2016 pubDate, got %s %v", d, err)
+	}
+	if _, err := records[1].Time(".pubDate"); err == nil {
+		t.Errorf("expected an error for a missing pubDate")
+	}
+	src, err := json.Marshal(records[0])
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	expected := `{"title":"New chemistry database","link":"https://library.example.edu/news/1","category":["Chemistry","Databases"],"pubDate":"Mon, 25 Jul 2016 20:48:03 -0700","channel.title":"Library News"}`
+	if string(src) != expected {
+		t.Errorf("expected %s, got %s", expected, src)
+	}
+
+	records, err = r.Records([]string{`.item[?(@.category == "Hours")].guid`, `.item[?(@.category == "Hours")].enclosure.url`})
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if len(records) != 1 || records[0].String(".guid") != "news-2" || records[0].String(".enclosure.url") != "" {
+		t.Errorf("unexpected records %+v", records)
+	}
+
+	if _, err := r.Records([]string{".item[0].title", ".item[1].link"}); err == nil {
+		t.Errorf("expected an error for items selected differently")
+	}
+}
+
+func TestTypedResults(t *testing.T) {
+	src, err := ioutil.ReadFile(path.Join("testdata", "rsdoiel.xml"))
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	r, err := Parse(src)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	links, err := r.Strings(".item[-5:].link")
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if len(links) != 5 || links[4] != r.ItemList[9].Link {
+		t.Errorf("unexpected links %q", links)
+	}
+	dates, err := r.Times(".item[].pubDate")
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if len(dates) != 10 {
+		t.Errorf("expected 10 dates, got %d", len(dates))
+		t.FailNow()
+	}
+	expected := time.Date(2016, time.May, 28, 0, 0, 0, 0, time.UTC)
+	if !dates[0].Equal(expected) {
+		t.Errorf("expected %s, got %s", expected, dates[0])
+	}
+	if _, err := r.Times(".item[].title"); err == nil {
+		t.Errorf("expected an error parsing titles as dates")
+	}
+}
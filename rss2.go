@@ -21,9 +21,7 @@ package rss2
 import (
 	"encoding/json"
 	"encoding/xml"
-	"fmt"
-	"strconv"
-	"strings"
+	"sort"
 )
 
 const Version = `v0.0.6`
@@ -33,11 +31,18 @@ type CustomAttrs []xml.Attr
 type RSS2 struct {
 	XMLName xml.Name `xml:"rss" json:"-"`
 	Version string   `xml:"version,attr" json:"version"`
+	// Base is the rss element's xml:base, see ResolveURLs
+	Base string `xml:"http://www.w3.org/XML/1998/namespace base,attr,omitempty" json:"xml_base,omitempty"`
 
 	// Required
-	Title       string `xml:"channel>title" json:"title"`
-	Link        string `xml:"channel>link" json:"link"`
-	Description string `xml:"channel>description" json:"description"`
+	Title string `xml:"channel>title" json:"title"`
+	// AtomLinks are the channel's atom:link elements, e.g. the
+	// self link or the links between the pages of a paged feed.
+	// They come before Link so an atom:link isn't read as the
+	// channel link.
+	AtomLinks   []AtomLink `xml:"http://www.w3.org/2005/Atom channel>link,omitempty" json:"atom_link,omitempty"`
+	Link        string     `xml:"channel>link" json:"link"`
+	Description string     `xml:"channel>description" json:"description"`
 
 	// Optional
 	Language       string `xml:"channel>language,omitempty" json:"language,omitempty"`
@@ -51,7 +56,7 @@ type RSS2 struct {
 	Docs           string `xml:"channel>docs,omitempty" json:"docs,omitempty"`
 	Cloud          string `xml:"channel>cloud,omitempty" json:"cloud,omitempty"`
 	TTL            string `xml:"channel>ttl,omitempty" json:"ttl,omitempty"`
-	Image          string `xml:"channel>image,omitempty" json:"image,omitempty"`
+	Image          *Image `xml:"channel>image,omitempty" json:"image,omitempty"`
 	Rating         string `xml:"channel>rating,omitempty" json:"rating,omitempty"`
 	SkipHours      string `xml:"channel>skipHours,omitempty" json:"skipHours,omitempty"`
 	SkipDays       string `xml:"channel>skipDays,omitempty" json:"skipDays,omitempty"`
@@ -69,14 +74,74 @@ type Item struct {
 	// Optional
 	Author      string      `xml:"author,omitempty" json:"author,omitempty"`
 	Description string      `xml:"description,omitempty" json:"description,omitempty"`
-	Category    string      `xml:"category,omitempty" json:"category,omitempty"`
-	Content     string      `xml:"encoded,omitempty" json:"encoded,omitempty"`
+	Category    []string    `xml:"category,omitempty" json:"category,omitempty"`
+	Content     string      `xml:"http://purl.org/rss/1.0/modules/content/ encoded,omitempty" json:"encoded,omitempty"`
 	PubDate     string      `xml:"pubDate,omitempty" json:"pubDate,omitempty"`
 	Comments    string      `xml:"comments,omitempty" json:"comments,omitempty"`
-	Enclosure   string      `xml:"enclosure,omitempty" json:"enclosure,omitempty"`
+	Enclosure   *Enclosure  `xml:"enclosure,omitempty" json:"enclosure,omitempty"`
 	GUID        string      `xml:"guid,omitempty" json:"guid,omitempty"`
 	Source      string      `xml:"source,omitempty" json:"source,omitempty"`
 	OtherAttr   CustomAttrs `xml:",any,attr" json:"other_attrs,omitempty"`
+
+	// Extensions holds elements from other namespaces, e.g. media:content
+	Extensions []Extension `xml:",any" json:"extensions,omitempty"`
+}
+
+// Extension is an element not defined by RSS 2, typically from a
+// module namespace such as Media RSS or Dublin Core.
+type Extension struct {
+	XMLName  xml.Name    `json:"-"`
+	Attrs    CustomAttrs `xml:",any,attr" json:"attrs,omitempty"`
+	Value    string      `xml:",chardata" json:"value,omitempty"`
+	Children []Extension `xml:",any" json:"children,omitempty"`
+}
+
+// MarshalJSON renders an extension with its namespace and local name
+func (ext Extension) MarshalJSON() ([]byte, error) {
+	type extension Extension
+	return json.Marshal(struct {
+		Space string `json:"namespace,omitempty"`
+		Local string `json:"name"`
+		extension
+	}{
+		Space:     ext.XMLName.Space,
+		Local:     ext.XMLName.Local,
+		extension: extension(ext),
+	})
+}
+
+// UnmarshalJSON reads an extension written by MarshalJSON
+func (ext *Extension) UnmarshalJSON(src []byte) error {
+	type extension Extension
+	obj := struct {
+		Space string `json:"namespace"`
+		Local string `json:"name"`
+		*extension
+	}{
+		extension: (*extension)(ext),
+	}
+	if err := json.Unmarshal(src, &obj); err != nil {
+		return err
+	}
+	ext.XMLName = xml.Name{Space: obj.Space, Local: obj.Local}
+	return nil
+}
+
+// Enclosure describes a media object attached to an item
+type Enclosure struct {
+	URL    string `xml:"url,attr" json:"url"`
+	Length string `xml:"length,attr,omitempty" json:"length,omitempty"`
+	Type   string `xml:"type,attr,omitempty" json:"type,omitempty"`
+}
+
+// Image is a GIF, JPEG or PNG image displayed with the channel
+type Image struct {
+	URL         string `xml:"url" json:"url"`
+	Title       string `xml:"title" json:"title"`
+	Link        string `xml:"link" json:"link"`
+	Width       string `xml:"width,omitempty" json:"width,omitempty"`
+	Height      string `xml:"height,omitempty" json:"height,omitempty"`
+	Description string `xml:"description,omitempty" json:"description,omitempty"`
 }
 
 type CData struct {
@@ -109,176 +174,43 @@ func (cattr CustomAttrs) MarshalJSON() ([]byte, error) {
 	return json.Marshal(m)
 }
 
-// Parse return an RSS2 document as a RSS2 structure.
-func Parse(buf []byte) (*RSS2, error) {
-	data := new(RSS2)
-	err := xml.Unmarshal(buf, &data)
-	if err != nil {
-		return nil, err
-	}
-	return data, nil
-}
-
-func (r *RSS2) channel(dataPath string) (map[string]interface{}, error) {
-	results := make(map[string]interface{})
-	switch {
-	case strings.Compare(dataPath, ".channel") == 0:
-		// package and return all the channel fields
-		results[".title"] = r.Title
-		results[".link"] = r.Link
-		results[".description"] = r.Description
-		if r.PubDate != "" {
-			results[".pubDate"] = r.PubDate
-		}
-	case strings.HasSuffix(dataPath, ".title"):
-		results[".title"] = r.Title
-	case strings.HasSuffix(dataPath, ".link"):
-		results[".link"] = r.Link
-	case strings.HasSuffix(dataPath, ".description"):
-		results[".description"] = r.Description
-	case strings.HasSuffix(dataPath, ".pubDate"):
-		results[".pubDate"] = r.PubDate
-	default:
-		return nil, fmt.Errorf("Unknown data path %s", dataPath)
+// UnmarshalJSON reads the attributes written by MarshalJSON, they
+// are sorted by name.
+func (cattr *CustomAttrs) UnmarshalJSON(src []byte) error {
+	m := map[string]string{}
+	if err := json.Unmarshal(src, &m); err != nil {
+		return err
 	}
-	return results, nil
-}
-
-type rangeExpression struct {
-	first int
-	last  int
-}
-
-func getRange(listLength int, exp string) *rangeExpression {
-	rexp := new(rangeExpression)
-	rexp.first = 0
-	rexp.last = listLength - 1
-
-	if strings.Contains(exp, "-") == true {
-		nums := strings.SplitN(exp, "-", 2)
-		i, err := strconv.Atoi(nums[0])
-		if err == nil {
-			rexp.first = i
-		}
-		i, err = strconv.Atoi(nums[1])
-		if err == nil {
-			rexp.last = i
-		}
-	} else {
-		i, err := strconv.Atoi(exp)
-		if err == nil {
-			rexp.first = i
-			rexp.last = i
-		}
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
 	}
-	return rexp
-}
-
-func (rexp *rangeExpression) inRange(val int) bool {
-	if val >= rexp.first && val <= rexp.last {
-		return true
+	sort.Strings(keys)
+	*cattr = CustomAttrs{}
+	for _, k := range keys {
+		*cattr = append(*cattr, xml.Attr{Name: xml.Name{Local: k}, Value: m[k]})
 	}
-	return false
+	return nil
 }
 
-func (r *RSS2) items(dataPath string) (map[string]interface{}, error) {
-	rexp := new(rangeExpression)
-	rexp.first = 0
-	rexp.last = len(r.ItemList) - 1
-
-	// Get the range expression so we know when to add it to results.
-	s := strings.Index(dataPath, "[")
-	e := strings.Index(dataPath, "]")
-	if s >= 0 && e >= 0 {
-		rexp = getRange(len(r.ItemList), dataPath[s:e])
+// ToXML returns the RSS2 document as RSS 2 XML
+func (r *RSS2) ToXML() ([]byte, error) {
+	if r.Version == "" {
+		r.Version = "2.0"
 	}
-
-	results := make(map[string]interface{})
-	switch {
-	case strings.HasSuffix(dataPath, ".title") == true:
-		vals := []string{}
-		for i, item := range r.ItemList {
-			if rexp.inRange(i) == true {
-				vals = append(vals, item.Title)
-			}
-		}
-		results["title"] = vals
-	case strings.HasSuffix(dataPath, ".link") == true:
-		vals := []string{}
-		for i, item := range r.ItemList {
-			if rexp.inRange(i) == true {
-				vals = append(vals, item.Link)
-			}
-		}
-		results["link"] = vals
-	case strings.HasSuffix(dataPath, ".description") == true:
-		vals := []string{}
-		for i, item := range r.ItemList {
-			if rexp.inRange(i) == true {
-				vals = append(vals, item.Description)
-			}
-		}
-		results["description"] = vals
-	case strings.HasSuffix(dataPath, ".content") == true:
-		vals := []string{}
-		for i, item := range r.ItemList {
-			if rexp.inRange(i) == true {
-				vals = append(vals, item.Content)
-			}
-		}
-		results["content"] = vals
-	case strings.HasSuffix(dataPath, ".pubDate") == true:
-		vals := []string{}
-		for i, item := range r.ItemList {
-			if rexp.inRange(i) == true {
-				vals = append(vals, item.PubDate)
-			}
-		}
-		results["pubDate"] = vals
-	case strings.HasSuffix(dataPath, ".comments") == true:
-		vals := []string{}
-		for i, item := range r.ItemList {
-			if rexp.inRange(i) == true {
-				vals = append(vals, item.Comments)
-			}
-		}
-		results["comments"] = vals
+	src, err := xml.MarshalIndent(r, "", "    ")
+	if err != nil {
+		return nil, err
 	}
-	return results, nil
+	return append([]byte(xml.Header), src...), nil
 }
 
-// Filter given an RSS2 document return all the entries matching so we
-// can apply return each of the data paths requested.
-// e.g. .version, .channel.title, .channel.link, .item[].link,
-// .item[].guid, .item[].title, .item[].description
-func (r *RSS2) Filter(dataPaths []string) (map[string]interface{}, error) {
-	var (
-		err  error
-		data map[string]interface{}
-	)
-	result := make(map[string]interface{})
-	for _, dataPath := range dataPaths {
-		switch {
-		case strings.Compare(dataPath, ".version") == 0:
-			result["version"] = r.Version
-		case strings.HasPrefix(dataPath, ".channel"):
-			data, err = r.channel(dataPath)
-			// Merge data into results keyed' by path
-			for _, val := range data {
-				result[dataPath] = val
-			}
-		case strings.HasPrefix(dataPath, ".item[]"):
-			data, err = r.items(dataPath)
-			// Merge data into results keyed' by path
-			for _, val := range data {
-				result[dataPath] = val
-			}
-		default:
-			return nil, fmt.Errorf("path %q not found", dataPath)
-		}
-	}
-	if result == nil {
-		return nil, fmt.Errorf("No data paths found")
+// Parse return an RSS2 document as a RSS2 structure.
+func Parse(buf []byte) (*RSS2, error) {
+	data := new(RSS2)
+	err := xml.Unmarshal(buf, &data)
+	if err != nil {
+		return nil, err
 	}
-	return result, err
+	return data, nil
 }
@@ -0,0 +1,389 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"html"
+	"strings"
+)
+
+// Policy is an allow-list of the HTML kept by SanitizeHTML. Elements
+// not listed are removed keeping their text, except for elements such
+// as script, style and iframe which are removed with their content.
+// Comments, processing instructions and doctypes are removed.
+type Policy struct {
+	// Elements maps the allowed elements to their allowed attributes
+	Elements map[string][]string
+	// GlobalAttributes are allowed on every allowed element
+	GlobalAttributes []string
+	// URLSchemes are the schemes allowed in attributes holding URLs,
+	// e.g. href and src, attributes with other URLs are removed
+	URLSchemes []string
+	// AllowRelativeURLs allows URLs without a scheme
+	AllowRelativeURLs bool
+}
+
+// DefaultPolicy returns a policy allowing common text formatting,
+// lists, tables, links and images with http, https and mailto URLs.
+// Scripts, styles, event handler attributes, forms and embedded
+// content are removed.
+func DefaultPolicy() *Policy {
+	return &Policy{
+		Elements: map[string][]string{
+			"a": {"href", "title"}, "abbr": {"title"}, "b": nil,
+			"blockquote": {"cite"}, "br": nil, "caption": nil,
+			"cite": nil, "code": nil, "dd": nil, "del": {"datetime"},
+			"dfn": nil, "div": nil, "dl": nil, "dt": nil, "em": nil,
+			"figcaption": nil, "figure": nil, "h1": nil, "h2": nil,
+			"h3": nil, "h4": nil, "h5": nil, "h6": nil, "hr": nil,
+			"i": nil, "img": {"src", "alt", "title", "width", "height"},
+			"ins": {"datetime"}, "kbd": nil, "li": nil, "mark": nil,
+			"ol": {"start"}, "p": nil, "pre": nil, "q": {"cite"},
+			"s": nil, "samp": nil, "small": nil, "span": nil,
+			"strong": nil, "sub": nil, "sup": nil, "table": nil,
+			"tbody": nil, "td": {"colspan", "rowspan"}, "tfoot": nil,
+			"th": {"colspan", "rowspan", "scope"}, "thead": nil,
+			"time": {"datetime"}, "tr": nil, "u": nil, "ul": nil,
+			"var": nil,
+		},
+		GlobalAttributes:  []string{"lang", "dir"},
+		URLSchemes:        []string{"http", "https", "mailto"},
+		AllowRelativeURLs: true,
+	}
+}
+
+// dropContent are the elements removed along with their content when
+// they aren't allowed
+var dropContent = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true,
+	"embed": true, "applet": true, "template": true, "noscript": true,
+	"noembed": true, "noframes": true, "textarea": true, "title": true,
+	"xmp": true, "svg": true, "math": true, "select": true,
+	"head": true, "frameset": true,
+}
+
+// rawText are the elements whose content isn't parsed as HTML
+var rawText = map[string]bool{
+	"script": true, "style": true, "iframe": true, "noembed": true,
+	"noframes": true, "noscript": true, "textarea": true, "title": true,
+	"xmp": true, "plaintext": true,
+}
+
+// urlAttributes hold URLs, srcset holds a list of them
+var urlAttributes = map[string]bool{
+	"action": true, "background": true, "cite": true, "codebase": true,
+	"data": true, "formaction": true, "href": true, "icon": true,
+	"longdesc": true, "manifest": true, "ping": true, "poster": true,
+	"src": true, "srcset": true, "usemap": true, "xlink:href": true,
+}
+
+// voidElements have no content or end tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+// htmlAttr is an attribute of a start tag, the value is unescaped
+type htmlAttr struct {
+	name, value string
+}
+
+// htmlTag is a start or end tag, the name is lower case
+type htmlTag struct {
+	name        string
+	attrs       []htmlAttr
+	end         bool
+	selfClosing bool
+}
+
+// indexEndTag returns the index in s of the first "</name" in any
+// case, or -1. The search is made on s itself since lower casing can
+// change the length of other characters.
+func indexEndTag(s, name string) int {
+	end := "</" + name
+	for i := 0; i+len(end) <= len(s); i += 2 {
+		j := strings.Index(s[i:], "</")
+		if j < 0 {
+			return -1
+		}
+		i += j
+		if i+len(end) <= len(s) && strings.EqualFold(s[i:i+len(end)], end) {
+			return i
+		}
+	}
+	return -1
+}
+
+// readTag reads the tag at the start of s, "<name ...>" or "</name>",
+// returning it and the rest of s. ok is false if s doesn't start with
+// a tag. An unterminated tag is nil and consumes the rest of s.
+func readTag(s string) (tag *htmlTag, rest string, ok bool) {
+	tag = new(htmlTag)
+	i := 1
+	if strings.HasPrefix(s, "</") {
+		tag.end = true
+		i = 2
+	}
+	if i >= len(s) || !(s[i] >= 'a' && s[i] <= 'z' || s[i] >= 'A' && s[i] <= 'Z') {
+		return nil, s, false
+	}
+	start := i
+	for i < len(s) && !strings.ContainsRune(" \t\r\n\f/>", rune(s[i])) {
+		i++
+	}
+	tag.name = strings.ToLower(s[start:i])
+	for {
+		for i < len(s) && strings.ContainsRune(" \t\r\n\f/", rune(s[i])) {
+			i++
+		}
+		if i >= len(s) {
+			return nil, "", true
+		}
+		if s[i] == '>' {
+			tag.selfClosing = s[i-1] == '/'
+			return tag, s[i+1:], true
+		}
+		start = i
+		for i < len(s) && !strings.ContainsRune(" \t\r\n\f/>=", rune(s[i])) {
+			i++
+		}
+		attr := htmlAttr{name: strings.ToLower(s[start:i])}
+		for i < len(s) && strings.ContainsRune(" \t\r\n\f", rune(s[i])) {
+			i++
+		}
+		if i < len(s) && s[i] == '=' {
+			i++
+			for i < len(s) && strings.ContainsRune(" \t\r\n\f", rune(s[i])) {
+				i++
+			}
+			if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+				quote := s[i]
+				j := strings.IndexByte(s[i+1:], quote)
+				if j < 0 {
+					return nil, "", true
+				}
+				attr.value = s[i+1 : i+1+j]
+				i += j + 2
+			} else {
+				start = i
+				for i < len(s) && !strings.ContainsRune(" \t\r\n\f>", rune(s[i])) {
+					i++
+				}
+				attr.value = s[start:i]
+			}
+		}
+		attr.value = html.UnescapeString(attr.value)
+		tag.attrs = append(tag.attrs, attr)
+	}
+}
+
+// allowedURL reports if u uses one of the policy's schemes or, when
+// allowed, is relative. Browsers ignore whitespace and control
+// characters in schemes, e.g. "java\tscript:", so they are too.
+func (policy *Policy) allowedURL(u string) bool {
+	clean := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, u)
+	i := strings.IndexAny(clean, ":/?#")
+	if i < 0 || clean[i] != ':' {
+		return policy.AllowRelativeURLs
+	}
+	scheme := strings.ToLower(clean[0:i])
+	for _, s := range policy.URLSchemes {
+		if scheme == strings.ToLower(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedURLs checks the URL of an attribute or, for srcset, each of
+// the comma separated "URL width" candidates
+func (policy *Policy) allowedURLs(name, value string) bool {
+	if name != "srcset" {
+		return policy.allowedURL(value)
+	}
+	for _, candidate := range strings.Split(value, ",") {
+		if fields := strings.Fields(candidate); len(fields) > 0 && !policy.allowedURL(fields[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// SanitizeHTML returns src keeping only the elements, attributes and
+// URLs allowed by policy, nil uses DefaultPolicy. Text and attribute
+// values are re-escaped and unclosed elements are closed so the
+// result can't change the markup around it.
+func SanitizeHTML(src string, policy *Policy) string {
+	if policy == nil {
+		policy = DefaultPolicy()
+	}
+	var sb strings.Builder
+	open := []string{}
+	s := src
+	for len(s) > 0 {
+		i := strings.IndexByte(s, '<')
+		if i < 0 {
+			sb.WriteString(html.EscapeString(html.UnescapeString(s)))
+			break
+		}
+		sb.WriteString(html.EscapeString(html.UnescapeString(s[0:i])))
+		s = s[i:]
+		switch {
+		case strings.HasPrefix(s, "<!--"):
+			if end := strings.Index(s[4:], "-->"); end >= 0 {
+				s = s[4+end+3:]
+			} else {
+				s = ""
+			}
+			continue
+		case strings.HasPrefix(s, "<!") || strings.HasPrefix(s, "<?"):
+			if end := strings.IndexByte(s, '>'); end >= 0 {
+				s = s[end+1:]
+			} else {
+				s = ""
+			}
+			continue
+		}
+		tag, rest, ok := readTag(s)
+		if !ok {
+			sb.WriteString("&lt;")
+			s = s[1:]
+			continue
+		}
+		s = rest
+		if tag == nil {
+			break
+		}
+		name := tag.name
+		allowed, isAllowed := policy.Elements[name]
+		if tag.end {
+			// close the most recent matching open element and any
+			// opened after it
+			for j := len(open) - 1; j >= 0; j-- {
+				if open[j] == name {
+					for k := len(open) - 1; k >= j; k-- {
+						sb.WriteString("</" + open[k] + ">")
+					}
+					open = open[0:j]
+					break
+				}
+			}
+			continue
+		}
+		text := ""
+		if rawText[name] {
+			// the content up to the end tag isn't markup
+			j := indexEndTag(s, name)
+			if j < 0 || name == "plaintext" {
+				j = len(s)
+			}
+			text, s = s[0:j], s[j:]
+			if !isAllowed || dropContent[name] {
+				if _, rest, ok := readTag(s); ok {
+					s = rest
+				}
+				continue
+			}
+		}
+		if !isAllowed {
+			if dropContent[name] && !voidElements[name] && !tag.selfClosing {
+				// remove everything up to the matching end tag
+				depth := 1
+				for depth > 0 && len(s) > 0 {
+					j := strings.IndexByte(s, '<')
+					if j < 0 {
+						s = ""
+						break
+					}
+					s = s[j:]
+					inner, rest, ok := readTag(s)
+					if !ok {
+						s = s[1:]
+						continue
+					}
+					s = rest
+					if inner != nil && inner.name == name && !inner.selfClosing {
+						if inner.end {
+							depth--
+						} else {
+							depth++
+						}
+					}
+				}
+			}
+			continue
+		}
+		sb.WriteString("<" + name)
+		seen := map[string]bool{}
+		for _, attr := range tag.attrs {
+			if seen[attr.name] || !(contains(allowed, attr.name) || contains(policy.GlobalAttributes, attr.name)) {
+				continue
+			}
+			if urlAttributes[attr.name] && !policy.allowedURLs(attr.name, attr.value) {
+				continue
+			}
+			seen[attr.name] = true
+			sb.WriteString(" " + attr.name + `="` + html.EscapeString(attr.value) + `"`)
+		}
+		sb.WriteString(">")
+		sb.WriteString(html.EscapeString(text))
+		switch {
+		case voidElements[name]:
+		case tag.selfClosing:
+			// XHTML, e.g. <span/>
+			sb.WriteString("</" + name + ">")
+		default:
+			open = append(open, name)
+		}
+	}
+	for j := len(open) - 1; j >= 0; j-- {
+		sb.WriteString("</" + open[j] + ">")
+	}
+	return sb.String()
+}
+
+// Sanitize applies SanitizeHTML with policy to the channel description
+// and each item's description and content:encoded, nil uses
+// DefaultPolicy
+func (r *RSS2) Sanitize(policy *Policy) {
+	if policy == nil {
+		policy = DefaultPolicy()
+	}
+	r.Description = SanitizeHTML(r.Description, policy)
+	for i := range r.ItemList {
+		item := &r.ItemList[i]
+		item.Description = SanitizeHTML(item.Description, policy)
+		item.Content = SanitizeHTML(item.Content, policy)
+	}
+}
@@ -0,0 +1,125 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	tests := map[string]string{
+		// allowed markup is kept, attributes are quoted and escaped
+		`<p>Hello <b>world</b> &amp; <a href='https://example.edu/?a=1&amp;b=2' title=x>more</a></p>`: `<p>Hello <b>world</b> &amp; <a href="https://example.edu/?a=1&amp;b=2" title="x">more</a></p>`,
+		`<P LANG=fr>Bonjour<BR>tout le monde</P>`:                                                     `<p lang="fr">Bonjour<br>tout le monde</p>`,
+		`<img src="/cover.png" alt="Cover" class="big"/>`:                                             `<img src="/cover.png" alt="Cover">`,
+		`<span/>text`:     `<span></span>text`,
+		`1 < 2 and 3 > 2`: `1 &lt; 2 and 3 &gt; 2`,
+		`<a href="mailto:news@example.edu">mail</a>`:             `<a href="mailto:news@example.edu">mail</a>`,
+		`<p>unclosed <em>emphasis`:                               `<p>unclosed <em>emphasis</em></p>`,
+		`<p><em>crossed</p></em>`:                                `<p><em>crossed</em></p>`,
+		`stray </div> end tag`:                                   `stray  end tag`,
+		`<unknown>text is kept</unknown>`:                        `text is kept`,
+		`<pre>&lt;script&gt; stays text</pre>`:                   `<pre>&lt;script&gt; stays text</pre>`,
+		`<!-- comment --><!DOCTYPE html><?xml version="1.0"?>ok`: `ok`,
+
+		// XSS vectors
+		`<script>alert(1)</script>after`:                                                         `after`,
+		`<SCRIPT SRC=//evil.example/x.js></SCRIPT>`:                                              ``,
+		`<script>document.write("</scr" + "ipt>")</script>after`:                                 `after`,
+		`<img src=x onerror=alert(1)>`:                                                           `<img src="x">`,
+		`<img src="x" onerror="alert(1)"//>`:                                                     `<img src="x">`,
+		`<a href="javascript:alert(1)">x</a>`:                                                    `<a>x</a>`,
+		`<a href=" JaVaScRiPt:alert(1)">x</a>`:                                                   `<a>x</a>`,
+		`<a href="jav&#x09;ascript:alert(1)">x</a>`:                                              `<a>x</a>`,
+		`<a href="jav	ascript:alert(1)">x</a>`:                                                   `<a>x</a>`,
+		`<a href="&#106;&#97;&#118;&#97;&#115;&#99;&#114;&#105;&#112;&#116;&#58;alert(1)">x</a>`: `<a>x</a>`,
+		`<a href="vbscript:msgbox(1)">x</a>`:                                                     `<a>x</a>`,
+		`<a href="data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==">x</a>`:             `<a>x</a>`,
+		`<img src="data:image/svg+xml,<svg onload=alert(1)>">`:                                   `<img>`,
+		`<svg onload=alert(1)>`:                                                                  ``,
+		`<svg><script>alert(1)</script></svg>after`:                                              `after`,
+		`<svg/onload=alert(1)/>after`:                                                            `after`,
+		`<math><mi xlink:href="javascript:alert(1)">x</mi></math>after`:                          `after`,
+		`<iframe src="https://evil.example"></iframe>after`:                                      `after`,
+		`<style>@import "https://evil.example/x.css";</style>after`:                              `after`,
+		`<div style="background:url(javascript:alert(1))">x</div>`:                               `<div>x</div>`,
+		`<body onload=alert(1)>x`:                                                                `x`,
+		`<form action="javascript:alert(1)"><input type=submit></form>`:                          ``,
+		`<meta http-equiv="refresh" content="0;url=javascript:alert(1)">`:                        ``,
+		`<object data="javascript:alert(1)"><param name=x></object>after`:                        `after`,
+		`<base href="javascript:alert(1)//">`:                                                    ``,
+		`<<script>script>alert(1)<</script>/script>`:                                             `&lt;/script&gt;`,
+		`<scr<script>ipt>alert(1)</script>`:                                                      `ipt&gt;alert(1)`,
+		`<!--<script>-->alert(1)`:                                                                `alert(1)`,
+		`<a title='"><script>alert(1)</script>'>x</a>`:                                           `<a title="&#34;&gt;&lt;script&gt;alert(1)&lt;/script&gt;">x</a>`,
+		`<img src="x`: ``,
+		`<a href="https://example.edu" onclick="alert(1)">x</a>`:        `<a href="https://example.edu">x</a>`,
+		`<img srcset="a.png 1x, javascript:alert(1) 2x" alt=x>`:         `<img alt="x">`,
+		`<textarea><img src=x onerror=alert(1)></textarea>after`:        `after`,
+		`<noscript><p title="</noscript><img src=x onerror=alert(1)>">`: `<img src="x">&#34;&gt;`,
+
+		// raw text whose lower case form changes length
+		`<script>` + strings.Repeat("Ⱥ", 20) + `</script>after`: `after`,
+		`<script>` + strings.Repeat("İ", 20) + `</SCRIPT>after`: `after`,
+		`<style>ȺİȺ</style><p>ok</p>`:                           `<p>ok</p>`,
+	}
+	for src, expected := range tests {
+		if got := SanitizeHTML(src, nil); got != expected {
+			t.Errorf("%s\nexpected %s\ngot      %s", src, expected, got)
+		}
+	}
+
+	// a narrower policy
+	policy := &Policy{
+		Elements:   map[string][]string{"a": {"href"}, "p": nil, "img": {"src", "srcset", "alt"}},
+		URLSchemes: []string{"https"},
+	}
+	src := `<p class="x"><a href="http://example.edu">http</a> <a href="/local">relative</a> <a href="https://example.edu">https</a> <img srcset="https://example.edu/x.png 1x, https://example.edu/y.png 2x"></p>`
+	expected := `<p><a>http</a> <a>relative</a> <a href="https://example.edu">https</a> <img srcset="https://example.edu/x.png 1x, https://example.edu/y.png 2x"></p>`
+	if got := SanitizeHTML(src, policy); got != expected {
+		t.Errorf("expected %s\ngot      %s", expected, got)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	r, err := Parse(pathTestSrc)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	r.Description = `News <script>alert(1)</script>from the library`
+	r.ItemList[0].Description = `<p onclick="alert(1)">New <b>database</b></p>`
+	r.ItemList[1].Content = `<a href="javascript:alert(1)">Hours</a>`
+	r.Sanitize(nil)
+	if r.Description != "News from the library" {
+		t.Errorf("unexpected channel description %q", r.Description)
+	}
+	if r.ItemList[0].Description != "<p>New <b>database</b></p>" {
+		t.Errorf("unexpected description %q", r.ItemList[0].Description)
+	}
+	if r.ItemList[1].Description != "Closed on Monday" || r.ItemList[1].Content != "<a>Hours</a>" {
+		t.Errorf("unexpected item %q %q", r.ItemList[1].Description, r.ItemList[1].Content)
+	}
+	for _, item := range r.ItemList {
+		if strings.Contains(item.Description+item.Content, "alert") {
+			t.Errorf("expected scripts to be removed, got %+v", item)
+		}
+	}
+}
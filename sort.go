@@ -0,0 +1,135 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sortValue is an item's value for the key it is sorted by
+type sortValue struct {
+	ok bool
+	s  string
+	t  time.Time
+}
+
+// sortKey compiles a key for Sort into a function returning an
+// item's value and one comparing two values
+func sortKey(key string) (func(*Item) sortValue, func(a, b sortValue) int, error) {
+	switch key {
+	case "date":
+		value := func(item *Item) sortValue {
+			t, err := parseDate(item.PubDate)
+			return sortValue{ok: err == nil, t: t}
+		}
+		return value, func(a, b sortValue) int { return compareTimes(a.t, b.t) }, nil
+	case "title":
+		value := func(item *Item) sortValue {
+			s := strings.ToLower(strings.Join(strings.Fields(item.Title), " "))
+			return sortValue{ok: s != "", s: s}
+		}
+		return value, func(a, b sortValue) int { return strings.Compare(a.s, b.s) }, nil
+	}
+	if !strings.HasPrefix(key, ".") {
+		return nil, nil, fmt.Errorf("can't sort by %q, expected date, title or a data path like .author", key)
+	}
+	p, err := CompilePath(".item[]" + key)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, suffix, ok := splitAtItem(p)
+	if !ok || len(suffix) == 0 {
+		return nil, nil, &PathError{Path: key, Pos: 0, Msg: "path doesn't select an item field"}
+	}
+	o := &operand{isPath: true, steps: suffix}
+	value := func(item *Item) sortValue {
+		vals := o.values(reflect.ValueOf(*item))
+		if len(vals) == 0 {
+			return sortValue{}
+		}
+		return sortValue{ok: true, s: vals[0]}
+	}
+	return value, func(a, b sortValue) int { return compareValues(a.s, b.s) }, nil
+}
+
+// sortOrder returns the indexes of items in the order given by value
+// and compare, items without a value come last
+func sortOrder(items []Item, value func(*Item) sortValue, compare func(a, b sortValue) int, descending bool) []int {
+	vals := make([]sortValue, len(items))
+	order := make([]int, len(items))
+	for i := range items {
+		vals[i] = value(&items[i])
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := vals[order[i]], vals[order[j]]
+		if !a.ok || !b.ok {
+			return a.ok && !b.ok
+		}
+		if descending {
+			return compare(a, b) > 0
+		}
+		return compare(a, b) < 0
+	})
+	return order
+}
+
+// Sort orders the items by key, "date" for pubDate compared as
+// times, "title" compared ignoring case or a data path relative to
+// the item, e.g. ".author" or ".enclosure.length", whose first value
+// is compared as a date, number or text like predicates do. Items
+// without a value for key follow the others in either direction and,
+// like items with the same value, keep their order.
+func (r *RSS2) Sort(key string, descending bool) error {
+	value, compare, err := sortKey(key)
+	if err != nil {
+		return err
+	}
+	items := make([]Item, len(r.ItemList))
+	for i, k := range sortOrder(r.ItemList, value, compare, descending) {
+		items[i] = r.ItemList[k]
+	}
+	r.ItemList = items
+	return nil
+}
+
+// Newest keeps the n newest items by pubDate leaving them in their
+// order in the feed. Undated items are treated as older than any
+// dated item, among items with the same date the first are kept.
+func (r *RSS2) Newest(n int) {
+	if n < 0 || n >= len(r.ItemList) {
+		return
+	}
+	value, compare, _ := sortKey("date")
+	keep := make([]bool, len(r.ItemList))
+	for _, k := range sortOrder(r.ItemList, value, compare, true)[0:n] {
+		keep[k] = true
+	}
+	items := []Item{}
+	for i, item := range r.ItemList {
+		if keep[i] {
+			items = append(items, item)
+		}
+	}
+	r.ItemList = items
+}
@@ -0,0 +1,91 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSort(t *testing.T) {
+	src := []byte(`<rss version="2.0"><channel><title>Library News</title><link>https://library.example.edu</link><description>News</description>
+<item><title>reading group</title><author>b@library.example.edu</author><pubDate>Tue, 26 Jul 2016 09:00:00 -0700</pubDate><enclosure url="https://library.example.edu/1.mp3" length="900" type="audio/mpeg"/></item>
+<item><title>Holiday hours</title><description>Closed on Monday</description></item>
+<item><title>New chemistry database</title><author>a@library.example.edu</author><pubDate>Tue, 26 Jul 2016 15:00:00 +0000</pubDate><enclosure url="https://library.example.edu/2.mp3" length="10000" type="audio/mpeg"/></item>
+<item><title>Exhibit opening</title><pubDate>Mon, 25 Jul 2016 09:00:00 PST</pubDate></item>
+<item><description>Untitled</description><pubDate>2016-07-27T09:00:00Z</pubDate></item>
+</channel></rss>`)
+	r, err := Parse(src)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	titles := func() string {
+		l := []string{}
+		for _, item := range r.ItemList {
+			if item.Title == "" {
+				l = append(l, item.Description)
+			} else {
+				l = append(l, item.Title)
+			}
+		}
+		return strings.Join(l, "|")
+	}
+	for _, test := range []struct {
+		key        string
+		descending bool
+		expected   string
+	}{
+		// 15:00 UTC is before 09:00 -0700
+		{"date", false, "Exhibit opening|New chemistry database|reading group|Untitled|Holiday hours"},
+		{"date", true, "Untitled|reading group|New chemistry database|Exhibit opening|Holiday hours"},
+		{"title", false, "Exhibit opening|Holiday hours|New chemistry database|reading group|Untitled"},
+		{"title", true, "reading group|New chemistry database|Holiday hours|Exhibit opening|Untitled"},
+		{".author", false, "New chemistry database|reading group|Holiday hours|Exhibit opening|Untitled"},
+		// compared as numbers, not text
+		{".enclosure.length", true, "New chemistry database|reading group|Holiday hours|Exhibit opening|Untitled"},
+	} {
+		if err := r.Sort(test.key, test.descending); err != nil {
+			t.Errorf("%s", err)
+			continue
+		}
+		if result := titles(); result != test.expected {
+			t.Errorf("%q %t: expected %q, got %q", test.key, test.descending, test.expected, result)
+		}
+	}
+	for _, key := range []string{"", "author", ".nosuchfield", ".item"} {
+		if err := r.Sort(key, false); err == nil {
+			t.Errorf("%q: expected an error", key)
+		}
+	}
+
+	r, _ = Parse(src)
+	r.Newest(3)
+	if result := titles(); result != "reading group|New chemistry database|Untitled" {
+		t.Errorf("expected the 3 newest in feed order, got %q", result)
+	}
+	r.Newest(4)
+	if len(r.ItemList) != 3 {
+		t.Errorf("expected 3 items, got %d", len(r.ItemList))
+	}
+	r.Newest(0)
+	if len(r.ItemList) != 0 {
+		t.Errorf("expected no items, got %d", len(r.ItemList))
+	}
+}
@@ -0,0 +1,158 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Position is a location in a source document, Line and Column
+// count from 1 and Column counts characters
+type Position struct {
+	Offset int64 `json:"offset"`
+	Line   int   `json:"line"`
+	Column int   `json:"column"`
+}
+
+// String returns the position as "line:column"
+func (pos Position) String() string {
+	return fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+}
+
+// SourceMap maps the data paths of the elements of an RSS 2 document,
+// e.g. ".title" or ".item[3].pubDate", to where each starts. The
+// channel is mapped to "" and the rss element to ".version".
+type SourceMap map[string]Position
+
+// Lookup returns the position of path, a path without an element of
+// its own, e.g. a missing element or an attribute, falls back to its
+// nearest enclosing element
+func (sm SourceMap) Lookup(path string) (Position, bool) {
+	for {
+		if pos, ok := sm[path]; ok {
+			return pos, true
+		}
+		i := strings.LastIndexAny(path, ".[")
+		if i < 0 {
+			return Position{}, false
+		}
+		path = path[0:i]
+	}
+}
+
+// lineIndex converts byte offsets into positions
+type lineIndex struct {
+	src    []byte
+	starts []int64
+}
+
+func newLineIndex(src []byte) *lineIndex {
+	li := &lineIndex{src: src, starts: []int64{0}}
+	for i, c := range src {
+		if c == '\n' {
+			li.starts = append(li.starts, int64(i+1))
+		}
+	}
+	return li
+}
+
+func (li *lineIndex) position(offset int64) Position {
+	// binary search for the last line starting at or before offset
+	lo, hi := 0, len(li.starts)-1
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if li.starts[mid] <= offset {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	end := offset
+	if end > int64(len(li.src)) {
+		end = int64(len(li.src))
+	}
+	return Position{
+		Offset: offset,
+		Line:   lo + 1,
+		Column: utf8.RuneCount(li.src[li.starts[lo]:end]) + 1,
+	}
+}
+
+// ParseWithSourceMap parses an RSS 2 document like Parse and also
+// returns where each of its elements start, so issues found by
+// Validate can be reported with a line and column. Syntax errors
+// are returned as *xml.SyntaxError which includes the line.
+func ParseWithSourceMap(buf []byte) (*RSS2, SourceMap, error) {
+	r, err := Parse(buf)
+	if err != nil {
+		return nil, nil, err
+	}
+	li := newLineIndex(buf)
+	sm := SourceMap{}
+	decoder := xml.NewDecoder(bytes.NewReader(buf))
+	// paths holds the data path of each open element, "" for ones
+	// not mapped
+	paths := []string{}
+	items := 0
+	for {
+		offset := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			p := ""
+			switch depth := len(paths); {
+			case depth == 0 && t.Name.Local == "rss":
+				p = ".version"
+			case depth == 1 && t.Name.Local == "channel" && paths[0] != "":
+				p = "."
+			case depth == 2 && paths[1] == "." && t.Name.Local == "item":
+				p = fmt.Sprintf(".item[%d]", items)
+				items++
+			case depth > 1 && paths[depth-1] != "" && t.Name.Space == "":
+				p = strings.TrimSuffix(paths[depth-1], ".") + "." + t.Name.Local
+			}
+			if p != "" {
+				pos := li.position(offset)
+				if p == "." {
+					sm[""] = pos
+				} else if _, ok := sm[p]; !ok {
+					// repeated elements, e.g. category, map to the first
+					sm[p] = pos
+				}
+			}
+			paths = append(paths, p)
+		case xml.EndElement:
+			if len(paths) > 0 {
+				paths = paths[0 : len(paths)-1]
+			}
+		}
+	}
+	return r, sm, nil
+}
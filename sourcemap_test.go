@@ -0,0 +1,76 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestParseWithSourceMap(t *testing.T) {
+	r, sm, err := ParseWithSourceMap(pathTestSrc)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if r.Title != "Library News" || len(r.ItemList) != 2 {
+		t.Errorf("unexpected feed %+v", r)
+	}
+	tests := map[string]string{
+		".version":                 "1:1",
+		".title":                   "3:3",
+		".managingEditor":          "6:3",
+		".item[0]":                 "7:3",
+		".item[0].category":        "12:5",
+		".item[0].enclosure":       "15:5",
+		".item[0].enclosure.url":   "15:5",
+		".item[1].description":     "24:5",
+		".item[1].pubDate":         "19:3",
+		".lastBuildDate":           "2:1",
+		".item[0].media:content.x": "7:3",
+	}
+	for p, expected := range tests {
+		pos, ok := sm.Lookup(p)
+		if !ok {
+			t.Errorf("%s: not found", p)
+			continue
+		}
+		if pos.String() != expected {
+			t.Errorf("%s: expected %s, got %s", p, expected, pos)
+		}
+	}
+	if _, ok := sm.Lookup("nothing"); ok {
+		t.Errorf("expected a path outside the feed to be missing")
+	}
+
+	// columns count characters, not bytes
+	_, sm, err = ParseWithSourceMap([]byte("<rss version=\"2.0\"><channel><title>été</title><link>x</link></channel></rss>"))
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if pos := sm[".link"]; pos.String() != "1:47" || pos.Offset != 48 {
+		t.Errorf("expected .link at 1:47 (offset 48), got %s (offset %d)", pos, pos.Offset)
+	}
+
+	_, _, err = ParseWithSourceMap([]byte("<rss version=\"2.0\">\n<channel>\n<title>x</titel>\n</channel></rss>"))
+	if serr, ok := err.(*xml.SyntaxError); !ok || serr.Line != 3 {
+		t.Errorf("expected a syntax error on line 3, got %#v", err)
+	}
+}
@@ -0,0 +1,150 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// ItemReader reads the items of an RSS 2 document one at a time so
+// large feeds never need to be held in memory. Title and Link hold the
+// channel title and link once they have been read, in most feeds they
+// come before the first item.
+type ItemReader struct {
+	Title string
+	Link  string
+
+	decoder *xml.Decoder
+	// open holds the local names of the elements enclosing the
+	// decoder's position
+	open []string
+	// channel is set once the channel element has been seen
+	channel bool
+}
+
+// NewItemReader returns an ItemReader decoding the RSS 2 document in
+func NewItemReader(in io.Reader) *ItemReader {
+	return &ItemReader{decoder: xml.NewDecoder(in)}
+}
+
+// Next returns the next item of the document or io.EOF after the last
+func (ir *ItemReader) Next() (*Item, error) {
+	for {
+		token, err := ir.decoder.Token()
+		if err == io.EOF {
+			if len(ir.open) > 0 {
+				return nil, fmt.Errorf("unexpected end of document in <%s>", ir.open[len(ir.open)-1])
+			}
+			if !ir.channel {
+				// empty input or text without any elements
+				return nil, fmt.Errorf("expected an RSS 2 document, no <rss><channel> found")
+			}
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			inChannel := len(ir.open) == 2 && ir.open[1] == "channel" && t.Name.Space == ""
+			switch {
+			case len(ir.open) == 0 && t.Name.Local != "rss":
+				return nil, fmt.Errorf("expected an RSS 2 document, found <%s>", t.Name.Local)
+			case inChannel && t.Name.Local == "item":
+				item := new(Item)
+				if err := ir.decoder.DecodeElement(item, &t); err != nil {
+					return nil, err
+				}
+				return item, nil
+			case inChannel && t.Name.Local == "title":
+				if err := ir.decoder.DecodeElement(&ir.Title, &t); err != nil {
+					return nil, err
+				}
+				continue
+			case inChannel && t.Name.Local == "link":
+				if err := ir.decoder.DecodeElement(&ir.Link, &t); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if len(ir.open) == 1 && t.Name.Local == "channel" {
+				ir.channel = true
+			}
+			ir.open = append(ir.open, t.Name.Local)
+		case xml.EndElement:
+			ir.open = ir.open[0 : len(ir.open)-1]
+		}
+	}
+}
+
+// NDJSONItem is the object WriteNDJSON writes for each item, the item
+// optionally annotated with where it came from.
+type NDJSONItem struct {
+	*Item
+	ChannelTitle string `json:"channel_title,omitempty"`
+	ChannelLink  string `json:"channel_link,omitempty"`
+	Filename     string `json:"filename,omitempty"`
+}
+
+// NDJSONOptions controls the annotations WriteNDJSON adds to items
+type NDJSONOptions struct {
+	// Channel adds the channel title and link to each item
+	Channel bool
+	// Filename, if not empty, is added to each item
+	Filename string
+	// Policy, if not nil, sanitizes each item's description and
+	// content:encoded, see SanitizeHTML
+	Policy *Policy
+}
+
+// WriteNDJSON streams the items of the RSS 2 document read from in to
+// w as newline delimited JSON, one item per line. It returns the
+// number of items written.
+func WriteNDJSON(w io.Writer, in io.Reader, opts *NDJSONOptions) (int, error) {
+	if opts == nil {
+		opts = new(NDJSONOptions)
+	}
+	reader := NewItemReader(in)
+	encoder := json.NewEncoder(w)
+	count := 0
+	for {
+		item, err := reader.Next()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		if opts.Policy != nil {
+			item.Description = SanitizeHTML(item.Description, opts.Policy)
+			item.Content = SanitizeHTML(item.Content, opts.Policy)
+		}
+		obj := NDJSONItem{Item: item, Filename: opts.Filename}
+		if opts.Channel {
+			obj.ChannelTitle, obj.ChannelLink = reader.Title, reader.Link
+		}
+		if err := encoder.Encode(obj); err != nil {
+			return count, err
+		}
+		count++
+	}
+}
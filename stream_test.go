@@ -0,0 +1,114 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestItemReader(t *testing.T) {
+	src, err := ioutil.ReadFile(path.Join("testdata", "rsdoiel.xml"))
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	r, err := Parse(src)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	reader := NewItemReader(bytes.NewReader(src))
+	for i := 0; ; i++ {
+		item, err := reader.Next()
+		if err == io.EOF {
+			if i != len(r.ItemList) {
+				t.Errorf("expected %d items, got %d", len(r.ItemList), i)
+			}
+			break
+		}
+		if err != nil {
+			t.Errorf("%s", err)
+			t.FailNow()
+		}
+		if i >= len(r.ItemList) || item.Title != r.ItemList[i].Title || item.Link != r.ItemList[i].Link || item.PubDate != r.ItemList[i].PubDate {
+			t.Errorf("item %d doesn't match Parse, %+v", i, item)
+		}
+		if reader.Title != r.Title || reader.Link != r.Link {
+			t.Errorf("expected channel %q %q, got %q %q", r.Title, r.Link, reader.Title, reader.Link)
+		}
+	}
+
+	for _, bad := range []string{`<feed><entry/></feed>`, `<rss><channel><item><title>x</title>`, `<rss><channel><item><title>x</item>`, ``, `not XML at all`, `<rss version="2.0"/>`} {
+		reader := NewItemReader(strings.NewReader(bad))
+		var err error
+		for err == nil {
+			_, err = reader.Next()
+		}
+		if err == io.EOF {
+			t.Errorf("%s: expected an error", bad)
+		}
+	}
+	// a channel without items is fine
+	if _, err := NewItemReader(strings.NewReader(`<rss version="2.0"><channel><title>x</title></channel></rss>`)).Next(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	n, err := WriteNDJSON(buf, bytes.NewReader(pathTestSrc), &NDJSONOptions{Channel: true, Filename: "news.xml"})
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if n != 2 {
+		t.Errorf("expected 2 items, got %d", n)
+	}
+	scanner := bufio.NewScanner(buf)
+	guids := []string{}
+	for scanner.Scan() {
+		obj := map[string]interface{}{}
+		if err := json.Unmarshal(scanner.Bytes(), &obj); err != nil {
+			t.Errorf("%s, %s", err, scanner.Text())
+			continue
+		}
+		if obj["channel_title"] != "Library News" || obj["channel_link"] != "https://library.example.edu/news" || obj["filename"] != "news.xml" {
+			t.Errorf("missing annotations in %s", scanner.Text())
+		}
+		guids = append(guids, obj["guid"].(string))
+	}
+	if strings.Join(guids, ",") != "news-1,news-2" {
+		t.Errorf("expected news-1,news-2, got %q", guids)
+	}
+
+	buf.Reset()
+	if _, err := WriteNDJSON(buf, bytes.NewReader(pathTestSrc), nil); err != nil {
+		t.Errorf("%s", err)
+	}
+	if strings.Contains(buf.String(), "channel_title") || strings.Contains(buf.String(), "filename") {
+		t.Errorf("expected no annotations, got %s", buf.String())
+	}
+}
@@ -0,0 +1,166 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// paragraphTags are the elements that start a new paragraph of text
+var paragraphTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"details": true, "div": true, "dl": true, "fieldset": true,
+	"figcaption": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true,
+	"h6": true, "header": true, "hr": true, "main": true, "nav": true,
+	"ol": true, "p": true, "pre": true, "section": true, "summary": true,
+	"table": true, "ul": true,
+}
+
+// lineTags are the elements that start a new line of text
+var lineTags = map[string]bool{
+	"br": true, "dd": true, "dt": true, "li": true, "tr": true,
+}
+
+var (
+	// autolink matches text such as <http://example.edu> that would
+	// otherwise be taken for a tag, e.g. in a description escaped
+	// as plain text
+	autolink = regexp.MustCompile(`^<([a-zA-Z][a-zA-Z0-9+.-]*:[^\s<>"]+|[^\s<>"@]+@[^\s<>"]+)>`)
+	// blankLines separate paragraphs of plain text
+	blankLines = regexp.MustCompile(`\n[ \t\r]*\n`)
+)
+
+// the paragraph and line breaks found converting HTML to text
+const (
+	paragraphBreak = "\x00"
+	lineBreak      = "\x01"
+)
+
+// htmlText converts an HTML fragment to plain text. Tags, comments
+// and the contents of script and style elements are removed, entities
+// decoded and white space collapsed. Paragraphs are separated by a
+// blank line and line breaks, e.g. from br and li, kept. In text
+// without any markup blank lines separate paragraphs.
+func htmlText(s string) string {
+	var sb strings.Builder
+	markup := false
+	for len(s) > 0 {
+		i := strings.IndexByte(s, '<')
+		if i < 0 {
+			sb.WriteString(s)
+			break
+		}
+		sb.WriteString(s[0:i])
+		s = s[i:]
+		if m := autolink.FindStringSubmatch(s); m != nil {
+			sb.WriteString(m[1])
+			s = s[len(m[0]):]
+			continue
+		}
+		// only the start of s is lower cased, other characters can
+		// change length
+		lower := s
+		if len(lower) > len("<script") {
+			lower = lower[0:len("<script")]
+		}
+		lower = strings.ToLower(lower)
+		switch {
+		case strings.HasPrefix(s, "<!--"):
+			markup = true
+			end := strings.Index(s, "-->")
+			if end < 0 {
+				s = ""
+			} else {
+				s = s[end+3:]
+			}
+			continue
+		case strings.HasPrefix(lower, "<script") || strings.HasPrefix(lower, "<style"):
+			name := "script"
+			if strings.HasPrefix(lower, "<style") {
+				name = "style"
+			}
+			end := indexEndTag(s, name)
+			if end < 0 {
+				s = ""
+				continue
+			}
+			s = s[end:]
+		case len(s) > 1 && !(s[1] == '/' || s[1] == '!' || s[1] >= 'a' && s[1] <= 'z' || s[1] >= 'A' && s[1] <= 'Z'):
+			// a "<" that doesn't start a tag
+			sb.WriteByte('<')
+			s = s[1:]
+			continue
+		}
+		end := tagEnd(s)
+		if end < 0 {
+			break
+		}
+		markup = true
+		// block level tags separate words, inline ones don't
+		switch name := tagName(s[0:end]); {
+		case paragraphTags[name]:
+			sb.WriteString(paragraphBreak)
+		case lineTags[name]:
+			sb.WriteString(lineBreak)
+		case !inlineTags[name]:
+			sb.WriteByte(' ')
+		}
+		s = s[end+1:]
+	}
+	text := sb.String()
+	if !markup {
+		text = blankLines.ReplaceAllString(text, paragraphBreak)
+	}
+	text = html.UnescapeString(text)
+	paragraphs := []string{}
+	for _, paragraph := range strings.Split(text, paragraphBreak) {
+		lines := []string{}
+		for _, line := range strings.Split(paragraph, lineBreak) {
+			if line = strings.Join(strings.Fields(line), " "); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) > 0 {
+			paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+		}
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// PlainText returns the item's description, or its content:encoded
+// when there is no description, as plain text. Markup is removed,
+// entities decoded and white space collapsed keeping paragraphs,
+// separated by a blank line, and line breaks.
+func (item *Item) PlainText() string {
+	if strings.TrimSpace(item.Description) == "" {
+		return htmlText(item.Content)
+	}
+	return htmlText(item.Description)
+}
+
+// Excerpt returns the item's plain text on a single line shortened to
+// at most n characters, breaking between words and ending with an
+// ellipsis when it was cut, e.g. for a search index or an email
+// subject. A value of n of 0 or less returns all of the text.
+func (item *Item) Excerpt(n int) string {
+	return Truncate(n, strings.Join(strings.Fields(item.PlainText()), " "))
+}
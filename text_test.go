@@ -0,0 +1,78 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"testing"
+)
+
+func TestPlainText(t *testing.T) {
+	// the CaltechAUTHORS description from TestRSS2, plain text with an
+	// escaped link
+	caltechAuthors := []byte(`<rss version="2.0"><channel><item>
+  <title>Flow-through Capture</title>
+  <description>  Schlappi, Travis S. and McCalla, Stephanie E.  (2016)  Flow-through Capture.  Analytical Chemistry .    ISSN 0003-2700.      (In Press)  http://resolver.caltech.edu/CaltechAUTHORS:20160725-102649276 &lt;http://resolver.caltech.edu/CaltechAUTHORS:20160725-102649276&gt;  </description></item></channel></rss>`)
+	r, err := Parse(caltechAuthors)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	item := r.ItemList[0]
+	expected := "Schlappi, Travis S. and McCalla, Stephanie E. (2016) Flow-through Capture. Analytical Chemistry . ISSN 0003-2700. (In Press) http://resolver.caltech.edu/CaltechAUTHORS:20160725-102649276 http://resolver.caltech.edu/CaltechAUTHORS:20160725-102649276"
+	if s := item.PlainText(); s != expected {
+		t.Errorf("expected\n%q\ngot\n%q", expected, s)
+	}
+	expected = "Schlappi, Travis S. and McCalla, Stephanie E. (2016)…"
+	if s := item.Excerpt(60); s != expected {
+		t.Errorf("expected %q, got %q", expected, s)
+	}
+
+	tests := map[string]string{
+		"<p>One  <b>bold</b>\n word.</p><p>Two &amp; three</p>":                 "One bold word.\n\nTwo & three",
+		"<h2>Title</h2>Text<br/>next line<ul><li>a</li><li>b</li></ul>":         "Title\n\nText\nnext line\n\na\nb",
+		"<div><p>Nested</p></div><p></p><p>  </p><p>Last</p>":                   "Nested\n\nLast",
+		"Plain text\n\n  second paragraph\nsame paragraph":                      "Plain text\n\nsecond paragraph same paragraph",
+		"Escaped &lt;b&gt;markup&lt;/b&gt; stays text":                          "Escaped <b>markup</b> stays text",
+		"Mail <news@library.example.edu> today":                                 "Mail news@library.example.edu today",
+		"<script>alert(1)</script><table><tr><td>a</td><td>b</td></tr></table>": "a b",
+		"<script>ȺȺȺȺȺȺȺȺ</SCRIPT><p>İİ after</p>":                              "İİ after",
+	}
+	for src, expected := range tests {
+		item := &Item{Description: src}
+		if s := item.PlainText(); s != expected {
+			t.Errorf("%q: expected %q, got %q", src, expected, s)
+		}
+	}
+
+	item = Item{Content: "<p>From content</p><p>encoded</p>"}
+	if s := item.PlainText(); s != "From content\n\nencoded" {
+		t.Errorf("expected the content:encoded text, got %q", s)
+	}
+	if s := item.Excerpt(0); s != "From content encoded" {
+		t.Errorf("expected a single line excerpt, got %q", s)
+	}
+	if s := item.Excerpt(14); s != "From content…" {
+		t.Errorf("expected a truncated excerpt, got %q", s)
+	}
+	// the excerpt ends between words even when the last word is long
+	item = Item{Description: "<p>Read the report at https://library.example.edu/reports/2016/annual.pdf</p>"}
+	if s := item.Excerpt(40); s != "Read the report at…" {
+		t.Errorf("expected the excerpt to end before the URL, got %q", s)
+	}
+}
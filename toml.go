@@ -0,0 +1,534 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// tomlKey quotes key unless it is a bare key
+func tomlKey(key string) string {
+	if key == "" {
+		return `""`
+	}
+	for _, c := range key {
+		if !(c == '_' || c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return jsonString(key)
+		}
+	}
+	return key
+}
+
+// tomlString formats s, multi-line text is written as a literal
+// multi-line string when it can be
+func tomlString(s string) string {
+	if strings.Contains(s, "\n") && !strings.Contains(s, "'''") && !strings.HasSuffix(s, "'") {
+		literal := true
+		for _, c := range s {
+			if c < ' ' && c != '\n' && c != '\t' || c == 0x7f {
+				literal = false
+				break
+			}
+		}
+		if literal {
+			return "'''\n" + s + "'''"
+		}
+	}
+	// JSON string escapes are a subset of TOML's basic string escapes
+	return jsonString(s)
+}
+
+// tomlInline formats a value that isn't a table
+func tomlInline(n *node) string {
+	switch n.kind {
+	case scalarNode:
+		if n.quoted {
+			return tomlString(n.text)
+		}
+		return n.text
+	case listNode:
+		vals := []string{}
+		for _, item := range n.items {
+			if item.kind != nullNode {
+				vals = append(vals, tomlInline(item))
+			}
+		}
+		return "[" + strings.Join(vals, ", ") + "]"
+	case mapNode:
+		vals := []string{}
+		for i, key := range n.keys {
+			if n.items[i].kind != nullNode {
+				vals = append(vals, tomlKey(key)+" = "+tomlInline(n.items[i]))
+			}
+		}
+		if len(vals) == 0 {
+			return "{}"
+		}
+		return "{ " + strings.Join(vals, ", ") + " }"
+	}
+	return `""`
+}
+
+// isTableArray reports if n is a non-empty list of maps, written as
+// an array of tables
+func isTableArray(n *node) bool {
+	if n.kind != listNode || len(n.items) == 0 {
+		return false
+	}
+	for _, item := range n.items {
+		if item.kind != mapNode {
+			return false
+		}
+	}
+	return true
+}
+
+// writeTOML writes the keys of a table. TOML requires a table's
+// values before its sub-tables, so values are written first, then
+// tables, then arrays of tables, each in field order. Null values
+// have no TOML form and are left out.
+func writeTOML(buf *bytes.Buffer, n *node, path []string) {
+	for i, key := range n.keys {
+		item := n.items[i]
+		if item.kind == nullNode || item.kind == mapNode || isTableArray(item) {
+			continue
+		}
+		fmt.Fprintf(buf, "%s = %s\n", tomlKey(key), tomlInline(item))
+	}
+	for i, key := range n.keys {
+		if item := n.items[i]; item.kind == mapNode {
+			sub := append(append([]string{}, path...), tomlKey(key))
+			fmt.Fprintf(buf, "\n[%s]\n", strings.Join(sub, "."))
+			writeTOML(buf, item, sub)
+		}
+	}
+	for i, key := range n.keys {
+		if item := n.items[i]; isTableArray(item) {
+			sub := append(append([]string{}, path...), tomlKey(key))
+			for _, elem := range item.items {
+				fmt.Fprintf(buf, "\n[[%s]]\n", strings.Join(sub, "."))
+				writeTOML(buf, elem, sub)
+			}
+		}
+	}
+}
+
+// ToTOML returns r as a TOML document. The channel fields are top
+// level keys and each item is an [[item]] table.
+func (r *RSS2) ToTOML() ([]byte, error) {
+	n, err := r.tree()
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	writeTOML(buf, n, nil)
+	return buf.Bytes(), nil
+}
+
+// tomlBare matches the values written without quotes, booleans,
+// integers, floats and dates or times
+var tomlBare = regexp.MustCompile(`^(true|false|[+-]?(inf|nan)|[+-]?[0-9][0-9_]*(\.[0-9][0-9_]*)?([eE][+-]?[0-9][0-9_]*)?|0x[0-9a-fA-F_]+|0o[0-7_]+|0b[01_]+|[0-9]{4}-[0-9]{2}-[0-9]{2}([Tt ][0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?([Zz]|[+-][0-9]{2}:[0-9]{2})?)?|[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?)$`)
+
+// tomlParser reads the TOML subset described in yaml.go into a node
+// tree. Numbers, booleans and dates are kept as their text since the
+// RSS 2 fields are all strings.
+type tomlParser struct {
+	src string
+	pos int
+	// defined records the tables created by headers
+	defined map[*node]bool
+}
+
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	line := strings.Count(p.src[0:p.pos], "\n") + 1
+	return fmt.Errorf("toml line %d, %s", line, fmt.Sprintf(format, args...))
+}
+
+func (p *tomlParser) peek() byte {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+// skip passes over spaces and tabs, with newlines true also newlines
+// and comments
+func (p *tomlParser) skip(newlines bool) {
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == ' ' || c == '\t':
+			p.pos++
+		case c == '#' && newlines:
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		case (c == '\n' || c == '\r') && newlines:
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// endOfLine expects only a comment before the next line
+func (p *tomlParser) endOfLine() error {
+	p.skip(false)
+	if p.peek() == '#' {
+		for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+			p.pos++
+		}
+	}
+	switch p.peek() {
+	case 0, '\n', '\r':
+		return nil
+	}
+	return p.errorf("unexpected %q", p.src[p.pos:p.pos+1])
+}
+
+// parseKey reads a dotted key
+func (p *tomlParser) parseKey() ([]string, error) {
+	parts := []string{}
+	for {
+		p.skip(false)
+		switch c := p.peek(); {
+		case c == '"' || c == '\'':
+			s, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, s)
+		default:
+			start := p.pos
+			for p.pos < len(p.src) {
+				c := p.src[p.pos]
+				if !(c == '_' || c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+					break
+				}
+				p.pos++
+			}
+			if p.pos == start {
+				return nil, p.errorf("expected a key")
+			}
+			parts = append(parts, p.src[start:p.pos])
+		}
+		p.skip(false)
+		if p.peek() != '.' {
+			return parts, nil
+		}
+		p.pos++
+	}
+}
+
+// parseString reads a basic, literal or multi-line string
+func (p *tomlParser) parseString() (string, error) {
+	quote := p.src[p.pos : p.pos+1]
+	multi := strings.HasPrefix(p.src[p.pos:], strings.Repeat(quote, 3))
+	if multi {
+		p.pos += 3
+		// a newline right after the opening quotes is trimmed
+		if strings.HasPrefix(p.src[p.pos:], "\r\n") {
+			p.pos += 2
+		} else if p.peek() == '\n' {
+			p.pos++
+		}
+	} else {
+		p.pos++
+	}
+	var sb strings.Builder
+	for {
+		if p.pos >= len(p.src) {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.src[p.pos]
+		switch {
+		case multi && strings.HasPrefix(p.src[p.pos:], strings.Repeat(quote, 3)):
+			p.pos += 3
+			// up to two quotes may sit before the closing ones
+			for i := 0; i < 2 && p.pos < len(p.src) && p.src[p.pos:p.pos+1] == quote; i++ {
+				sb.WriteString(quote)
+				p.pos++
+			}
+			return sb.String(), nil
+		case !multi && c == quote[0]:
+			p.pos++
+			return sb.String(), nil
+		case !multi && c == '\n':
+			return "", p.errorf("newline in string")
+		case c == '\\' && quote == `"`:
+			p.pos++
+			if p.pos >= len(p.src) {
+				return "", p.errorf("unterminated string")
+			}
+			switch e := p.src[p.pos]; e {
+			case 'b':
+				sb.WriteByte('\b')
+			case 't':
+				sb.WriteByte('\t')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'r':
+				sb.WriteByte('\r')
+			case 'e':
+				sb.WriteByte(0x1b)
+			case '"', '\\':
+				sb.WriteByte(e)
+			case 'u', 'U':
+				size := 4
+				if e == 'U' {
+					size = 8
+				}
+				if p.pos+size >= len(p.src) {
+					return "", p.errorf("bad escape")
+				}
+				code, err := strconv.ParseUint(p.src[p.pos+1:p.pos+1+size], 16, 32)
+				if err != nil {
+					return "", p.errorf("bad escape")
+				}
+				r := rune(code)
+				p.pos += size
+				// join UTF-16 surrogate pairs written by JSON encoders
+				if r >= 0xd800 && r < 0xdc00 && strings.HasPrefix(p.src[p.pos+1:], `\u`) && p.pos+7 <= len(p.src) {
+					if low, err := strconv.ParseUint(p.src[p.pos+3:p.pos+7], 16, 32); err == nil && low >= 0xdc00 && low < 0xe000 {
+						r = (r-0xd800)<<10 + (rune(low) - 0xdc00) + 0x10000
+						p.pos += 6
+					}
+				}
+				sb.WriteRune(r)
+			case ' ', '\t', '\r', '\n':
+				if !multi {
+					return "", p.errorf("bad escape")
+				}
+				// a line ending backslash trims the whitespace
+				// that follows
+				for p.pos < len(p.src) && strings.IndexByte(" \t\r\n", p.src[p.pos]) >= 0 {
+					p.pos++
+				}
+				continue
+			default:
+				return "", p.errorf("bad escape \\%c", e)
+			}
+			p.pos++
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// parseValue reads a value
+func (p *tomlParser) parseValue() (*node, error) {
+	p.skip(false)
+	switch c := p.peek(); {
+	case c == '"' || c == '\'':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return yamlString(s), nil
+	case c == '[':
+		p.pos++
+		n := &node{kind: listNode}
+		for {
+			p.skip(true)
+			if p.peek() == ']' {
+				p.pos++
+				return n, nil
+			}
+			item, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			n.items = append(n.items, item)
+			p.skip(true)
+			switch p.peek() {
+			case ',':
+				p.pos++
+			case ']':
+			default:
+				return nil, p.errorf("expected \",\" or \"]\" in array")
+			}
+		}
+	case c == '{':
+		p.pos++
+		n := &node{kind: mapNode}
+		for {
+			p.skip(false)
+			if p.peek() == '}' {
+				p.pos++
+				return n, nil
+			}
+			if len(n.keys) > 0 {
+				if p.peek() != ',' {
+					return nil, p.errorf("expected \",\" or \"}\" in inline table")
+				}
+				p.pos++
+			}
+			if err := p.parseKeyValue(n); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// numbers, booleans and dates
+	start := p.pos
+	for p.pos < len(p.src) && strings.IndexByte(",]}#\r\n", p.src[p.pos]) < 0 {
+		p.pos++
+	}
+	text := strings.TrimSpace(p.src[start:p.pos])
+	if text == "" {
+		return nil, p.errorf("expected a value")
+	}
+	if !tomlBare.MatchString(text) {
+		return nil, p.errorf("%q is not a string, number, boolean or date", text)
+	}
+	return yamlString(text), nil
+}
+
+// parseKeyValue reads "key = value" into table
+func (p *tomlParser) parseKeyValue(table *node) error {
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	if p.peek() != '=' {
+		return p.errorf("expected \"=\" after %q", strings.Join(keys, "."))
+	}
+	p.pos++
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys[0 : len(keys)-1] {
+		next := table.get(key)
+		if next == nil {
+			next = &node{kind: mapNode}
+			table.set(key, next)
+		} else if next.kind != mapNode {
+			return p.errorf("%q is not a table", key)
+		}
+		table = next
+	}
+	last := keys[len(keys)-1]
+	if table.get(last) != nil {
+		return p.errorf("duplicate key %q", last)
+	}
+	table.set(last, value)
+	return nil
+}
+
+// table finds or creates the table a header names, the last element of
+// an array of tables is used along the way
+func (p *tomlParser) table(root *node, keys []string, array bool) (*node, error) {
+	n := root
+	for i, key := range keys {
+		next := n.get(key)
+		last := i == len(keys)-1
+		switch {
+		case last && array:
+			if next == nil {
+				next = &node{kind: listNode}
+				n.set(key, next)
+			} else if !isTableArray(next) {
+				return nil, p.errorf("%q is not an array of tables", key)
+			}
+			elem := &node{kind: mapNode}
+			next.items = append(next.items, elem)
+			return elem, nil
+		case next == nil:
+			next = &node{kind: mapNode}
+			n.set(key, next)
+		case isTableArray(next) && last:
+			return nil, p.errorf("%q is an array of tables", key)
+		case isTableArray(next):
+			next = next.items[len(next.items)-1]
+		case next.kind != mapNode:
+			return nil, p.errorf("%q is not a table", key)
+		}
+		if last {
+			if p.defined[next] {
+				return nil, p.errorf("table %q is defined twice", strings.Join(keys, "."))
+			}
+			p.defined[next] = true
+		}
+		n = next
+	}
+	return n, nil
+}
+
+// tomlTree reads a TOML document as a node tree
+func tomlTree(buf []byte) (*node, error) {
+	if !utf8.Valid(buf) {
+		return nil, fmt.Errorf("toml must be UTF-8")
+	}
+	p := &tomlParser{src: strings.TrimPrefix(string(buf), "\ufeff"), defined: map[*node]bool{}}
+	root := &node{kind: mapNode}
+	current := root
+	for {
+		p.skip(true)
+		if p.pos >= len(p.src) {
+			break
+		}
+		if p.peek() == '[' {
+			array := strings.HasPrefix(p.src[p.pos:], "[[")
+			if array {
+				p.pos += 2
+			} else {
+				p.pos++
+			}
+			keys, err := p.parseKey()
+			if err != nil {
+				return nil, err
+			}
+			closing := "]"
+			if array {
+				closing = "]]"
+			}
+			if !strings.HasPrefix(p.src[p.pos:], closing) {
+				return nil, p.errorf("expected %q", closing)
+			}
+			p.pos += len(closing)
+			if current, err = p.table(root, keys, array); err != nil {
+				return nil, err
+			}
+		} else if err := p.parseKeyValue(current); err != nil {
+			return nil, err
+		}
+		if err := p.endOfLine(); err != nil {
+			return nil, err
+		}
+	}
+	return root, nil
+}
+
+// ParseTOML reads an RSS 2 document written as TOML, e.g. by ToTOML,
+// using the field names of the JSON form. Only the TOML subset listed
+// in yaml.go is supported.
+func ParseTOML(buf []byte) (*RSS2, error) {
+	n, err := tomlTree(buf)
+	if err != nil {
+		return nil, err
+	}
+	return treeToRSS2(n)
+}
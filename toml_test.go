@@ -0,0 +1,152 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"io/ioutil"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestTOMLRoundTrip(t *testing.T) {
+	src, err := ioutil.ReadFile(path.Join("testdata", "rsdoiel.xml"))
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	for _, xmlSrc := range [][]byte{src, pathTestSrc} {
+		r, err := Parse(xmlSrc)
+		if err != nil {
+			t.Errorf("%s", err)
+			t.FailNow()
+		}
+		r.ItemList[0].Description = "First paragraph.\n\n  Indented: \"quoted\" # not a comment\n"
+		r.ItemList[0].Comments = "ends with a quote '\nreally'"
+		r.ItemList[0].Source = "has ''' quotes\n"
+		r.ItemList[0].GUID = "tab\there, \\ backslash, emoji \U0001F600"
+		tomlSrc, err := r.ToTOML()
+		if err != nil {
+			t.Errorf("%s", err)
+			t.FailNow()
+		}
+		r2, err := ParseTOML(tomlSrc)
+		if err != nil {
+			t.Errorf("%s\n%s", err, tomlSrc)
+			t.FailNow()
+		}
+		sameJSON(t, r, r2)
+	}
+}
+
+func TestToTOML(t *testing.T) {
+	r, _ := Parse(pathTestSrc)
+	r.ItemList[1].Description = "Closed on Monday.\nOpen Tuesday."
+	src, err := r.ToTOML()
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	s := string(src)
+	for _, expected := range []string{
+		"version = \"2.0\"\ntitle = \"Library News\"\n",
+		"\n[[item]]\ntitle = \"New chemistry database\"\n",
+		"category = [\"Chemistry\", \"Databases\"]\n",
+		"\n[item.enclosure]\nurl = ",
+		"\n[[item.extensions]]\nnamespace = \"http://search.yahoo.com/mrss/\"\nname = \"content\"\n",
+		"description = '''\nClosed on Monday.\nOpen Tuesday.'''\n",
+	} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("expected %q in\n%s", expected, s)
+		}
+	}
+}
+
+func TestParseTOML(t *testing.T) {
+	src := []byte(`# A hand written feed
+title = 'Library News' # the channel title
+link = "https://library.example.edu/news"
+description = """
+News from the \
+  library."""
+ttl = 60
+
+[[item]]
+title = "New chemistry database \u2014 \"ChemDB\""
+link = "https://library.example.edu/news/1"
+category = [
+  "Chemistry", # a comment
+  'Data bases',
+]
+enclosure = { url = "https://library.example.edu/news/1.mp3", type = "audio/mpeg" }
+pubDate = 2016-07-25T20:48:03-07:00
+
+[[item]]
+title = "Holiday hours"
+link = "https://library.example.edu/news/2"
+description = '''
+C:\hours\closed.txt'''
+
+[item.enclosure]
+url = "https://library.example.edu/news/2.mp3"
+`)
+	r, err := ParseTOML(src)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if r.Version != "2.0" || r.Title != "Library News" || r.Description != "News from the library." || r.TTL != "60" {
+		t.Errorf("unexpected channel %+v", r)
+	}
+	if len(r.ItemList) != 2 {
+		t.Errorf("expected 2 items, got %d", len(r.ItemList))
+		t.FailNow()
+	}
+	first, second := r.ItemList[0], r.ItemList[1]
+	if first.Title != "New chemistry database — \"ChemDB\"" || first.PubDate != "2016-07-25T20:48:03-07:00" {
+		t.Errorf("unexpected first item %+v", first)
+	}
+	if strings.Join(first.Category, "|") != "Chemistry|Data bases" {
+		t.Errorf("unexpected categories %q", first.Category)
+	}
+	if first.Enclosure == nil || first.Enclosure.Type != "audio/mpeg" {
+		t.Errorf("unexpected enclosure %+v", first.Enclosure)
+	}
+	if second.Description != `C:\hours\closed.txt` || second.Enclosure == nil || second.Enclosure.URL != "https://library.example.edu/news/2.mp3" {
+		t.Errorf("unexpected second item %+v", second)
+	}
+
+	for _, bad := range []string{
+		"title = \"a\"\ntitle = \"b\"\n",
+		"title = \"a\" link = \"b\"\n",
+		"title = \"unterminated\n",
+		"[item]\n[item]\n",
+		"[[item]]\n[item]\n",
+		"title = \"a\"\n[title]\n",
+		"title\n",
+		"title = \"bad \\q escape\"\n",
+		"title = Library News\n",
+		"ttl = 60 minutes\n",
+		"category = [a, b]\n",
+	} {
+		if _, err := ParseTOML([]byte(bad)); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
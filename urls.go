@@ -0,0 +1,266 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"html"
+	"net/url"
+	"strings"
+)
+
+// xmlNamespace is the namespace of the xml: prefix, e.g. xml:base
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// URLOptions control how ResolveURLs normalizes URLs
+type URLOptions struct {
+	// FeedURL is where the feed was retrieved from, relative URLs
+	// are resolved against it when the feed has no absolute base
+	FeedURL string
+	// HTTPS rewrites http URLs to https
+	HTTPS bool
+	// StripTracking removes tracking query parameters, utm_* and
+	// the click identifiers in trackingParams
+	StripTracking bool
+}
+
+// trackingParams are query parameters added for analytics, removed
+// along with utm_* parameters
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "msclkid": true,
+	"mc_cid": true, "mc_eid": true, "yclid": true, "_hsenc": true,
+	"_hsmi": true, "igshid": true,
+}
+
+// urlResolver resolves URLs against a base applying URLOptions
+type urlResolver struct {
+	base *url.URL
+	opts *URLOptions
+}
+
+// with returns a resolver using ref, resolved against the current
+// base, as its base. An empty or unparsable ref keeps the base.
+func (ur *urlResolver) with(ref string) *urlResolver {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ur
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ur
+	}
+	if ur.base != nil {
+		u = ur.base.ResolveReference(u)
+	}
+	return &urlResolver{base: u, opts: ur.opts}
+}
+
+// resolve returns s as a normalized absolute URL, values that can't
+// be parsed or resolved are returned unchanged
+func (ur *urlResolver) resolve(s string) string {
+	ref := strings.TrimSpace(s)
+	if ref == "" {
+		return s
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return s
+	}
+	if !u.IsAbs() {
+		if ur.base == nil || !ur.base.IsAbs() {
+			return s
+		}
+		u = ur.base.ResolveReference(u)
+	}
+	if ur.opts.HTTPS && u.Scheme == "http" {
+		u.Scheme = "https"
+		u.Host = strings.TrimSuffix(u.Host, ":80")
+	}
+	if ur.opts.StripTracking && u.RawQuery != "" {
+		// keep the order and encoding of the other parameters
+		params := []string{}
+		for _, param := range strings.Split(u.RawQuery, "&") {
+			name := param
+			if i := strings.IndexByte(param, '='); i >= 0 {
+				name = param[0:i]
+			}
+			if name, err := url.QueryUnescape(name); err == nil {
+				name = strings.ToLower(name)
+				if strings.HasPrefix(name, "utm_") || trackingParams[name] {
+					continue
+				}
+			}
+			params = append(params, param)
+		}
+		u.RawQuery = strings.Join(params, "&")
+		u.ForceQuery = false
+	}
+	return u.String()
+}
+
+// resolveHTML resolves the URL attributes of the tags in an HTML
+// fragment, e.g. href and src. Tags without URLs, text, comments and
+// the content of script and style elements are left as they are.
+func (ur *urlResolver) resolveHTML(src string) string {
+	if !strings.Contains(src, "<") {
+		return src
+	}
+	var sb strings.Builder
+	s := src
+	for len(s) > 0 {
+		i := strings.IndexByte(s, '<')
+		if i < 0 {
+			sb.WriteString(s)
+			break
+		}
+		sb.WriteString(s[0:i])
+		s = s[i:]
+		if strings.HasPrefix(s, "<!--") {
+			end := strings.Index(s, "-->")
+			if end < 0 {
+				end = len(s) - 3
+			}
+			sb.WriteString(s[0 : end+3])
+			s = s[end+3:]
+			continue
+		}
+		tag, rest, ok := readTag(s)
+		if !ok || tag == nil {
+			sb.WriteByte('<')
+			s = s[1:]
+			continue
+		}
+		raw := s[0 : len(s)-len(rest)]
+		s = rest
+		if rawText[tag.name] && !tag.end {
+			// copy the content up to the end tag as is
+			j := indexEndTag(s, tag.name)
+			if j < 0 {
+				j = len(s)
+			}
+			raw, s = raw+s[0:j], s[j:]
+		}
+		changed := false
+		for i, attr := range tag.attrs {
+			if !urlAttributes[attr.name] {
+				continue
+			}
+			value := attr.value
+			if attr.name == "srcset" {
+				candidates := strings.Split(value, ",")
+				for j, candidate := range candidates {
+					if fields := strings.Fields(candidate); len(fields) > 0 {
+						fields[0] = ur.resolve(fields[0])
+						candidates[j] = strings.Join(fields, " ")
+					}
+				}
+				value = strings.Join(candidates, ", ")
+			} else {
+				value = ur.resolve(value)
+			}
+			if value != attr.value {
+				tag.attrs[i].value = value
+				changed = true
+			}
+		}
+		if !changed {
+			sb.WriteString(raw)
+			continue
+		}
+		sb.WriteString("<" + tag.name)
+		for _, attr := range tag.attrs {
+			sb.WriteString(" " + attr.name + `="` + html.EscapeString(attr.value) + `"`)
+		}
+		if tag.selfClosing {
+			sb.WriteString(" /")
+		}
+		sb.WriteString(">")
+	}
+	return sb.String()
+}
+
+// resolveExtensions resolves the url, href and src attributes of
+// extension elements such as media:content and atom:link
+func (ur *urlResolver) resolveExtensions(exts []Extension) {
+	for i := range exts {
+		for j, attr := range exts[i].Attrs {
+			if attr.Name.Space == "" && (attr.Name.Local == "url" || attr.Name.Local == "href" || attr.Name.Local == "src") {
+				exts[i].Attrs[j].Value = ur.resolve(attr.Value)
+			}
+		}
+		ur.resolveExtensions(exts[i].Children)
+	}
+}
+
+// xmlBase returns the xml:base attribute in attrs
+func xmlBase(attrs CustomAttrs) string {
+	for _, attr := range attrs {
+		if attr.Name.Space == xmlNamespace && attr.Name.Local == "base" {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// ResolveURLs makes the URLs in r absolute and normalizes them with
+// opts, nil leaves them as they are apart from being resolved.
+//
+// Channel URLs (link, docs and the image url and link) are resolved
+// against the rss element's xml:base, or opts.FeedURL. Item URLs
+// (link, comments, the enclosure url and the url, href and src of
+// extensions such as media:content) are resolved against an item's
+// xml:base or else the channel link. URLs in the HTML of an item's
+// description and content:encoded, e.g. a href and img src, are
+// resolved against the item's xml:base, its link or the channel link.
+// URLs that can't be resolved are left unchanged.
+func (r *RSS2) ResolveURLs(opts *URLOptions) {
+	if opts == nil {
+		opts = new(URLOptions)
+	}
+	feed := (&urlResolver{opts: opts}).with(opts.FeedURL)
+	channel := feed.with(r.Base)
+	r.Link = channel.resolve(r.Link)
+	r.Docs = channel.resolve(r.Docs)
+	if r.Image != nil {
+		r.Image.URL = channel.resolve(r.Image.URL)
+		r.Image.Link = channel.resolve(r.Image.Link)
+	}
+	// items are relative to the channel link, it is a page of the
+	// site the feed describes
+	site := channel.with(r.Link)
+	r.Description = site.resolveHTML(r.Description)
+	for i := range r.ItemList {
+		item := &r.ItemList[i]
+		base := site
+		if b := xmlBase(item.OtherAttr); b != "" {
+			base = channel.with(b)
+		}
+		item.Link = base.resolve(item.Link)
+		item.Comments = base.resolve(item.Comments)
+		if item.Enclosure != nil {
+			item.Enclosure.URL = base.resolve(item.Enclosure.URL)
+		}
+		base.resolveExtensions(item.Extensions)
+		page := base
+		if xmlBase(item.OtherAttr) == "" {
+			page = base.with(item.Link)
+		}
+		item.Description = page.resolveHTML(item.Description)
+		item.Content = page.resolveHTML(item.Content)
+	}
+}
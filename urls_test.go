@@ -0,0 +1,114 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveURLs(t *testing.T) {
+	src := []byte(`<rss version="2.0" xml:base="https://example.edu/blog/" xmlns:media="http://search.yahoo.com/mrss/">
+<channel>
+  <title>Blog</title>
+  <link>index.html</link>
+  <description>See &lt;a href="about.html"&gt;about&lt;/a&gt;</description>
+  <docs>/rss-spec.html</docs>
+  <image><url>logo.png</url><title>Blog</title><link>./</link></image>
+  <item>
+    <title>One</title>
+    <link>2020/one.html?utm_source=rss&amp;id=1&amp;utm_medium=feed</link>
+    <comments>2020/one.html#comments</comments>
+    <description>&lt;p&gt;&lt;a href="two.html"&gt;Two&lt;/a&gt; &lt;img src='/img/one.png' alt="One"&gt; &lt;a href="#top"&gt;top&lt;/a&gt; &lt;a href="mailto:me@example.edu"&gt;mail&lt;/a&gt;&lt;/p&gt;&lt;!-- &lt;a href="x.html"&gt; --&gt;</description>
+    <enclosure url="media/one.mp3" length="10" type="audio/mpeg"/>
+    <media:content url="media/one.png" type="image/png"/>
+  </item>
+  <item xml:base="http://cdn.example.org/assets/">
+    <title>Two</title>
+    <link>http://example.org/two?fbclid=abc</link>
+    <description>&lt;img srcset="a.png 1x, b.png 2x" src="a.png"/&gt;</description>
+  </item>
+</channel>
+</rss>`)
+	r, err := Parse(src)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if r.Base != "https://example.edu/blog/" {
+		t.Errorf("expected the rss element's xml:base, got %q", r.Base)
+	}
+	r.ResolveURLs(&URLOptions{HTTPS: true, StripTracking: true})
+	one, two := r.ItemList[0], r.ItemList[1]
+	tests := map[string]string{
+		"channel link": r.Link + " " + r.Docs,
+		"image":        r.Image.URL + " " + r.Image.Link,
+		"description":  r.Description,
+		"link":         one.Link + " " + one.Comments,
+		"enclosure":    one.Enclosure.URL + " " + one.Extensions[0].Attrs[0].Value,
+		"html":         one.Description,
+		"xml:base":     two.Link,
+		"srcset":       two.Description,
+	}
+	expected := map[string]string{
+		"channel link": "https://example.edu/blog/index.html https://example.edu/rss-spec.html",
+		"image":        "https://example.edu/blog/logo.png https://example.edu/blog/",
+		"description":  `See <a href="https://example.edu/blog/about.html">about</a>`,
+		"link":         "https://example.edu/blog/2020/one.html?id=1 https://example.edu/blog/2020/one.html#comments",
+		"enclosure":    "https://example.edu/blog/media/one.mp3 https://example.edu/blog/media/one.png",
+		"html":         `<p><a href="https://example.edu/blog/2020/two.html">Two</a> <img src="https://example.edu/img/one.png" alt="One"> <a href="https://example.edu/blog/2020/one.html?id=1#top">top</a> <a href="mailto:me@example.edu">mail</a></p><!-- <a href="x.html"> -->`,
+		"xml:base":     "https://example.org/two",
+		"srcset":       `<img srcset="https://cdn.example.org/assets/a.png 1x, https://cdn.example.org/assets/b.png 2x" src="https://cdn.example.org/assets/a.png" />`,
+	}
+	for name, got := range tests {
+		if got != expected[name] {
+			t.Errorf("%s: expected\n%s\ngot\n%s", name, expected[name], got)
+		}
+	}
+	// xml:base is kept writing XML
+	out, err := r.ToXML()
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if !strings.Contains(string(out), `xml:base="https://example.edu/blog/"`) {
+		t.Errorf("expected xml:base in\n%s", out)
+	}
+
+	// without a base relative URLs are left as they are, FeedURL
+	// supplies one
+	r, _ = Parse([]byte(`<rss version="2.0"><channel><title>x</title><link>/news/</link><description>x</description><item><link>1.html?utm_campaign=x</link></item></channel></rss>`))
+	r.ResolveURLs(nil)
+	if r.Link != "/news/" || r.ItemList[0].Link != "1.html?utm_campaign=x" {
+		t.Errorf("expected relative URLs unchanged, got %q %q", r.Link, r.ItemList[0].Link)
+	}
+	r.ResolveURLs(&URLOptions{FeedURL: "http://example.edu/feeds/rss.xml"})
+	if r.Link != "http://example.edu/news/" || r.ItemList[0].Link != "http://example.edu/news/1.html?utm_campaign=x" {
+		t.Errorf("expected URLs resolved against the feed URL, got %q %q", r.Link, r.ItemList[0].Link)
+	}
+
+	// script content is copied as is, even when lower casing it
+	// would change its length
+	script := `<script>` + strings.Repeat("Ⱥ", 20) + `</SCRIPT><img src="a.png">`
+	r = &RSS2{Base: "https://example.edu/", ItemList: []Item{{Description: script}}}
+	r.ResolveURLs(nil)
+	if expected := `<script>` + strings.Repeat("Ⱥ", 20) + `</SCRIPT><img src="https://example.edu/a.png">`; r.ItemList[0].Description != expected {
+		t.Errorf("expected %s, got %s", expected, r.ItemList[0].Description)
+	}
+}
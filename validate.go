@@ -0,0 +1,280 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Severity says how serious an Issue is
+type Severity string
+
+const (
+	// SeverityError is a violation of the RSS 2 specification
+	SeverityError Severity = "error"
+	// SeverityWarning is allowed by the specification but likely
+	// to cause problems for feed readers
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a problem found by Validate
+type Issue struct {
+	// Rule identifies the check, e.g. "date-rfc822"
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	// Path is the data path of the value, e.g. ".item[3].pubDate"
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// String returns the issue as "severity path: message (rule)"
+func (issue Issue) String() string {
+	return fmt.Sprintf("%s %s: %s (%s)", issue.Severity, issue.Path, issue.Message, issue.Rule)
+}
+
+// ValidationRules describes each rule Validate checks by rule ID
+var ValidationRules = map[string]string{
+	"channel-required":          "version 2.0 and a channel title, link and description are required",
+	"item-title-or-description": "an item needs a title or a description",
+	"date-rfc822":               "dates are RFC 822 dates",
+	"url-absolute":              "links and URLs are absolute http or https URLs",
+	"email":                     "managingEditor, webMaster and author are email addresses",
+	"guid-unique":               "no two items share a guid",
+	"enclosure":                 "an enclosure has a URL, a length in bytes and a MIME type",
+	"image":                     "an image has a URL, title and link, a width of at most 144 and a height of at most 400",
+	"ttl":                       "ttl is a number of minutes",
+}
+
+// rfc822Formats are the date layouts allowed by RFC 822 as amended
+// by RFC 1123, the day of week and seconds are optional
+var rfc822Formats = []string{
+	"Mon, 02 Jan 2006 15:04:05 -0700",
+	"Mon, 02 Jan 2006 15:04:05 MST",
+	"Mon, 02 Jan 2006 15:04 -0700",
+	"Mon, 02 Jan 2006 15:04 MST",
+	"02 Jan 2006 15:04:05 -0700",
+	"02 Jan 2006 15:04:05 MST",
+	"02 Jan 2006 15:04 -0700",
+	"02 Jan 2006 15:04 MST",
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	time.RFC822Z,
+	time.RFC822,
+	"Mon, 02 Jan 06 15:04:05 -0700",
+	"Mon, 02 Jan 06 15:04:05 MST",
+}
+
+// rfc822Zones are the zone names RFC 822 allows besides numeric
+// offsets, military zones are single letters other than J
+var rfc822Zones = map[string]bool{
+	"UT": true, "GMT": true,
+	"EST": true, "EDT": true,
+	"CST": true, "CDT": true,
+	"MST": true, "MDT": true,
+	"PST": true, "PDT": true,
+}
+
+// validator collects issues as a feed is checked
+type validator struct {
+	issues []Issue
+}
+
+func (v *validator) add(rule string, severity Severity, path string, format string, args ...interface{}) {
+	v.issues = append(v.issues, Issue{
+		Rule:     rule,
+		Severity: severity,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// required reports an empty required element
+func (v *validator) required(rule, path, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		v.add(rule, SeverityError, path, "%s is required", path[strings.LastIndex(path, ".")+1:])
+		return false
+	}
+	return true
+}
+
+// date checks an RFC 822 date, dates Parse understands in another
+// format are warnings
+func (v *validator) date(path, value string) {
+	if value == "" {
+		return
+	}
+	s := strings.TrimSpace(value)
+	// time.Parse takes any abbreviation as a zone name, so the zone
+	// is checked against those RFC 822 names
+	zone := s[strings.LastIndex(s, " ")+1:]
+	named := rfc822Zones[zone]
+	switch {
+	case zone == "UT":
+		s += "C"
+	case len(zone) == 1 && zone[0] >= 'A' && zone[0] <= 'Z' && zone != "J":
+		// RFC 1123 treats military zones as an unknown offset
+		s, named = s[0:len(s)-1]+"-0000", true
+	}
+	for _, layout := range rfc822Formats {
+		if strings.HasSuffix(layout, "MST") && !named {
+			continue
+		}
+		if t, err := time.Parse(layout, s); err == nil {
+			// the day of week, when given, must agree with the date
+			if i := strings.Index(s, ","); i > 0 && !strings.EqualFold(s[0:i], t.Weekday().String()[0:3]) {
+				v.add("date-rfc822", SeverityError, path, "%q is a %s", value, t.Weekday())
+			}
+			return
+		}
+	}
+	if _, err := parseDate(s); err == nil {
+		v.add("date-rfc822", SeverityWarning, path, "%q is not an RFC 822 date", value)
+		return
+	}
+	v.add("date-rfc822", SeverityError, path, "%q is not a valid date", value)
+}
+
+// url checks for an absolute http(s) URL
+func (v *validator) url(path, value string) {
+	if value == "" {
+		return
+	}
+	u, err := url.Parse(strings.TrimSpace(value))
+	switch {
+	case err != nil:
+		v.add("url-absolute", SeverityError, path, "%q is not a URL", value)
+	case !u.IsAbs() || u.Host == "" && u.Opaque == "":
+		v.add("url-absolute", SeverityError, path, "%q is not an absolute URL", value)
+	case u.Scheme != "http" && u.Scheme != "https":
+		v.add("url-absolute", SeverityWarning, path, "%q is not an http or https URL", value)
+	}
+}
+
+// email checks for an address optionally followed by the person's
+// name in parentheses, e.g. "jane@example.edu (Jane Doe)"
+func (v *validator) email(path, value string) {
+	if value == "" {
+		return
+	}
+	addr := strings.TrimSpace(value)
+	if i := strings.Index(addr, " ("); i > 0 && strings.HasSuffix(addr, ")") {
+		addr = addr[0:i]
+	}
+	if a, err := mail.ParseAddress(addr); err != nil || a.Address != addr {
+		v.add("email", SeverityError, path, "%q is not an email address", value)
+	}
+}
+
+// number checks a non-negative integer, max of 0 means no limit
+func (v *validator) number(rule, path, value string, max int) {
+	if value == "" {
+		return
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	switch {
+	case err != nil || n < 0:
+		v.add(rule, SeverityError, path, "%q is not a non-negative integer", value)
+	case max > 0 && n > max:
+		v.add(rule, SeverityError, path, "%d is more than the maximum of %d", n, max)
+	}
+}
+
+// Validate checks r against the RSS 2.0 specification. Issues are
+// returned in document order with these rules
+//
+//   - channel-required, version 2.0 and a channel title, link and
+//     description
+//   - item-title-or-description, every item has one or the other
+//   - date-rfc822, pubDate and lastBuildDate are RFC 822 dates
+//   - url-absolute, links, docs, comments and enclosure and image URLs
+//   - email, managingEditor, webMaster and item author are addresses
+//   - guid-unique, no two items share a guid
+//   - enclosure, url, length and MIME type are present and valid
+//   - image, url, title and link are present, width is at most 144
+//     and height at most 400
+//
+// A valid feed returns an empty list.
+func (r *RSS2) Validate() []Issue {
+	v := new(validator)
+	if r.Version != "2.0" {
+		v.add("channel-required", SeverityError, ".version", "version must be \"2.0\", got %q", r.Version)
+	}
+	v.required("channel-required", ".title", r.Title)
+	if v.required("channel-required", ".link", r.Link) {
+		v.url(".link", r.Link)
+	}
+	v.required("channel-required", ".description", r.Description)
+	v.email(".managingEditor", r.ManagingEditor)
+	v.email(".webMaster", r.WebMaster)
+	v.date(".pubDate", r.PubDate)
+	v.date(".lastBuildDate", r.LastBuildDate)
+	v.url(".docs", r.Docs)
+	v.number("ttl", ".ttl", r.TTL, 0)
+	if img := r.Image; img != nil {
+		if v.required("image", ".image.url", img.URL) {
+			v.url(".image.url", img.URL)
+		}
+		v.required("image", ".image.title", img.Title)
+		if v.required("image", ".image.link", img.Link) {
+			v.url(".image.link", img.Link)
+		}
+		v.number("image", ".image.width", img.Width, 144)
+		v.number("image", ".image.height", img.Height, 400)
+	}
+
+	guids := map[string]int{}
+	for i, item := range r.ItemList {
+		p := fmt.Sprintf(".item[%d]", i)
+		if strings.TrimSpace(item.Title) == "" && strings.TrimSpace(item.Description) == "" {
+			v.add("item-title-or-description", SeverityError, p, "an item needs a title or a description")
+		}
+		v.url(p+".link", item.Link)
+		v.email(p+".author", item.Author)
+		v.url(p+".comments", item.Comments)
+		v.date(p+".pubDate", item.PubDate)
+		if item.GUID != "" {
+			if j, ok := guids[item.GUID]; ok {
+				v.add("guid-unique", SeverityError, p+".guid", "%q is also the guid of .item[%d]", item.GUID, j)
+			} else {
+				guids[item.GUID] = i
+			}
+		}
+		if enc := item.Enclosure; enc != nil {
+			if v.required("enclosure", p+".enclosure.url", enc.URL) {
+				v.url(p+".enclosure.url", enc.URL)
+			}
+			if v.required("enclosure", p+".enclosure.length", enc.Length) {
+				v.number("enclosure", p+".enclosure.length", enc.Length, 0)
+			}
+			if v.required("enclosure", p+".enclosure.type", enc.Type) {
+				if parts := strings.Split(enc.Type, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+					v.add("enclosure", SeverityError, p+".enclosure.type", "%q is not a MIME type", enc.Type)
+				}
+			}
+		}
+	}
+	return v.issues
+}
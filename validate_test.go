@@ -0,0 +1,164 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"io/ioutil"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	r, err := Parse(pathTestSrc)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if issues := r.Validate(); len(issues) != 0 {
+		t.Errorf("expected no issues, got %s", issues)
+	}
+
+	src := []byte(`<rss version="2.0">
+<channel>
+  <title>Broken</title>
+  <link>/news</link>
+  <webMaster>Web Team</webMaster>
+  <pubDate>2020-01-02</pubDate>
+  <lastBuildDate>Tue, 25 Jul 2016 20:48:03 -0700</lastBuildDate>
+  <ttl>soon</ttl>
+  <image>
+    <url>https://example.edu/logo.png</url>
+    <link>https://example.edu</link>
+    <width>200</width>
+    <height>31</height>
+  </image>
+  <item>
+    <title>One</title>
+    <link>https://example.edu/1</link>
+    <guid>a</guid>
+    <author>jane@example.edu (Jane Doe)</author>
+    <pubDate>Mon, 25 Jul 2016 20:48:03 PDT</pubDate>
+  </item>
+  <item>
+    <link>ftp://example.edu/2</link>
+    <guid>a</guid>
+    <author>Jane Doe</author>
+    <pubDate>yesterday</pubDate>
+    <enclosure url="example.mp3" length="-1" type="audio"/>
+  </item>
+  <item>
+    <description>Only a description</description>
+    <enclosure url="https://example.edu/3.mp3" type="audio/mpeg"/>
+  </item>
+</channel>
+</rss>`)
+	r, err = Parse(src)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	expected := []string{
+		"error .link: \"/news\" is not an absolute URL (url-absolute)",
+		"error .description: description is required (channel-required)",
+		"error .webMaster: \"Web Team\" is not an email address (email)",
+		"warning .pubDate: \"2020-01-02\" is not an RFC 822 date (date-rfc822)",
+		"error .lastBuildDate: \"Tue, 25 Jul 2016 20:48:03 -0700\" is a Monday (date-rfc822)",
+		"error .ttl: \"soon\" is not a non-negative integer (ttl)",
+		"error .image.title: title is required (image)",
+		"error .image.width: 200 is more than the maximum of 144 (image)",
+		"error .item[1]: an item needs a title or a description (item-title-or-description)",
+		"warning .item[1].link: \"ftp://example.edu/2\" is not an http or https URL (url-absolute)",
+		"error .item[1].author: \"Jane Doe\" is not an email address (email)",
+		"error .item[1].pubDate: \"yesterday\" is not a valid date (date-rfc822)",
+		"error .item[1].guid: \"a\" is also the guid of .item[0] (guid-unique)",
+		"error .item[1].enclosure.url: \"example.mp3\" is not an absolute URL (url-absolute)",
+		"error .item[1].enclosure.length: \"-1\" is not a non-negative integer (enclosure)",
+		"error .item[1].enclosure.type: \"audio\" is not a MIME type (enclosure)",
+		"error .item[2].enclosure.length: length is required (enclosure)",
+	}
+	issues := r.Validate()
+	got := []string{}
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+	// every issue path resolves against the feed and every rule is
+	// documented
+	for _, issue := range issues {
+		if _, err := CompilePath(issue.Path); err != nil {
+			t.Errorf("%s: %s", issue.Path, err)
+		}
+		if _, ok := ValidationRules[issue.Rule]; !ok {
+			t.Errorf("%s: rule %q is missing from ValidationRules", issue.Path, issue.Rule)
+		}
+	}
+}
+
+func TestValidateDateZones(t *testing.T) {
+	dates := map[string]string{
+		"Mon, 25 Jul 2016 20:48:03 -0700": "",
+		"Mon, 25 Jul 2016 20:48:03 GMT":   "",
+		"Mon, 25 Jul 2016 20:48:03 UT":    "",
+		"Mon, 25 Jul 2016 20:48:03 EDT":   "",
+		"Mon, 25 Jul 2016 20:48 PST":      "",
+		"Mon, 25 Jul 2016 20:48:03 Z":     "",
+		"Mon, 25 Jul 2016 20:48:03 CEST":  "warning",
+		"Mon, 25 Jul 2016 20:48:03 UTC":   "warning",
+		"Mon, 25 Jul 2016 20:48:03 XYZ":   "warning",
+		"Mon, 25 Jul 2016 20:48:03 J":     "error",
+	}
+	for date, expected := range dates {
+		r := &RSS2{Version: "2.0", Title: "News", Link: "https://example.edu/", Description: "News", PubDate: date}
+		got := ""
+		for _, issue := range r.Validate() {
+			if issue.Rule == "date-rfc822" {
+				got = string(issue.Severity)
+			}
+		}
+		if got != expected {
+			t.Errorf("%s: expected %q, got %q", date, expected, got)
+		}
+	}
+}
+
+func TestValidateRSDoiel(t *testing.T) {
+	src, err := ioutil.ReadFile(path.Join("testdata", "rsdoiel.xml"))
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	r, err := Parse(src)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	// mkrss wrote author names where RSS 2 wants email addresses and
+	// dates in UTC, which isn't one of the RFC 822 zones
+	for _, issue := range r.Validate() {
+		switch {
+		case issue.Rule == "email" && strings.HasSuffix(issue.Path, ".author"):
+		case issue.Rule == "date-rfc822" && issue.Severity == SeverityWarning && strings.HasSuffix(issue.Message, "UTC\" is not an RFC 822 date"):
+		default:
+			t.Errorf("unexpected issue %s", issue)
+		}
+	}
+}
@@ -0,0 +1,866 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+//
+// YAML and TOML are written from an ordered tree built from the JSON
+// encoding of a feed, so fields follow the order of the struct fields
+// (the order of the RSS 2 specification) instead of Go map order.
+// Reading builds the same tree and decodes it as JSON.
+//
+// Only the subset of each format needed to read back what ToYAML and
+// ToTOML write, and feeds written by hand in the same shape, is
+// supported.
+//
+// YAML: a single document (an optional "---" and "..." are allowed),
+// block mappings and sequences indented with spaces, plain, single
+// and double quoted scalars, literal (|) and folded (>) block scalars
+// with chomping and indentation indicators, flow sequences and
+// mappings written on one line and comments. Anchors, aliases, tags,
+// complex ("?") keys, multi-line flow collections and multiple
+// documents are rejected.
+//
+// TOML: key/value pairs with bare, quoted and dotted keys, tables,
+// arrays of tables, inline tables, arrays, basic and literal strings
+// including their multi-line forms, and comments. Numbers, booleans
+// and dates are checked against the TOML grammar and kept as their
+// text since the RSS 2 fields are all strings.
+//
+
+type nodeKind int
+
+const (
+	nullNode nodeKind = iota
+	scalarNode
+	listNode
+	mapNode
+)
+
+// node is a JSON value keeping object keys in document order
+type node struct {
+	kind nodeKind
+	// text holds a scalar, quoted is true for strings as opposed to
+	// numbers and booleans
+	text   string
+	quoted bool
+	// keys holds the object keys, items the object values or array
+	// elements
+	keys  []string
+	items []*node
+}
+
+// get returns the value of key in a map node or nil
+func (n *node) get(key string) *node {
+	for i, k := range n.keys {
+		if k == key {
+			return n.items[i]
+		}
+	}
+	return nil
+}
+
+// set adds or replaces key in a map node
+func (n *node) set(key string, value *node) {
+	for i, k := range n.keys {
+		if k == key {
+			n.items[i] = value
+			return
+		}
+	}
+	n.keys = append(n.keys, key)
+	n.items = append(n.items, value)
+}
+
+func readTree(decoder *json.Decoder) (*node, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := token.(type) {
+	case json.Delim:
+		n := &node{kind: listNode}
+		if t == '{' {
+			n.kind = mapNode
+		}
+		for decoder.More() {
+			if n.kind == mapNode {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				n.keys = append(n.keys, key.(string))
+			}
+			item, err := readTree(decoder)
+			if err != nil {
+				return nil, err
+			}
+			n.items = append(n.items, item)
+		}
+		// the closing delimiter
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return n, nil
+	case string:
+		return &node{kind: scalarNode, text: t, quoted: true}, nil
+	case json.Number:
+		return &node{kind: scalarNode, text: t.String()}, nil
+	case bool:
+		return &node{kind: scalarNode, text: strconv.FormatBool(t)}, nil
+	}
+	return &node{kind: nullNode}, nil
+}
+
+// jsonTree decodes JSON into a node tree
+func jsonTree(src []byte) (*node, error) {
+	decoder := json.NewDecoder(bytes.NewReader(src))
+	decoder.UseNumber()
+	return readTree(decoder)
+}
+
+// writeJSON encodes the tree as JSON
+func (n *node) writeJSON(buf *bytes.Buffer) {
+	switch n.kind {
+	case nullNode:
+		buf.WriteString("null")
+	case scalarNode:
+		if n.quoted {
+			buf.WriteString(jsonString(n.text))
+		} else {
+			buf.WriteString(n.text)
+		}
+	case listNode, mapNode:
+		open, close := "[", "]"
+		if n.kind == mapNode {
+			open, close = "{", "}"
+		}
+		buf.WriteString(open)
+		for i, item := range n.items {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			if n.kind == mapNode {
+				buf.WriteString(jsonString(n.keys[i]))
+				buf.WriteString(":")
+			}
+			item.writeJSON(buf)
+		}
+		buf.WriteString(close)
+	}
+}
+
+// jsonString quotes s as a JSON string without escaping HTML
+func jsonString(s string) string {
+	buf := new(bytes.Buffer)
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// tree returns the JSON form of r as a node tree
+func (r *RSS2) tree() (*node, error) {
+	src, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return jsonTree(src)
+}
+
+// treeToRSS2 decodes a tree read from YAML or TOML as an RSS2
+func treeToRSS2(n *node) (*RSS2, error) {
+	if n.kind != mapNode {
+		return nil, fmt.Errorf("expected a mapping of channel fields")
+	}
+	buf := new(bytes.Buffer)
+	n.writeJSON(buf)
+	r := new(RSS2)
+	if err := json.Unmarshal(buf.Bytes(), r); err != nil {
+		return nil, err
+	}
+	if r.Version == "" {
+		r.Version = "2.0"
+	}
+	return r, nil
+}
+
+//
+// YAML
+//
+
+// yamlPlain reports if s can be written as a plain YAML scalar and
+// read back as the same string. Strings that look like numbers,
+// booleans or dates are quoted.
+func yamlPlain(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return false
+	}
+	if strings.ContainsAny(s[0:1], "-?:,[]{}#&*!|>'\"%@`+.0123456789") {
+		return false
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return false
+	}
+	for _, c := range s {
+		if c < ' ' || c == 0x7f || c == '\ufeff' || c == '\u2028' || c == '\u2029' {
+			return false
+		}
+	}
+	switch strings.ToLower(s) {
+	case "null", "~", "true", "false", "yes", "no", "on", "off", "y", "n":
+		return false
+	}
+	return true
+}
+
+// yamlBlock reports if s can be written as a literal block scalar
+func yamlBlock(s string) bool {
+	if !strings.Contains(strings.TrimRight(s, "\n"), "\n") {
+		return false
+	}
+	first := true
+	for _, line := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		if line != "" && strings.TrimSpace(line) == "" {
+			// whitespace only lines can't be told from indentation
+			return false
+		}
+		if first && line != "" {
+			if line[0] == ' ' || line[0] == '\t' {
+				return false
+			}
+			first = false
+		}
+		for _, c := range line {
+			if c < ' ' && c != '\t' || c == 0x7f || c == '\ufeff' || c == '\u2028' || c == '\u2029' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// yamlScalar formats a scalar, block scalars are indented by indent
+func yamlScalar(n *node, indent int) string {
+	switch {
+	case n.kind == nullNode:
+		return "null"
+	case !n.quoted:
+		return n.text
+	case yamlPlain(n.text):
+		return n.text
+	case yamlBlock(n.text):
+		body := strings.TrimRight(n.text, "\n")
+		trailing := len(n.text) - len(body)
+		header := "|-"
+		switch {
+		case trailing == 1:
+			header = "|"
+		case trailing > 1:
+			header = "|+"
+		}
+		pad := strings.Repeat(" ", indent)
+		lines := []string{header}
+		for _, line := range strings.Split(body, "\n") {
+			if line == "" {
+				lines = append(lines, "")
+			} else {
+				lines = append(lines, pad+line)
+			}
+		}
+		for i := 1; i < trailing; i++ {
+			lines = append(lines, "")
+		}
+		return strings.Join(lines, "\n")
+	}
+	return jsonString(n.text)
+}
+
+func yamlKey(key string) string {
+	if yamlPlain(key) {
+		return key
+	}
+	return jsonString(key)
+}
+
+// writeYAML writes a node at indent, the caller has written any key
+// or "- " leading up to it
+func writeYAML(buf *bytes.Buffer, n *node, indent int) {
+	pad := strings.Repeat(" ", indent)
+	switch n.kind {
+	case mapNode:
+		for i, key := range n.keys {
+			buf.WriteString(pad)
+			buf.WriteString(yamlKey(key))
+			buf.WriteString(":")
+			writeYAMLValue(buf, n.items[i], indent)
+		}
+	case listNode:
+		for _, item := range n.items {
+			if (item.kind == mapNode || item.kind == listNode) && len(item.items) > 0 {
+				// start the first line of a nested collection
+				// after the dash, "- title: ..."
+				nested := new(bytes.Buffer)
+				writeYAML(nested, item, indent+2)
+				buf.WriteString(pad)
+				buf.WriteString("- ")
+				buf.Write(nested.Bytes()[indent+2:])
+				continue
+			}
+			buf.WriteString(pad)
+			buf.WriteString("-")
+			writeYAMLValue(buf, item, indent)
+		}
+	}
+}
+
+// writeYAMLValue writes the value following a "key:" or "-" at indent
+func writeYAMLValue(buf *bytes.Buffer, n *node, indent int) {
+	switch {
+	case n.kind == mapNode && len(n.items) == 0:
+		buf.WriteString(" {}\n")
+	case n.kind == listNode && len(n.items) == 0:
+		buf.WriteString(" []\n")
+	case n.kind == mapNode || n.kind == listNode:
+		buf.WriteString("\n")
+		writeYAML(buf, n, indent+2)
+	default:
+		buf.WriteString(" ")
+		buf.WriteString(yamlScalar(n, indent+2))
+		buf.WriteString("\n")
+	}
+}
+
+// ToYAML returns r as a YAML document with fields in RSS 2 order and
+// multi-line text as literal block scalars.
+func (r *RSS2) ToYAML() ([]byte, error) {
+	n, err := r.tree()
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	buf.WriteString("---\n")
+	writeYAML(buf, n, 0)
+	return buf.Bytes(), nil
+}
+
+// yamlParser reads the subset of YAML described above, anything
+// outside of it is an error rather than a guess.
+type yamlParser struct {
+	lines []string
+	pos   int
+}
+
+func (p *yamlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("yaml line %d, %s", p.pos+1, fmt.Sprintf(format, args...))
+}
+
+// indentOf returns the indentation of line, -1 for blank and comment
+// lines
+func indentOf(line string) int {
+	trimmed := strings.TrimLeft(line, " ")
+	if trimmed == "" || trimmed[0] == '#' {
+		return -1
+	}
+	return len(line) - len(trimmed)
+}
+
+// next skips blank and comment lines returning the indentation of
+// the next line with content, -1 at the end
+func (p *yamlParser) next() int {
+	for p.pos < len(p.lines) {
+		if ind := indentOf(p.lines[p.pos]); ind >= 0 {
+			return ind
+		}
+		p.pos++
+	}
+	return -1
+}
+
+// stripComment removes a trailing comment outside of quoted scalars
+func stripComment(s string) string {
+	quote := byte(0)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '"' && c == '\\':
+			// skip the escaped character
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" \t[{,", s[i-1]) >= 0):
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return strings.TrimRight(s[0:i], " \t")
+		}
+	}
+	return strings.TrimRight(s, " \t")
+}
+
+// splitKey splits a mapping line into key and value, ok is false if
+// the line isn't a "key: value" pair
+func splitKey(s string) (string, string, bool, error) {
+	if s != "" && (s[0] == '"' || s[0] == '\'') {
+		key, rest, err := yamlQuoted(s)
+		if err != nil {
+			return "", "", false, err
+		}
+		rest = strings.TrimLeft(rest, " ")
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", false, nil
+		}
+		return key, strings.TrimSpace(rest[1:]), true, nil
+	}
+	if s != "" && strings.ContainsAny(s[0:1], "[{") {
+		return "", "", false, nil
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] == ':' && (i+1 == len(s) || s[i+1] == ' ' || s[i+1] == '\t') {
+			return strings.TrimSpace(s[0:i]), strings.TrimSpace(s[i+1:]), true, nil
+		}
+	}
+	return "", "", false, nil
+}
+
+// yamlQuoted reads a quoted scalar at the start of s returning the
+// text and the rest of s
+func yamlQuoted(s string) (string, string, error) {
+	quote := s[0]
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '\'' && c == '\'':
+			if i+1 < len(s) && s[i+1] == '\'' {
+				sb.WriteByte('\'')
+				i++
+				continue
+			}
+			return sb.String(), s[i+1:], nil
+		case quote == '"' && c == '"':
+			return sb.String(), s[i+1:], nil
+		case quote == '"' && c == '\\' && i+1 < len(s):
+			i++
+			switch e := s[i]; e {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't', '\t':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'a':
+				sb.WriteByte('\a')
+			case 'v':
+				sb.WriteByte('\v')
+			case 'e':
+				sb.WriteByte(0x1b)
+			case '0':
+				sb.WriteByte(0)
+			case '_':
+				sb.WriteRune('\u00a0')
+			case 'N':
+				sb.WriteRune('\u0085')
+			case 'L':
+				sb.WriteRune('\u2028')
+			case 'P':
+				sb.WriteRune('\u2029')
+			case 'x', 'u', 'U':
+				size := map[byte]int{'x': 2, 'u': 4, 'U': 8}[e]
+				if i+size >= len(s) {
+					return "", "", fmt.Errorf("bad escape in %s", s)
+				}
+				code, err := strconv.ParseUint(s[i+1:i+1+size], 16, 32)
+				if err != nil {
+					return "", "", fmt.Errorf("bad escape in %s", s)
+				}
+				r := rune(code)
+				// join UTF-16 surrogate pairs written by JSON encoders
+				if r >= 0xd800 && r < 0xdc00 && strings.HasPrefix(s[i+1+size:], "\\u") && i+1+size+6 <= len(s) {
+					if low, err := strconv.ParseUint(s[i+size+3:i+size+7], 16, 32); err == nil && low >= 0xdc00 && low < 0xe000 {
+						r = (r-0xd800)<<10 + (rune(low) - 0xdc00) + 0x10000
+						i += 6
+					}
+				}
+				sb.WriteRune(r)
+				i += size
+			default:
+				// \" \\ \/ and \<space>
+				sb.WriteByte(e)
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", "", fmt.Errorf("unterminated string %s", s)
+}
+
+func yamlString(s string) *node {
+	return &node{kind: scalarNode, text: s, quoted: true}
+}
+
+// yamlInline parses a value written on the same line as its key or
+// dash, plain scalars may continue on more indented lines
+func (p *yamlParser) yamlInline(s string, indent int) (*node, error) {
+	switch {
+	case s == "" || s == "~" || s == "null" || s == "Null" || s == "NULL":
+		return &node{kind: nullNode}, nil
+	case s[0] == '&' || s[0] == '*':
+		return nil, p.errorf("anchors and aliases are not supported")
+	case s[0] == '!':
+		return nil, p.errorf("tags are not supported")
+	case s[0] == '"' || s[0] == '\'':
+		text, rest, err := yamlQuoted(s)
+		if err != nil {
+			return nil, p.errorf("%s", err)
+		}
+		if strings.TrimSpace(rest) != "" {
+			return nil, p.errorf("unexpected %q after string", rest)
+		}
+		return yamlString(text), nil
+	case s[0] == '[' || s[0] == '{':
+		n, rest, err := yamlFlow(s)
+		if err != nil {
+			return nil, p.errorf("%s", err)
+		}
+		if strings.TrimSpace(rest) != "" {
+			return nil, p.errorf("unexpected %q after %c", rest, s[0])
+		}
+		return n, nil
+	}
+	// continuation lines of a plain scalar are folded into it
+	text := s
+	for {
+		save := p.pos
+		p.pos++
+		ind := p.next()
+		if ind <= indent {
+			p.pos = save
+			break
+		}
+		line := stripComment(strings.TrimSpace(p.lines[p.pos]))
+		if _, _, ok, _ := splitKey(line); ok {
+			p.pos = save
+			break
+		}
+		text += " " + line
+	}
+	return yamlString(text), nil
+}
+
+// yamlFlow parses a single line flow sequence or mapping
+func yamlFlow(s string) (*node, string, error) {
+	n := &node{kind: listNode}
+	end := byte(']')
+	if s[0] == '{' {
+		n.kind, end = mapNode, '}'
+	}
+	s = strings.TrimLeft(s[1:], " ")
+	for {
+		if s == "" {
+			return nil, "", fmt.Errorf("unterminated flow collection")
+		}
+		if s[0] == end {
+			return n, s[1:], nil
+		}
+		var (
+			item *node
+			err  error
+		)
+		key := ""
+		if n.kind == mapNode {
+			if s[0] == '"' || s[0] == '\'' {
+				if key, s, err = yamlQuoted(s); err != nil {
+					return nil, "", err
+				}
+			} else {
+				i := strings.IndexAny(s, ":,}")
+				if i < 0 {
+					return nil, "", fmt.Errorf("unterminated flow mapping")
+				}
+				key, s = strings.TrimSpace(s[0:i]), s[i:]
+			}
+			s = strings.TrimLeft(s, " ")
+			if !strings.HasPrefix(s, ":") {
+				return nil, "", fmt.Errorf("expected \":\" after %q", key)
+			}
+			s = strings.TrimLeft(s[1:], " ")
+		}
+		switch {
+		case s != "" && (s[0] == '[' || s[0] == '{'):
+			item, s, err = yamlFlow(s)
+		case s != "" && (s[0] == '"' || s[0] == '\''):
+			var text string
+			text, s, err = yamlQuoted(s)
+			item = yamlString(text)
+		default:
+			i := strings.IndexAny(s, ",]}")
+			if i < 0 {
+				i = len(s)
+			}
+			text := strings.TrimSpace(s[0:i])
+			s = s[i:]
+			if text != "" && strings.IndexByte("&*!", text[0]) >= 0 {
+				return nil, "", fmt.Errorf("anchors, aliases and tags are not supported")
+			}
+			if text == "" || text == "~" || text == "null" {
+				item = &node{kind: nullNode}
+			} else {
+				item = yamlString(text)
+			}
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if n.kind == mapNode {
+			n.keys = append(n.keys, key)
+		}
+		n.items = append(n.items, item)
+		s = strings.TrimLeft(s, " ")
+		if strings.HasPrefix(s, ",") {
+			s = strings.TrimLeft(s[1:], " ")
+		}
+	}
+}
+
+// yamlBlockScalar reads a literal (|) or folded (>) block scalar, the
+// header line has been consumed, indent is the indentation of its
+// parent
+func (p *yamlParser) yamlBlockScalar(header string, indent int) (*node, error) {
+	chomp, explicit := byte(0), 0
+	for _, c := range header[1:] {
+		switch {
+		case c == '-' || c == '+':
+			chomp = byte(c)
+		case c >= '1' && c <= '9':
+			explicit = int(c - '0')
+		default:
+			return nil, p.errorf("bad block scalar header %q", header)
+		}
+	}
+	content := -1
+	if explicit > 0 {
+		content = indent + explicit
+	}
+	lines := []string{}
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := p.lines[p.pos]
+		if strings.TrimSpace(line) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		ind := len(line) - len(strings.TrimLeft(line, " "))
+		if content < 0 {
+			if ind <= indent {
+				break
+			}
+			content = ind
+		}
+		if ind < content {
+			break
+		}
+		lines = append(lines, line[content:])
+	}
+	// trailing blank lines only count for keep chomping
+	body := len(lines)
+	for body > 0 && lines[body-1] == "" {
+		body--
+	}
+	var text string
+	if header[0] == '|' {
+		text = strings.Join(lines[0:body], "\n")
+	} else {
+		var sb strings.Builder
+		for i, line := range lines[0:body] {
+			switch {
+			case i == 0:
+			case line == "" || lines[i-1] == "" || line[0] == ' ' || lines[i-1][0] == ' ':
+				sb.WriteString("\n")
+			default:
+				sb.WriteString(" ")
+			}
+			sb.WriteString(line)
+		}
+		text = sb.String()
+	}
+	switch {
+	case body == 0:
+	case chomp == '+':
+		text += strings.Repeat("\n", len(lines)-body+1)
+	case chomp == 0:
+		text += "\n"
+	}
+	return yamlString(text), nil
+}
+
+// parseBlock parses the node starting on the next content line,
+// which must be indented more than parent
+func (p *yamlParser) parseBlock(parent int) (*node, error) {
+	ind := p.next()
+	if ind <= parent {
+		return &node{kind: nullNode}, nil
+	}
+	line := stripComment(p.lines[p.pos][ind:])
+	if strings.Contains(p.lines[p.pos][0:ind], "\t") {
+		return nil, p.errorf("tabs can't be used for indentation")
+	}
+	switch {
+	case line == "-" || strings.HasPrefix(line, "- "):
+		return p.parseSequence(ind)
+	case line == "?" || strings.HasPrefix(line, "? "):
+		return nil, p.errorf("complex keys are not supported")
+	case line[0] == '|' || line[0] == '>':
+		p.pos++
+		return p.yamlBlockScalar(line, parent)
+	}
+	if _, _, ok, err := splitKey(line); err != nil {
+		return nil, p.errorf("%s", err)
+	} else if ok {
+		return p.parseMapping(ind)
+	}
+	n, err := p.yamlInline(line, ind-1)
+	p.pos++
+	return n, err
+}
+
+func (p *yamlParser) parseSequence(indent int) (*node, error) {
+	n := &node{kind: listNode}
+	for p.next() == indent {
+		line := p.lines[p.pos][indent:]
+		if line != "-" && !strings.HasPrefix(line, "- ") {
+			break
+		}
+		rest := strings.TrimLeft(line[1:], " ")
+		if rest == "" || rest[0] == '#' {
+			p.pos++
+			item, err := p.parseBlock(indent)
+			if err != nil {
+				return nil, err
+			}
+			n.items = append(n.items, item)
+			continue
+		}
+		// re-read the line with the dash as indentation so a
+		// mapping started after "- " lines up with its other keys
+		p.lines[p.pos] = strings.Repeat(" ", len(p.lines[p.pos])-len(rest)) + rest
+		item, err := p.parseBlock(indent)
+		if err != nil {
+			return nil, err
+		}
+		n.items = append(n.items, item)
+	}
+	return n, nil
+}
+
+func (p *yamlParser) parseMapping(indent int) (*node, error) {
+	n := &node{kind: mapNode}
+	for p.next() == indent {
+		line := stripComment(p.lines[p.pos][indent:])
+		key, rest, ok, err := splitKey(line)
+		if err != nil {
+			return nil, p.errorf("%s", err)
+		}
+		if !ok {
+			return nil, p.errorf("expected \"key: value\", found %q", line)
+		}
+		if key != "" && (key[0] == '"' || key[0] == '\'') {
+			if key, _, err = yamlQuoted(key); err != nil {
+				return nil, p.errorf("%s", err)
+			}
+		}
+		if n.get(key) != nil {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+		p.pos++
+		var value *node
+		switch {
+		case rest == "":
+			// a sequence may sit at the same indentation as its key
+			if ind := p.next(); ind == indent && (p.lines[p.pos][ind:] == "-" || strings.HasPrefix(p.lines[p.pos][ind:], "- ")) {
+				value, err = p.parseSequence(indent)
+			} else {
+				value, err = p.parseBlock(indent)
+			}
+		case rest[0] == '|' || rest[0] == '>':
+			value, err = p.yamlBlockScalar(rest, indent)
+		default:
+			p.pos--
+			value, err = p.yamlInline(rest, indent)
+			p.pos++
+		}
+		if err != nil {
+			return nil, err
+		}
+		n.set(key, value)
+	}
+	if ind := p.next(); ind > indent {
+		return nil, p.errorf("unexpected indentation")
+	}
+	return n, nil
+}
+
+// yamlTree reads a YAML document as a node tree
+func yamlTree(buf []byte) (*node, error) {
+	if !utf8.Valid(buf) {
+		return nil, fmt.Errorf("yaml must be UTF-8")
+	}
+	src := strings.TrimPrefix(strings.Replace(string(buf), "\r\n", "\n", -1), "\ufeff")
+	p := &yamlParser{lines: strings.Split(src, "\n")}
+	for p.next() == 0 && strings.HasPrefix(p.lines[p.pos], "%") {
+		p.pos++
+	}
+	if p.next() == 0 && strings.HasPrefix(p.lines[p.pos], "---") {
+		p.lines[p.pos] = strings.Repeat(" ", 3) + p.lines[p.pos][3:]
+		if indentOf(p.lines[p.pos]) < 0 {
+			p.pos++
+		}
+	}
+	n, err := p.parseBlock(-1)
+	if err != nil {
+		return nil, err
+	}
+	if ind := p.next(); ind >= 0 && !strings.HasPrefix(p.lines[p.pos], "...") {
+		return nil, p.errorf("unexpected content, only one document is supported")
+	}
+	return n, nil
+}
+
+// ParseYAML reads an RSS 2 document written as YAML, e.g. by ToYAML,
+// using the field names of the JSON form. Only the YAML subset listed
+// at the top of this file is supported.
+func ParseYAML(buf []byte) (*RSS2, error) {
+	n, err := yamlTree(buf)
+	if err != nil {
+		return nil, err
+	}
+	return treeToRSS2(n)
+}
@@ -0,0 +1,183 @@
+//
+// rss2 is a golang package for working with RSS 2 feeds and documents.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2020, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+package rss2
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"strings"
+	"testing"
+)
+
+// sameJSON reports if a and b have the same JSON form
+func sameJSON(t *testing.T, a, b *RSS2) bool {
+	srcA, err := json.Marshal(a)
+	if err != nil {
+		t.Errorf("%s", err)
+		return false
+	}
+	srcB, err := json.Marshal(b)
+	if err != nil {
+		t.Errorf("%s", err)
+		return false
+	}
+	if string(srcA) != string(srcB) {
+		t.Errorf("expected\n%s\ngot\n%s", srcA, srcB)
+		return false
+	}
+	return true
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	src, err := ioutil.ReadFile(path.Join("testdata", "rsdoiel.xml"))
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	for _, xmlSrc := range [][]byte{src, pathTestSrc} {
+		r, err := Parse(xmlSrc)
+		if err != nil {
+			t.Errorf("%s", err)
+			t.FailNow()
+		}
+		r.ItemList[0].Description = "First paragraph.\n\n  Indented: \"quoted\" # not a comment\n"
+		r.ItemList[0].Comments = "line one\nline two"
+		r.ItemList[0].Source = "trailing newlines\n\n\n"
+		r.ItemList[0].GUID = "  leading space\nsecond line"
+		r.ItemList[0].Author = "true"
+		yamlSrc, err := r.ToYAML()
+		if err != nil {
+			t.Errorf("%s", err)
+			t.FailNow()
+		}
+		r2, err := ParseYAML(yamlSrc)
+		if err != nil {
+			t.Errorf("%s\n%s", err, yamlSrc)
+			t.FailNow()
+		}
+		sameJSON(t, r, r2)
+	}
+}
+
+func TestToYAML(t *testing.T) {
+	r, _ := Parse(pathTestSrc)
+	r.ItemList[1].Description = "Closed on Monday.\nOpen Tuesday."
+	src, err := r.ToYAML()
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	s := string(src)
+	// fields follow the RSS 2 order
+	order := []string{"version:", "title: Library News", "link:", "description:", "managingEditor:", "item:"}
+	last := -1
+	for _, field := range order {
+		i := strings.Index(s, "\n"+field)
+		if i < last {
+			t.Errorf("expected %q after the previous fields in\n%s", field, s)
+		}
+		last = i
+	}
+	for _, expected := range []string{
+		"version: \"2.0\"\n",
+		"  - title: New chemistry database\n",
+		"      length: \"1024\"\n",
+		"    description: |-\n      Closed on Monday.\n      Open Tuesday.\n",
+	} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("expected %q in\n%s", expected, s)
+		}
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	src := []byte(`# A hand written feed
+title: 'Library News'   # the channel title
+link: https://library.example.edu/news
+description: >
+  News from the
+  library.
+ttl: 60
+item:
+- title: "New chemistry database — \"ChemDB\""
+  link: https://library.example.edu/news/1
+  category: [Chemistry, "Data bases"]
+  description: |
+    First line
+      indented line
+
+    after a blank line
+  enclosure: {url: "https://library.example.edu/news/1.mp3", type: audio/mpeg}
+- title: Holiday hours
+  link: https://library.example.edu/news/2
+  description: A plain scalar
+    folded over two lines
+  category:
+    - Hours
+  guid: ~
+`)
+	r, err := ParseYAML(src)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+	}
+	if r.Version != "2.0" || r.Title != "Library News" || r.Description != "News from the library.\n" || r.TTL != "60" {
+		t.Errorf("unexpected channel %+v", r)
+	}
+	if len(r.ItemList) != 2 {
+		t.Errorf("expected 2 items, got %d", len(r.ItemList))
+		t.FailNow()
+	}
+	first, second := r.ItemList[0], r.ItemList[1]
+	if first.Title != "New chemistry database — \"ChemDB\"" {
+		t.Errorf("unexpected title %q", first.Title)
+	}
+	if strings.Join(first.Category, "|") != "Chemistry|Data bases" {
+		t.Errorf("unexpected categories %q", first.Category)
+	}
+	if first.Description != "First line\n  indented line\n\nafter a blank line\n" {
+		t.Errorf("unexpected description %q", first.Description)
+	}
+	if first.Enclosure == nil || first.Enclosure.Type != "audio/mpeg" {
+		t.Errorf("unexpected enclosure %+v", first.Enclosure)
+	}
+	if second.Description != "A plain scalar folded over two lines" || second.GUID != "" || len(second.Category) != 1 {
+		t.Errorf("unexpected second item %+v", second)
+	}
+
+	for _, bad := range []string{
+		"title: a\ntitle: b\n",
+		"title: a\n  link: b\n",
+		"item:\n  - title: &anchor a\n",
+		"title: \"unterminated\n",
+		"- a\n- b\n",
+		"title: a\n---\ntitle: b\n",
+		"title: !!str 2020\n",
+		"title: !local a\n",
+		"category: [!!str a, b]\n",
+		"category: [a, *alias]\n",
+		"? title\n: a\n",
+		"category: [a,\n  b]\n",
+	} {
+		if _, err := ParseYAML([]byte(bad)); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}